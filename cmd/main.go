@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -10,8 +11,13 @@ import (
 
 	"github.com/maksemen2/avito-shop/config"
 	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/internal/events"
+	"github.com/maksemen2/avito-shop/internal/grpcserver"
 	"github.com/maksemen2/avito-shop/internal/handlers"
+	"github.com/maksemen2/avito-shop/internal/jobs"
+	"github.com/maksemen2/avito-shop/internal/repository"
 	"github.com/maksemen2/avito-shop/internal/routes"
+	"github.com/maksemen2/avito-shop/internal/services"
 	"github.com/maksemen2/avito-shop/pkg/auth"
 	"github.com/maksemen2/avito-shop/pkg/logger"
 	"go.uber.org/zap"
@@ -22,13 +28,80 @@ func main() {
 	logger := logger.MustLoad(config.Logger)
 	jwtManager := auth.NewJWTManager(config.Auth)
 	db := database.MustLoad(config.Database)
-	requestsHandler := handlers.NewRequestsHandler(db, jwtManager, logger)
+
+	// Сервер не применяет миграции сам: схему обновляет shopctl migrate up до выкатки новой версии
+	migrator := database.MustLoadMigrator(db, config.Database)
+	if err := migrator.Check(context.Background()); err != nil {
+		logger.Fatal("Refusing to start, run shopctl migrate up", zap.Error(err))
+	}
+
+	broker := events.NewBroker(logger)
+	holderOptions := []repository.HolderOption{
+		repository.WithReadReplicas(database.MustLoadReplicas(config.Database)...),
+		repository.WithGoodsCache(time.Duration(config.Cache.GoodsTTLSeconds) * time.Second),
+		repository.WithCoinLifetime(config.Coins.Lifetime()),
+	}
+
+	// Кеш сбрасывается раньше публикации событий, чтобы клиент, получивший событие, не прочитал старую сводку
+	infoCache := services.NewInfoCache(config.Cache)
+	if infoCache != nil {
+		holderOptions = append(holderOptions, repository.WithUserChangeHook(services.InvalidationHook(infoCache)))
+	}
+
+	holderOptions = append(holderOptions, repository.WithUserChangeHook(services.NewEventsHook(broker, logger)))
+
+	// HTTP, gRPC и фоновые задачи работают через общий репозиторий, чтобы у них были общие кеши
+	holderRepository := repository.NewHolderRepository(db, logger, holderOptions...)
+	requestsHandler := handlers.NewRequestsHandler(holderRepository, infoCache, jwtManager, broker, config, logger)
 	router := routes.SetupRoutes(requestsHandler, logger, config.Cors)
 
+	grantService := services.NewGrantService(holderRepository, logger)
+	expiryService := services.NewExpiryService(holderRepository, logger)
+	pricingService := services.NewPricingService(holderRepository, logger)
+	webhookService := services.NewWebhookService(holderRepository, config.Webhooks, logger)
+
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	scheduler := jobs.NewScheduler(logger)
+	scheduler.Add(jobs.Job{
+		Name:     "grants",
+		Interval: time.Duration(config.Jobs.GrantsIntervalMinutes) * time.Minute,
+		Run: func(ctx context.Context) error {
+			_, err := grantService.ApplyGrants(ctx, time.Now())
+			return err
+		},
+	})
+	scheduler.Add(jobs.Job{
+		Name:     "coins-expiry",
+		Interval: time.Duration(config.Jobs.ExpiryIntervalMinutes) * time.Minute,
+		Run: func(ctx context.Context) error {
+			_, err := expiryService.ExpireCoins(ctx, time.Now())
+			return err
+		},
+	})
+	scheduler.Add(jobs.Job{
+		Name:     "sale-notifications",
+		Interval: time.Duration(config.Jobs.SalesIntervalMinutes) * time.Minute,
+		Run: func(ctx context.Context) error {
+			_, err := pricingService.NotifySales(ctx, time.Now())
+			return err
+		},
+	})
+	scheduler.Add(jobs.Job{
+		Name:     "webhooks",
+		Interval: time.Duration(config.Jobs.WebhooksIntervalSeconds) * time.Second,
+		Run: func(ctx context.Context) error {
+			_, err := webhookService.DeliverPending(ctx, time.Now())
+			return err
+		},
+	})
+	scheduler.Start(jobsCtx)
+
 	srv := &http.Server{
 		Addr:    ":8080",
 		Handler: router,
 	}
+	// Потоки /api/events не завершаются сами, поэтому закрываем их при остановке сервера
+	srv.RegisterOnShutdown(broker.Close)
 
 	go func() {
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -38,6 +111,26 @@ func main() {
 
 	logger.Info("Server is running on http://localhost:8080")
 
+	grpcServer := grpcserver.NewServer(grpcserver.Services{
+		Auth:     services.NewAuthService(holderRepository, jwtManager, logger),
+		Info:     services.NewCachedInfoService(services.NewInfoService(holderRepository, logger), infoCache),
+		Transfer: services.NewTransferService(holderRepository, config.Transfer, logger),
+		Purchase: services.NewPurchaseService(holderRepository, logger),
+	}, jwtManager, logger)
+
+	grpcListener, err := net.Listen("tcp", ":"+config.GRPC.Port)
+	if err != nil {
+		logger.Fatal("Failed to listen gRPC port", zap.Error(err))
+	}
+
+	go func() {
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			logger.Fatal("Failed to run gRPC server", zap.Error(err))
+		}
+	}()
+
+	logger.Info("gRPC server is running on localhost:" + config.GRPC.Port)
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
@@ -49,5 +142,22 @@ func main() {
 		logger.Fatal("Shutdown forced", zap.Error(err))
 	}
 
+	// GracefulStop ждёт завершения активных вызовов, поэтому ограничиваем его тем же таймаутом
+	grpcStopped := make(chan struct{})
+
+	go func() {
+		grpcServer.GracefulStop()
+		close(grpcStopped)
+	}()
+
+	select {
+	case <-grpcStopped:
+	case <-ctx.Done():
+		grpcServer.Stop()
+	}
+
+	stopJobs()
+	scheduler.Wait()
+
 	logger.Info("Exit")
 }
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/maksemen2/avito-shop/config"
+	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/internal/repository"
+	"github.com/maksemen2/avito-shop/internal/shopctl"
+	"github.com/maksemen2/avito-shop/pkg/logger"
+)
+
+func main() {
+	config := config.MustLoad()
+	logger := logger.MustLoad(config.Logger)
+	db := database.MustLoad(config.Database)
+	ctx := context.Background()
+
+	migrator := database.MustLoadMigrator(db, config.Database)
+
+	// Кроме migrate, команды, как и сервер, работают только с актуальной схемой
+	if len(os.Args) < 2 || os.Args[1] != "migrate" {
+		if err := migrator.Check(ctx); err != nil {
+			log.Fatalf("%v, run shopctl migrate up", err)
+		}
+	}
+
+	cli := shopctl.New(repository.NewHolderRepository(db, logger, repository.WithCoinLifetime(config.Coins.Lifetime())), migrator, os.Stdout)
+
+	if err := cli.Run(ctx, os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "shopctl: %v\n", err)
+
+		if errors.Is(err, shopctl.ErrUsage) || errors.Is(err, shopctl.ErrUnknownCommand) {
+			cli.Usage()
+		}
+
+		os.Exit(1)
+	}
+}
@@ -5,11 +5,23 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
+// Драйверы базы данных
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// DatabaseConfig описывает подключение к базе данных.
+// Для драйвера sqlite используется только Path и настройки пула, параметры сервера Postgres игнорируются.
 type DatabaseConfig struct {
+	Driver                        string
+	Path                          string
 	Host                          string
 	Port                          string
 	User                          string
@@ -18,19 +30,102 @@ type DatabaseConfig struct {
 	MaxConnections                int
 	MaxIdleConnections            int
 	MaxConnectionsLifetimeMinutes int
+	// ReplicaHosts - адреса реплик для чтения вида host или host:port, остальные параметры подключения берутся у основной базы
+	ReplicaHosts []string
 }
 
 // DSN возвращает строку подключения к базе данных для GORM.
 func (c *DatabaseConfig) DSN() string {
+	if c.Driver == DriverSQLite {
+		// Внешние ключи в SQLite по умолчанию выключены, а immediate-транзакции сразу берут блокировку записи
+		// и ждут её busy_timeout вместо ошибки при попытке повысить блокировку посреди транзакции
+		return fmt.Sprintf("file:%s?_foreign_keys=on&_busy_timeout=5000&_journal_mode=WAL&_txlock=immediate", c.Path)
+	}
+
 	return fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s sslmode=disable TimeZone=UTC",
 		c.Host, c.Port, c.User, c.DBName, c.Password)
 }
 
+// Replicas возвращает конфиги подключения к репликам для чтения.
+func (c *DatabaseConfig) Replicas() []DatabaseConfig {
+	replicas := make([]DatabaseConfig, 0, len(c.ReplicaHosts))
+
+	for _, address := range c.ReplicaHosts {
+		replica := *c
+		replica.ReplicaHosts = nil
+
+		host, port, found := strings.Cut(address, ":")
+		replica.Host = host
+
+		if found {
+			replica.Port = port
+		}
+
+		replicas = append(replicas, replica)
+	}
+
+	return replicas
+}
+
 type AuthConfig struct {
 	JwtKey             string
 	TokenLifetimeHours int
 }
 
+// TransferConfig описывает ограничения на исходящие переводы монет.
+// Нулевое значение ограничения означает, что оно не применяется.
+type TransferConfig struct {
+	MaxAmount          int
+	DailyLimit         int
+	MinAccountAgeHours int
+}
+
+// CoinsConfig описывает срок жизни монет. Нулевой срок отключает сгорание.
+type CoinsConfig struct {
+	LifetimeDays int
+}
+
+// Lifetime возвращает срок жизни монет или 0, если монеты не сгорают.
+func (c *CoinsConfig) Lifetime() time.Duration {
+	if c.LifetimeDays <= 0 {
+		return 0
+	}
+
+	return time.Duration(c.LifetimeDays) * 24 * time.Hour
+}
+
+// JobsConfig описывает интервалы запуска фоновых задач. Неположительный интервал отключает задачу.
+type JobsConfig struct {
+	GrantsIntervalMinutes int
+	ExpiryIntervalMinutes int
+	SalesIntervalMinutes  int
+	// Доставка вебхуков должна быть почти мгновенной, поэтому её интервал задаётся в секундах
+	WebhooksIntervalSeconds int
+}
+
+// WebhooksConfig описывает доставку событий во внешние вебхуки.
+// Задержка перед повторной попыткой удваивается с каждой неудачной попыткой, начиная с BackoffSeconds.
+type WebhooksConfig struct {
+	MaxAttempts    int
+	BackoffSeconds int
+	TimeoutSeconds int
+	// LeaseSeconds - наименьший срок, на который доставка резервируется за отправляющим её экземпляром сервиса
+	LeaseSeconds int
+}
+
+// CacheConfig описывает кеши поверх базы данных. Нулевой срок жизни или размер отключает кеш.
+type CacheConfig struct {
+	GoodsTTLSeconds int
+	// InfoSize - наибольшее число пользователей, чьи сводки /api/info хранятся в памяти
+	InfoSize       int
+	InfoTTLSeconds int
+}
+
+// GRPCConfig описывает gRPC API, который слушает отдельный от REST API порт.
+type GRPCConfig struct {
+	Port string
+}
+
 type CorsConfig struct {
 	AllowedOrigins   string
 	AllowedMethods   string
@@ -47,6 +142,12 @@ type LoggerConfig struct {
 type Config struct {
 	Database DatabaseConfig
 	Auth     AuthConfig
+	Transfer TransferConfig
+	Coins    CoinsConfig
+	Jobs     JobsConfig
+	Webhooks WebhooksConfig
+	Cache    CacheConfig
+	GRPC     GRPCConfig
 	Cors     CorsConfig
 	Logger   LoggerConfig
 }
@@ -63,6 +164,32 @@ func LoadEnv() error {
 }
 
 func LoadDatabaseConfig() (DatabaseConfig, error) {
+	driver := os.Getenv("DATABASE_DRIVER")
+	if driver == "" {
+		driver = DriverPostgres
+	}
+
+	if driver != DriverPostgres && driver != DriverSQLite {
+		return DatabaseConfig{}, fmt.Errorf("unsupported DATABASE_DRIVER %q, expected %s or %s", driver, DriverPostgres, DriverSQLite)
+	}
+
+	path := os.Getenv("DATABASE_PATH")
+	if path == "" {
+		path = "shop.db"
+	}
+
+	var replicaHosts []string
+
+	for _, host := range strings.Split(os.Getenv("DATABASE_REPLICA_HOSTS"), ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			replicaHosts = append(replicaHosts, host)
+		}
+	}
+
+	if driver == DriverSQLite && len(replicaHosts) > 0 {
+		return DatabaseConfig{}, fmt.Errorf("DATABASE_REPLICA_HOSTS is not supported with %s driver", DriverSQLite)
+	}
+
 	maxConnections, err := strconv.Atoi(os.Getenv("DATABASE_MAX_CONNECTIONS"))
 	if err != nil {
 		return DatabaseConfig{}, fmt.Errorf("error converting DATABASE_MAX_CONNECTIONS: %v", err)
@@ -79,6 +206,8 @@ func LoadDatabaseConfig() (DatabaseConfig, error) {
 	}
 
 	return DatabaseConfig{
+		Driver:                        driver,
+		Path:                          path,
 		Host:                          os.Getenv("DATABASE_HOST"),
 		Port:                          os.Getenv("DATABASE_PORT"),
 		User:                          os.Getenv("DATABASE_USERNAME"),
@@ -87,6 +216,7 @@ func LoadDatabaseConfig() (DatabaseConfig, error) {
 		MaxConnections:                maxConnections,
 		MaxIdleConnections:            maxIdleConnections,
 		MaxConnectionsLifetimeMinutes: maxConnectionsLifetimeMinutes,
+		ReplicaHosts:                  replicaHosts,
 	}, nil
 }
 
@@ -104,6 +234,141 @@ func LoadAuthConfig() (AuthConfig, error) {
 	}, nil
 }
 
+func LoadTransferConfig() (TransferConfig, error) {
+	limits := map[string]int{
+		"TRANSFER_MAX_AMOUNT":            0,
+		"TRANSFER_DAILY_LIMIT":           0,
+		"TRANSFER_MIN_ACCOUNT_AGE_HOURS": 0,
+	}
+
+	for key := range limits {
+		value := os.Getenv(key)
+		if value == "" {
+			continue
+		}
+
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			return TransferConfig{}, fmt.Errorf("error converting %s: %v", key, err)
+		}
+
+		if limit < 0 {
+			return TransferConfig{}, fmt.Errorf("%s must not be negative", key)
+		}
+
+		limits[key] = limit
+	}
+
+	return TransferConfig{
+		MaxAmount:          limits["TRANSFER_MAX_AMOUNT"],
+		DailyLimit:         limits["TRANSFER_DAILY_LIMIT"],
+		MinAccountAgeHours: limits["TRANSFER_MIN_ACCOUNT_AGE_HOURS"],
+	}, nil
+}
+
+func LoadCoinsConfig() CoinsConfig {
+	lifetimeDays, err := strconv.Atoi(os.Getenv("COINS_LIFETIME_DAYS"))
+	if err != nil {
+		lifetimeDays = 365
+	}
+
+	return CoinsConfig{
+		LifetimeDays: lifetimeDays,
+	}
+}
+
+func LoadJobsConfig() JobsConfig {
+	grantsInterval, err := strconv.Atoi(os.Getenv("GRANTS_JOB_INTERVAL_MINUTES"))
+	if err != nil {
+		grantsInterval = 60
+	}
+
+	expiryInterval, err := strconv.Atoi(os.Getenv("COINS_EXPIRY_JOB_INTERVAL_MINUTES"))
+	if err != nil {
+		expiryInterval = 60
+	}
+
+	salesInterval, err := strconv.Atoi(os.Getenv("SALES_JOB_INTERVAL_MINUTES"))
+	if err != nil {
+		salesInterval = 5
+	}
+
+	webhooksInterval, err := strconv.Atoi(os.Getenv("WEBHOOKS_JOB_INTERVAL_SECONDS"))
+	if err != nil {
+		webhooksInterval = 10
+	}
+
+	return JobsConfig{
+		GrantsIntervalMinutes:   grantsInterval,
+		ExpiryIntervalMinutes:   expiryInterval,
+		SalesIntervalMinutes:    salesInterval,
+		WebhooksIntervalSeconds: webhooksInterval,
+	}
+}
+
+func LoadWebhooksConfig() WebhooksConfig {
+	maxAttempts, err := strconv.Atoi(os.Getenv("WEBHOOKS_MAX_ATTEMPTS"))
+	if err != nil || maxAttempts <= 0 {
+		maxAttempts = 8
+	}
+
+	backoff, err := strconv.Atoi(os.Getenv("WEBHOOKS_BACKOFF_SECONDS"))
+	if err != nil || backoff <= 0 {
+		backoff = 30
+	}
+
+	timeout, err := strconv.Atoi(os.Getenv("WEBHOOKS_TIMEOUT_SECONDS"))
+	if err != nil || timeout <= 0 {
+		timeout = 5
+	}
+
+	lease, err := strconv.Atoi(os.Getenv("WEBHOOKS_LEASE_SECONDS"))
+	if err != nil || lease <= 0 {
+		lease = 60
+	}
+
+	return WebhooksConfig{
+		MaxAttempts:    maxAttempts,
+		BackoffSeconds: backoff,
+		TimeoutSeconds: timeout,
+		LeaseSeconds:   lease,
+	}
+}
+
+func LoadCacheConfig() CacheConfig {
+	goodsTTL, err := strconv.Atoi(os.Getenv("GOODS_CACHE_TTL_SECONDS"))
+	if err != nil || goodsTTL < 0 {
+		goodsTTL = 60
+	}
+
+	infoSize, err := strconv.Atoi(os.Getenv("INFO_CACHE_SIZE"))
+	if err != nil || infoSize < 0 {
+		infoSize = 0
+	}
+
+	infoTTL, err := strconv.Atoi(os.Getenv("INFO_CACHE_TTL_SECONDS"))
+	if err != nil || infoTTL < 0 {
+		infoTTL = 30
+	}
+
+	return CacheConfig{
+		GoodsTTLSeconds: goodsTTL,
+		InfoSize:        infoSize,
+		InfoTTLSeconds:  infoTTL,
+	}
+}
+
+func LoadGRPCConfig() GRPCConfig {
+	port := os.Getenv("GRPC_PORT")
+	if port == "" {
+		port = "9090"
+	}
+
+	return GRPCConfig{
+		Port: port,
+	}
+}
+
 func LoadCorsConfig() CorsConfig {
 	return CorsConfig{
 		AllowedOrigins:   os.Getenv("CORS_ALLOWED_ORIGINS"),
@@ -140,9 +405,20 @@ func MustLoad() *Config {
 		log.Fatalf("Error loading auth config: %v", err)
 	}
 
+	transferConfig, err := LoadTransferConfig()
+	if err != nil {
+		log.Fatalf("Error loading transfer config: %v", err)
+	}
+
 	return &Config{
 		Database: dbConfig,
 		Auth:     authConfig,
+		Transfer: transferConfig,
+		Coins:    LoadCoinsConfig(),
+		Jobs:     LoadJobsConfig(),
+		Webhooks: LoadWebhooksConfig(),
+		Cache:    LoadCacheConfig(),
+		GRPC:     LoadGRPCConfig(),
 		Cors:     LoadCorsConfig(),
 		Logger:   LoadLoggerConfig(),
 	}
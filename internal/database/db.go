@@ -5,14 +5,16 @@ import (
 	"time"
 
 	"github.com/maksemen2/avito-shop/config"
+	"github.com/maksemen2/avito-shop/migrations"
 	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
 // MustLoad подключается к базе данных и возвращает объект gorm.DB. Завершает работу приложения при ошибке.
 func MustLoad(config config.DatabaseConfig) *gorm.DB {
-	db, err := gorm.Open(postgres.Open(config.DSN()), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	db, err := gorm.Open(dialector(config), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
 	if err != nil {
 		log.Fatalf("failed to connect to database: %v", err)
 	}
@@ -28,3 +30,38 @@ func MustLoad(config config.DatabaseConfig) *gorm.DB {
 
 	return db
 }
+
+// MustLoadReplicas подключается к репликам для чтения из конфига. Завершает работу приложения при ошибке.
+func MustLoadReplicas(config config.DatabaseConfig) []*gorm.DB {
+	replicas := make([]*gorm.DB, 0, len(config.ReplicaHosts))
+	for _, replicaConfig := range config.Replicas() {
+		replicas = append(replicas, MustLoad(replicaConfig))
+	}
+
+	return replicas
+}
+
+// MustLoadMigrator возвращает Migrator со встроенными миграциями для драйвера из конфига.
+// Завершает работу приложения при ошибке.
+func MustLoadMigrator(db *gorm.DB, config config.DatabaseConfig) *Migrator {
+	source, err := migrations.ForDriver(config.Driver)
+	if err != nil {
+		log.Fatalf("failed to load migrations: %v", err)
+	}
+
+	migrator, err := NewMigrator(db, source)
+	if err != nil {
+		log.Fatalf("failed to load migrations: %v", err)
+	}
+
+	return migrator
+}
+
+// dialector выбирает драйвер GORM по DatabaseConfig.Driver.
+func dialector(cfg config.DatabaseConfig) gorm.Dialector {
+	if cfg.Driver == config.DriverSQLite {
+		return sqlite.Open(cfg.DSN())
+	}
+
+	return postgres.Open(cfg.DSN())
+}
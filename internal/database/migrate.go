@@ -0,0 +1,283 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidMigrations = errors.New("invalid migrations")
+	ErrSchemaOutdated    = errors.New("database schema is outdated")
+	ErrUnknownVersion    = errors.New("unknown schema version")
+)
+
+const migrationsTable = "schema_migrations"
+
+// SchemaMigration - запись о применённой миграции.
+type SchemaMigration struct {
+	Version   uint      `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"size:255"`
+	AppliedAt time.Time `gorm:"autoCreateTime"`
+}
+
+func (SchemaMigration) TableName() string {
+	return migrationsTable
+}
+
+// Migration - шаг миграции схемы с SQL для применения и отката.
+type Migration struct {
+	Version uint
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Migrator применяет и откатывает миграции из файловой системы вида <версия>_<название>.(up|down).sql.
+// Каждая миграция выполняется в отдельной транзакции вместе с записью в schema_migrations,
+// поэтому при ошибке схема остаётся на последней успешно применённой версии.
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+func NewMigrator(db *gorm.DB, source fs.FS) (*Migrator, error) {
+	migrations, err := readMigrations(source)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+	}, nil
+}
+
+func readMigrations(source fs.FS) ([]Migration, error) {
+	pattern := regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+	entries, err := fs.ReadDir(source, ".")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidMigrations, err)
+	}
+
+	byVersion := make(map[uint]*Migration)
+
+	for _, entry := range entries {
+		match := pattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseUint(match[1], 10, 32)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("%w: bad version in %s", ErrInvalidMigrations, entry.Name())
+		}
+
+		content, err := fs.ReadFile(source, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidMigrations, err)
+		}
+
+		migration, ok := byVersion[uint(version)]
+		if !ok {
+			migration = &Migration{Version: uint(version), Name: match[2]}
+			byVersion[uint(version)] = migration
+		}
+
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("%w: version %d has different names", ErrInvalidMigrations, version)
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("%w: version %d needs both up and down steps", ErrInvalidMigrations, migration.Version)
+		}
+
+		migrations = append(migrations, *migration)
+	}
+
+	if len(migrations) == 0 {
+		return nil, fmt.Errorf("%w: no migrations found", ErrInvalidMigrations)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Latest возвращает версию последней известной миграции.
+func (m *Migrator) Latest() uint {
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Version возвращает текущую версию схемы. База без таблицы миграций имеет версию 0.
+func (m *Migrator) Version(ctx context.Context) (uint, error) {
+	db := m.db.WithContext(ctx)
+	if !db.Migrator().HasTable(migrationsTable) {
+		return 0, nil
+	}
+
+	var version uint
+	if err := db.Model(&SchemaMigration{}).Select("COALESCE(MAX(version), 0)").Scan(&version).Error; err != nil {
+		return 0, err
+	}
+
+	return version, nil
+}
+
+// Check возвращает ErrSchemaOutdated, если в базе применены не все известные миграции.
+// Более новая схема ошибкой не считается: после отката приложения база может остаться на следующей версии.
+func (m *Migrator) Check(ctx context.Context) error {
+	version, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+
+	if version < m.Latest() {
+		return fmt.Errorf("%w: version %d, required %d", ErrSchemaOutdated, version, m.Latest())
+	}
+
+	return nil
+}
+
+// Up применяет все ещё не применённые миграции и возвращает их.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	version, err := m.prepare(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+
+	for _, migration := range m.migrations {
+		if migration.Version <= version {
+			continue
+		}
+
+		err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(migration.Up).Error; err != nil {
+				return err
+			}
+
+			return tx.Create(&SchemaMigration{Version: migration.Version, Name: migration.Name}).Error
+		})
+		if err != nil {
+			return applied, fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+
+		applied = append(applied, migration)
+	}
+
+	return applied, nil
+}
+
+// Down откатывает steps последних применённых миграций и возвращает их в порядке отката.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	version, err := m.prepare(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var reverted []Migration
+
+	for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+		migration := m.migrations[i]
+		if migration.Version > version {
+			continue
+		}
+
+		err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(migration.Down).Error; err != nil {
+				return err
+			}
+
+			return tx.Delete(&SchemaMigration{}, migration.Version).Error
+		})
+		if err != nil {
+			return reverted, fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+
+		reverted = append(reverted, migration)
+	}
+
+	return reverted, nil
+}
+
+// Force отмечает схему как находящуюся на версии version, не выполняя SQL.
+// Нужна для баз, созданных до появления миграций, и для восстановления после ручных исправлений.
+func (m *Migrator) Force(ctx context.Context, version uint) error {
+	if version != 0 && !m.known(version) {
+		return fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+
+	if _, err := m.prepare(ctx); err != nil {
+		return err
+	}
+
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("version > ?", version).Delete(&SchemaMigration{}).Error; err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if migration.Version > version {
+				break
+			}
+
+			record := SchemaMigration{Version: migration.Version, Name: migration.Name}
+			if err := tx.Where(SchemaMigration{Version: migration.Version}).FirstOrCreate(&record).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// prepare создаёт таблицу миграций при необходимости и возвращает текущую версию.
+// Версия, которой нет среди известных миграций, означает схему новее приложения - такую базу не трогаем.
+func (m *Migrator) prepare(ctx context.Context) (uint, error) {
+	if err := m.db.WithContext(ctx).AutoMigrate(&SchemaMigration{}); err != nil {
+		return 0, err
+	}
+
+	version, err := m.Version(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if version != 0 && !m.known(version) {
+		return 0, fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+
+	return version, nil
+}
+
+func (m *Migrator) known(version uint) bool {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return true
+		}
+	}
+
+	return false
+}
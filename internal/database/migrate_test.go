@@ -0,0 +1,159 @@
+package database_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/maksemen2/avito-shop/config"
+	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/migrations"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+func setupTestMigrator(t *testing.T, source fstest.MapFS) (*database.Migrator, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormLogger.Default.LogMode(gormLogger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to open in-memory database: %v", err)
+	}
+
+	migrator, err := database.NewMigrator(db, source)
+	if err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+
+	return migrator, db
+}
+
+func testMigrations() fstest.MapFS {
+	return fstest.MapFS{
+		"0001_init.up.sql":        {Data: []byte("CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT NOT NULL);")},
+		"0001_init.down.sql":      {Data: []byte("DROP TABLE items;")},
+		"0002_add_price.up.sql":   {Data: []byte("ALTER TABLE items ADD COLUMN price INTEGER NOT NULL DEFAULT 0;")},
+		"0002_add_price.down.sql": {Data: []byte("ALTER TABLE items DROP COLUMN price;")},
+		"0003_broken.up.sql":      {Data: []byte("CREATE TABLE tags (id INTEGER PRIMARY KEY); ALTER TABLE missing ADD COLUMN x INTEGER;")},
+		"0003_broken.down.sql":    {Data: []byte("DROP TABLE tags;")},
+		"README.md":               {Data: []byte("not a migration")},
+	}
+}
+
+func TestMigrator_UpDown(t *testing.T) {
+	migrator, db := setupTestMigrator(t, testMigrations())
+	ctx := context.Background()
+
+	assert.Equal(t, uint(3), migrator.Latest())
+	assert.ErrorIs(t, migrator.Check(ctx), database.ErrSchemaOutdated)
+
+	// Третья миграция падает и откатывается целиком, схема остаётся на второй версии
+	applied, err := migrator.Up(ctx)
+	assert.Error(t, err)
+	assert.Len(t, applied, 2)
+	assert.True(t, db.Migrator().HasColumn("items", "price"))
+	assert.False(t, db.Migrator().HasTable("tags"))
+
+	version, err := migrator.Version(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), version)
+
+	reverted, err := migrator.Down(ctx, 1)
+	assert.NoError(t, err)
+	assert.Len(t, reverted, 1)
+	assert.Equal(t, "add_price", reverted[0].Name)
+	assert.False(t, db.Migrator().HasColumn("items", "price"))
+
+	assert.NoError(t, migrator.Force(ctx, 3))
+	assert.NoError(t, migrator.Check(ctx))
+
+	assert.ErrorIs(t, migrator.Force(ctx, 7), database.ErrUnknownVersion)
+}
+
+func TestMigrator_Invalid(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormLogger.Default.LogMode(gormLogger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to open in-memory database: %v", err)
+	}
+
+	_, err = database.NewMigrator(db, fstest.MapFS{
+		"0001_init.up.sql": {Data: []byte("CREATE TABLE items (id INTEGER PRIMARY KEY);")},
+	})
+	assert.ErrorIs(t, err, database.ErrInvalidMigrations, "migration without down step must be rejected")
+
+	_, err = database.NewMigrator(db, fstest.MapFS{})
+	assert.ErrorIs(t, err, database.ErrInvalidMigrations)
+
+	// Встроенные миграции должны разбираться без ошибок и иметь одинаковые версии для всех драйверов
+	latest := make(map[string]uint)
+
+	for _, driver := range []string{config.DriverPostgres, config.DriverSQLite} {
+		source, err := migrations.ForDriver(driver)
+		if err != nil {
+			t.Fatalf("failed to load %s migrations: %v", driver, err)
+		}
+
+		migrator, err := database.NewMigrator(db, source)
+		if assert.NoError(t, err, driver) {
+			latest[driver] = migrator.Latest()
+		}
+	}
+
+	assert.Equal(t, latest[config.DriverPostgres], latest[config.DriverSQLite])
+
+	_, err = migrations.ForDriver("mysql")
+	assert.Error(t, err)
+}
+
+// Схема из SQL-миграций должна содержать все таблицы и колонки моделей, с которыми работают репозитории.
+func TestMigrations_SQLiteMatchesModels(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:?_foreign_keys=on"), &gorm.Config{Logger: gormLogger.Default.LogMode(gormLogger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to open in-memory database: %v", err)
+	}
+
+	source, err := migrations.ForDriver(config.DriverSQLite)
+	if err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+
+	migrator, err := database.NewMigrator(db, source)
+	if err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+
+	ctx := context.Background()
+
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("failed to apply migrations: %v", err)
+	}
+
+	models := []any{&database.User{}, &database.Purchase{}, &database.Transaction{}, &database.Good{}, &database.CoinRequest{}, &database.GrantProgram{}, &database.Grant{}, &database.CoinLot{}, &database.CoinExpiration{}, &database.ItemTransfer{}, &database.Listing{}, &database.PromoCode{}, &database.PromoRedemption{}, &database.PriceSchedule{}, &database.GoodVariant{}, &database.WishlistItem{}, &database.Notification{}, &database.OutboxEvent{}, &database.Webhook{}, &database.WebhookDelivery{}}
+
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			t.Fatalf("failed to parse model %T: %v", model, err)
+		}
+
+		if !assert.True(t, db.Migrator().HasTable(model), "table %s", stmt.Schema.Table) {
+			continue
+		}
+
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName != "" {
+				assert.True(t, db.Migrator().HasColumn(model, field.DBName), "column %s.%s", stmt.Schema.Table, field.DBName)
+			}
+		}
+	}
+
+	var goods int64
+
+	assert.NoError(t, db.Model(&database.Good{}).Count(&goods).Error)
+	assert.Equal(t, int64(10), goods, "goods must be seeded")
+
+	reverted, err := migrator.Down(ctx, int(migrator.Latest()))
+	assert.NoError(t, err)
+	assert.Len(t, reverted, int(migrator.Latest()))
+	assert.False(t, db.Migrator().HasTable(&database.User{}))
+}
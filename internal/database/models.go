@@ -9,30 +9,326 @@ type User struct {
 	Username     string    `gorm:"uniqueIndex;size:255"`
 	PasswordHash string    `gorm:"type:char(60)"`
 	Coins        int       `gorm:"default:1000;check:coins >= 0"`
+	IsAdmin      bool      `gorm:"default:false"`
+	Locked       bool      `gorm:"default:false"`
 	CreatedAt    time.Time `gorm:"autoCreateTime"`
 }
 
+// Transaction - перевод монет между пользователями.
+// Для сторнирующего перевода ReversalOfID указывает на отменённый перевод, уникальность не даёт отменить его дважды.
 type Transaction struct {
-	ID         uint      `gorm:"primaryKey;index"`
-	FromUserID uint      `gorm:"index"`
-	FromUser   *User     `gorm:"foreignKey:FromUserID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
-	ToUserID   uint      `gorm:"index"`
-	ToUser     *User     `gorm:"foreignKey:ToUserID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
-	Amount     int       `gorm:"check:amount > 0"`
-	CreatedAt  time.Time `gorm:"autoCreateTime"`
+	ID           uint         `gorm:"primaryKey;index"`
+	FromUserID   uint         `gorm:"index"`
+	FromUser     *User        `gorm:"foreignKey:FromUserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	ToUserID     uint         `gorm:"index"`
+	ToUser       *User        `gorm:"foreignKey:ToUserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Amount       int          `gorm:"check:amount > 0"`
+	Kind         string       `gorm:"size:16;not null;default:transfer"`
+	ReversalOfID *uint        `gorm:"uniqueIndex"`
+	ReversalOf   *Transaction `gorm:"foreignKey:ReversalOfID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	CreatedAt    time.Time    `gorm:"autoCreateTime"`
 }
 
+// Виды переводов. В суточный лимит отправителя входят только transfer и coin_request.
+const (
+	TransactionKindTransfer    = "transfer"
+	TransactionKindCoinRequest = "coin_request"
+	TransactionKindListing     = "listing"
+	TransactionKindReversal    = "reversal"
+)
+
 type Good struct {
 	ID    uint   `gorm:"primaryKey"`
 	Type  string `gorm:"uniqueIndex;size:255"`
 	Price int    `gorm:"check:price > 0"`
 }
 
+// Purchase - единица товара в инвентаре пользователя UserID.
+// Для подарков GiftedByID указывает на купившего пользователя, а GiftedToID - на получателя:
+// UserID меняется при передаче и перепродаже единицы, а запись о подарке остаётся прежней.
+// Единица, выставленная на продажу, зарезервирована за объявлением ListingID и не может быть передана.
+// Для товаров с вариантами VariantID указывает на купленный вариант.
+// Если при покупке применялся промокод, PromoCodeID указывает на него, а Discount - на размер скидки в монетах.
 type Purchase struct {
+	ID          uint         `gorm:"primaryKey"`
+	UserID      uint         `gorm:"index"`
+	User        *User        `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	GoodID      uint         `gorm:"index"`
+	Good        *Good        `gorm:"foreignKey:GoodID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	GiftedByID  *uint        `gorm:"index"`
+	GiftedBy    *User        `gorm:"foreignKey:GiftedByID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	GiftedToID  *uint        `gorm:"index"`
+	GiftedTo    *User        `gorm:"foreignKey:GiftedToID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	ListingID   *uint        `gorm:"index"`
+	VariantID   *uint        `gorm:"index"`
+	Variant     *GoodVariant `gorm:"foreignKey:VariantID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	PromoCodeID *uint        `gorm:"index"`
+	PromoCode   *PromoCode   `gorm:"foreignKey:PromoCodeID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Discount    int          `gorm:"default:0;check:discount >= 0"`
+	CreatedAt   time.Time    `gorm:"autoCreateTime"`
+}
+
+// Статусы запроса монет
+const (
+	CoinRequestPending  = "pending"
+	CoinRequestAccepted = "accepted"
+	CoinRequestDeclined = "declined"
+)
+
+// CoinRequest - запрос монет одного пользователя (Requester) у другого (Payer).
+type CoinRequest struct {
+	ID          uint       `gorm:"primaryKey"`
+	RequesterID uint       `gorm:"index"`
+	Requester   *User      `gorm:"foreignKey:RequesterID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	PayerID     uint       `gorm:"index"`
+	Payer       *User      `gorm:"foreignKey:PayerID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Amount      int        `gorm:"check:amount > 0"`
+	Status      string     `gorm:"size:16;index;default:pending"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime"`
+	ResolvedAt  *time.Time `gorm:"default:null"`
+}
+
+// Периоды начисления программ пособий
+const (
+	GrantPeriodDaily   = "daily"
+	GrantPeriodWeekly  = "weekly"
+	GrantPeriodMonthly = "monthly"
+)
+
+// GrantProgram - программа регулярного начисления монет всем пользователям.
+type GrantProgram struct {
+	ID        uint      `gorm:"primaryKey"`
+	Name      string    `gorm:"uniqueIndex;size:255"`
+	Amount    int       `gorm:"check:amount > 0"`
+	Period    string    `gorm:"size:16"`
+	Active    bool      `gorm:"default:true"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// Grant - начисление по программе пособий конкретному пользователю за конкретный период.
+// Уникальность (ProgramID, UserID, Period) гарантирует, что за один период пользователь получит начисление один раз.
+type Grant struct {
+	ID        uint          `gorm:"primaryKey"`
+	ProgramID uint          `gorm:"uniqueIndex:idx_grants_program_user_period"`
+	Program   *GrantProgram `gorm:"foreignKey:ProgramID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserID    uint          `gorm:"uniqueIndex:idx_grants_program_user_period;index"`
+	User      *User         `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Amount    int           `gorm:"check:amount > 0"`
+	Period    string        `gorm:"uniqueIndex:idx_grants_program_user_period;size:32"`
+	CreatedAt time.Time     `gorm:"autoCreateTime"`
+}
+
+// Источники поступления монет
+const (
+	CoinSourceInitial  = "initial"
+	CoinSourceTransfer = "transfer"
+	CoinSourceGrant    = "grant"
+	CoinSourceAdmin    = "admin"
+)
+
+// CoinLot - партия монет, поступившая пользователю одним начислением.
+// Списания расходуют партии от старых к новым, а непотраченный остаток партии сгорает в момент ExpiresAt.
+// Срок сгорания фиксируется при создании партии, пустой ExpiresAt означает, что партия не сгорает.
+type CoinLot struct {
+	ID        uint       `gorm:"primaryKey"`
+	UserID    uint       `gorm:"index:idx_coin_lots_user_created"`
+	User      *User      `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Source    string     `gorm:"size:16"`
+	Amount    int        `gorm:"check:amount > 0"`
+	Remaining int        `gorm:"check:remaining >= 0"`
+	ExpiresAt *time.Time `gorm:"index"`
+	CreatedAt time.Time  `gorm:"autoCreateTime;index:idx_coin_lots_user_created"`
+}
+
+// CoinExpiration - запись истории о сгорании монет пользователя.
+type CoinExpiration struct {
 	ID        uint      `gorm:"primaryKey"`
 	UserID    uint      `gorm:"index"`
-	User      *User     `gorm:"foreignKey:UserID"`
-	GoodID    uint      `gorm:"index"`
-	Good      *Good     `gorm:"foreignKey:GoodID"`
+	User      *User     `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Amount    int       `gorm:"check:amount > 0"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// ItemTransfer - запись истории о передаче Quantity единиц товара от одного пользователя другому.
+// При удалении пользователя запись сохраняется, а ссылка на него обнуляется.
+type ItemTransfer struct {
+	ID         uint         `gorm:"primaryKey"`
+	FromUserID *uint        `gorm:"index"`
+	FromUser   *User        `gorm:"foreignKey:FromUserID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	ToUserID   *uint        `gorm:"index"`
+	ToUser     *User        `gorm:"foreignKey:ToUserID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	GoodID     uint         `gorm:"index"`
+	Good       *Good        `gorm:"foreignKey:GoodID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	VariantID  *uint        `gorm:"index"`
+	Variant    *GoodVariant `gorm:"foreignKey:VariantID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Quantity   int          `gorm:"check:quantity > 0"`
+	CreatedAt  time.Time    `gorm:"autoCreateTime"`
+}
+
+// Статусы объявления о продаже
+const (
+	ListingActive    = "active"
+	ListingSold      = "sold"
+	ListingCancelled = "cancelled"
+)
+
+// Listing - объявление о продаже одной единицы товара из инвентаря продавца за Price монет.
+// Для товаров с вариантами VariantID указывает на вариант продаваемой единицы.
+type Listing struct {
+	ID        uint         `gorm:"primaryKey"`
+	SellerID  uint         `gorm:"index"`
+	Seller    *User        `gorm:"foreignKey:SellerID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	GoodID    uint         `gorm:"index"`
+	Good      *Good        `gorm:"foreignKey:GoodID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	VariantID *uint        `gorm:"index"`
+	Variant   *GoodVariant `gorm:"foreignKey:VariantID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Price     int          `gorm:"check:price > 0"`
+	Status    string       `gorm:"size:16;index;default:active"`
+	BuyerID   *uint        `gorm:"index"`
+	Buyer     *User        `gorm:"foreignKey:BuyerID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	CreatedAt time.Time    `gorm:"autoCreateTime"`
+	ClosedAt  *time.Time   `gorm:"default:null"`
+}
+
+// Типы скидки промокода
+const (
+	DiscountPercent = "percent"
+	DiscountFixed   = "fixed"
+)
+
+// PromoCode - промокод на скидку при покупке товара.
+// Пустой GoodID означает, что промокод действует на все товары, нулевые лимиты - отсутствие ограничений.
+type PromoCode struct {
+	ID             uint       `gorm:"primaryKey"`
+	Code           string     `gorm:"uniqueIndex;size:64"`
+	DiscountType   string     `gorm:"size:16"`
+	DiscountValue  int        `gorm:"check:discount_value > 0"`
+	GoodID         *uint      `gorm:"index"`
+	Good           *Good      `gorm:"foreignKey:GoodID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	MaxUses        int        `gorm:"default:0"`
+	MaxUsesPerUser int        `gorm:"default:0"`
+	UsedCount      int        `gorm:"default:0"`
+	ValidFrom      *time.Time `gorm:"default:null"`
+	ValidUntil     *time.Time `gorm:"default:null"`
+	Active         bool       `gorm:"default:true"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime"`
+}
+
+// PromoRedemption - факт применения промокода пользователем, по которому считается лимит на пользователя.
+type PromoRedemption struct {
+	ID          uint       `gorm:"primaryKey"`
+	PromoCodeID uint       `gorm:"index:idx_promo_redemptions_promo_user"`
+	PromoCode   *PromoCode `gorm:"foreignKey:PromoCodeID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserID      uint       `gorm:"index:idx_promo_redemptions_promo_user"`
+	User        *User      `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	PurchaseID  uint       `gorm:"index"`
+	Purchase    *Purchase  `gorm:"foreignKey:PurchaseID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime"`
+}
+
+// PriceSchedule - временное изменение цены товара в окне [StartsAt, EndsAt).
+// Задаётся либо новой ценой Price, либо скидкой DiscountPercent от базовой цены товара.
+// Notified отмечает, что о начале действия расписания уже уведомили пользователей.
+type PriceSchedule struct {
+	ID              uint      `gorm:"primaryKey"`
+	GoodID          uint      `gorm:"index:idx_price_schedules_good_window"`
+	Good            *Good     `gorm:"foreignKey:GoodID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Price           int       `gorm:"default:0;check:price >= 0"`
+	DiscountPercent int       `gorm:"default:0;check:discount_percent >= 0 AND discount_percent < 100"`
+	StartsAt        time.Time `gorm:"index:idx_price_schedules_good_window"`
+	EndsAt          time.Time `gorm:"index:idx_price_schedules_good_window"`
+	Notified        bool      `gorm:"default:false"`
+	CreatedAt       time.Time `gorm:"autoCreateTime"`
+}
+
+// GoodVariant - вариант товара (размер, цвет и т.п.) со своим остатком на складе.
+// Цена варианта равна текущей цене товара плюс PriceDelta.
+type GoodVariant struct {
+	ID         uint      `gorm:"primaryKey"`
+	GoodID     uint      `gorm:"uniqueIndex:idx_good_variants_good_name"`
+	Good       *Good     `gorm:"foreignKey:GoodID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Name       string    `gorm:"uniqueIndex:idx_good_variants_good_name;size:64"`
+	Stock      int       `gorm:"default:0;check:stock >= 0"`
+	PriceDelta int       `gorm:"default:0"`
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+}
+
+// WishlistItem - товар в списке желаний пользователя.
+type WishlistItem struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"uniqueIndex:idx_wishlist_items_user_good"`
+	User      *User     `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	GoodID    uint      `gorm:"uniqueIndex:idx_wishlist_items_user_good;index"`
+	Good      *Good     `gorm:"foreignKey:GoodID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// Типы уведомлений
+const (
+	NotificationSale     = "sale"
+	NotificationRestock  = "restock"
+	NotificationTransfer = "transfer"
+	NotificationGift     = "gift"
+	NotificationItems    = "items"
+	NotificationGrant    = "grant"
+	NotificationAdmin    = "admin"
+)
+
+// Notification - уведомление пользователя. Непрочитанные уведомления имеют пустой ReadAt.
+type Notification struct {
+	ID        uint       `gorm:"primaryKey"`
+	UserID    uint       `gorm:"index:idx_notifications_user_created"`
+	User      *User      `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Type      string     `gorm:"size:32"`
+	Message   string     `gorm:"size:512"`
+	ReadAt    *time.Time `gorm:"default:null"`
+	CreatedAt time.Time  `gorm:"autoCreateTime;index:idx_notifications_user_created"`
+}
+
+// Типы событий исходящего outbox
+const (
+	EventTransferCreated = "transfer.created"
+	EventPurchaseCreated = "purchase.created"
+)
+
+// OutboxEvent - доменное событие, записанное в той же транзакции, что и породившая его операция.
+// Payload хранит данные события в JSON.
+type OutboxEvent struct {
+	ID        uint      `gorm:"primaryKey"`
+	Type      string    `gorm:"size:64"`
+	Payload   string    `gorm:"type:text"`
 	CreatedAt time.Time `gorm:"autoCreateTime"`
 }
+
+// Webhook - зарегистрированный получатель событий. Тело каждого запроса подписывается HMAC-SHA256 с ключом Secret.
+type Webhook struct {
+	ID        uint      `gorm:"primaryKey"`
+	URL       string    `gorm:"size:2048"`
+	Secret    string    `gorm:"size:255"`
+	Active    bool      `gorm:"default:true"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// Статусы доставки события вебхуку
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+// WebhookDelivery - доставка события одному вебхуку. Создаётся вместе с событием для каждого активного вебхука.
+// Неудачная доставка повторяется в NextAttemptAt, а после исчерпания попыток переходит в статус dead.
+type WebhookDelivery struct {
+	ID            uint         `gorm:"primaryKey"`
+	WebhookID     uint         `gorm:"index"`
+	Webhook       *Webhook     `gorm:"foreignKey:WebhookID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	EventID       uint         `gorm:"index"`
+	Event         *OutboxEvent `gorm:"foreignKey:EventID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Status        string       `gorm:"size:16;index:idx_webhook_deliveries_status_next"`
+	Attempts      int          `gorm:"default:0"`
+	NextAttemptAt time.Time    `gorm:"index:idx_webhook_deliveries_status_next"`
+	LeaseUntil    *time.Time   `gorm:"default:null"`
+	LastError     string       `gorm:"size:512"`
+	DeliveredAt   *time.Time   `gorm:"default:null"`
+	CreatedAt     time.Time    `gorm:"autoCreateTime"`
+}
@@ -0,0 +1,125 @@
+package events
+
+import (
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// Типы событий
+const (
+	TypeBalance  = "balance"
+	TypeTransfer = "transfer"
+	TypePurchase = "purchase"
+)
+
+// Размер буфера событий одного подписчика. События для медленного подписчика сверх буфера отбрасываются,
+// чтобы публикация никогда не блокировала обработку запроса.
+const subscriberBuffer = 16
+
+// Event - событие для конкретного пользователя. Data сериализуется в JSON при отправке клиенту.
+type Event struct {
+	Type string
+	Data any
+}
+
+// Publisher публикует события пользователям.
+type Publisher interface {
+	Publish(userID uint, event Event)
+	// Subscribed сообщает, есть ли у пользователя подписчики. Позволяет не готовить событие, которое некому отправить.
+	Subscribed(userID uint) bool
+}
+
+// Broker - внутрипроцессный pub/sub событий пользователей.
+// У одного пользователя может быть несколько подписчиков, например несколько открытых вкладок.
+type Broker struct {
+	mu          sync.RWMutex
+	subscribers map[uint]map[chan Event]struct{}
+	closed      bool
+	logger      *zap.Logger
+}
+
+func NewBroker(logger *zap.Logger) *Broker {
+	return &Broker{
+		subscribers: make(map[uint]map[chan Event]struct{}),
+		logger:      logger,
+	}
+}
+
+// Subscribe подписывает на события пользователя userID.
+// Возвращает канал событий и функцию отписки, после вызова которой канал закрывается.
+// Канал также закрывается при остановке брокера.
+func (b *Broker) Subscribe(userID uint) (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		close(ch)
+		return ch, func() {}
+	}
+
+	if b.subscribers[userID] == nil {
+		b.subscribers[userID] = make(map[chan Event]struct{})
+	}
+
+	b.subscribers[userID][ch] = struct{}{}
+
+	return ch, func() { b.unsubscribe(userID, ch) }
+}
+
+func (b *Broker) unsubscribe(userID uint, ch chan Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// Канал мог быть уже закрыт при остановке брокера
+	if _, ok := b.subscribers[userID][ch]; !ok {
+		return
+	}
+
+	delete(b.subscribers[userID], ch)
+
+	if len(b.subscribers[userID]) == 0 {
+		delete(b.subscribers, userID)
+	}
+
+	close(ch)
+}
+
+// Publish отправляет событие всем подписчикам пользователя userID не блокируясь.
+func (b *Broker) Publish(userID uint, event Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for ch := range b.subscribers[userID] {
+		select {
+		case ch <- event:
+		default:
+			b.logger.Warn("event dropped for slow subscriber", zap.Uint("userID", userID), zap.String("type", event.Type))
+		}
+	}
+}
+
+func (b *Broker) Subscribed(userID uint) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return len(b.subscribers[userID]) > 0
+}
+
+// Close закрывает каналы всех подписчиков. Новые подписки после этого сразу получают закрытый канал.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for userID, subscribers := range b.subscribers {
+		for ch := range subscribers {
+			close(ch)
+		}
+
+		delete(b.subscribers, userID)
+	}
+
+	b.closed = true
+}
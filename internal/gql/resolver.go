@@ -0,0 +1,269 @@
+package gql
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/maksemen2/avito-shop/internal/models"
+	"github.com/maksemen2/avito-shop/internal/repository"
+	"github.com/maksemen2/avito-shop/internal/services"
+)
+
+type userContextKey struct{}
+
+type user struct {
+	id       uint
+	username string
+}
+
+// WithUser добавляет в контекст пользователя, от имени которого выполняется запрос.
+func WithUser(ctx context.Context, userID uint, username string) context.Context {
+	return context.WithValue(ctx, userContextKey{}, user{id: userID, username: username})
+}
+
+func userFromContext(ctx context.Context) user {
+	u, _ := ctx.Value(userContextKey{}).(user)
+	return u
+}
+
+// resolverError добавляет к ошибке GraphQL код в extensions, аналогичный коду ответа REST API.
+type resolverError struct {
+	message string
+	code    string
+}
+
+func (e *resolverError) Error() string {
+	return e.message
+}
+
+func (e *resolverError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.code}
+}
+
+func newResolverError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrInternal):
+		return &resolverError{message: models.ErrInternal, code: "INTERNAL"}
+	case errors.Is(err, services.ErrDailyLimitExceeded), errors.Is(err, services.ErrAccountTooNew), errors.Is(err, services.ErrUserLocked):
+		return &resolverError{message: err.Error(), code: "FORBIDDEN"}
+	default:
+		return &resolverError{message: err.Error(), code: "BAD_REQUEST"}
+	}
+}
+
+type resolver struct {
+	services Services
+}
+
+func (r *resolver) Balance(ctx context.Context) (int32, error) {
+	balance, err := r.services.Info.GetBalance(ctx, userFromContext(ctx).id)
+	if err != nil {
+		return 0, newResolverError(err)
+	}
+
+	return int32(balance), nil
+}
+
+func (r *resolver) Inventory(ctx context.Context) ([]*itemResolver, error) {
+	inventory, err := r.services.Info.GetInventory(ctx, userFromContext(ctx).id)
+	if err != nil {
+		return nil, newResolverError(err)
+	}
+
+	items := make([]*itemResolver, 0, len(inventory))
+	for _, item := range inventory {
+		items = append(items, &itemResolver{item: item})
+	}
+
+	return items, nil
+}
+
+func (r *resolver) History(ctx context.Context, args struct {
+	First  int32
+	Offset int32
+}) (*transferPageResolver, error) {
+	page, err := r.services.Info.GetTransfers(ctx, userFromContext(ctx).id, int(args.First), int(args.Offset))
+	if err != nil {
+		return nil, newResolverError(err)
+	}
+
+	return &transferPageResolver{page: page, offset: int(args.Offset)}, nil
+}
+
+func (r *resolver) Goods(ctx context.Context) ([]*goodResolver, error) {
+	catalog, err := r.services.Pricing.Catalog(ctx, time.Now())
+	if err != nil {
+		return nil, newResolverError(err)
+	}
+
+	goods := make([]*goodResolver, 0, len(catalog))
+	for _, good := range catalog {
+		goods = append(goods, &goodResolver{good: good})
+	}
+
+	return goods, nil
+}
+
+func (r *resolver) SendCoin(ctx context.Context, args struct {
+	ToUser string
+	Amount int32
+}) (int32, error) {
+	u := userFromContext(ctx)
+
+	err := r.services.Transfer.SendCoins(ctx, u.id, u.username, models.SendCoinRequest{
+		ToUser: args.ToUser,
+		Amount: int(args.Amount),
+	})
+	if err != nil {
+		return 0, newResolverError(err)
+	}
+
+	// Баланс читается сразу после записи, поэтому с основной базы
+	return r.Balance(repository.WithPrimaryReads(ctx))
+}
+
+func (r *resolver) Buy(ctx context.Context, args struct {
+	Item    string
+	Variant *string
+	Promo   *string
+}) (int32, error) {
+	var opts models.BuyOptions
+
+	if args.Variant != nil {
+		opts.Variant = *args.Variant
+	}
+
+	if args.Promo != nil {
+		opts.Promo = *args.Promo
+	}
+
+	if err := r.services.Purchase.BuyGood(ctx, userFromContext(ctx).id, args.Item, opts); err != nil {
+		return 0, newResolverError(err)
+	}
+
+	return r.Balance(repository.WithPrimaryReads(ctx))
+}
+
+type itemResolver struct {
+	item models.Item
+}
+
+func (r *itemResolver) Type() string {
+	return r.item.Type
+}
+
+func (r *itemResolver) Variant() *string {
+	if r.item.Variant == "" {
+		return nil
+	}
+
+	return &r.item.Variant
+}
+
+func (r *itemResolver) Quantity() int32 {
+	return int32(r.item.Quantity)
+}
+
+type transferResolver struct {
+	transfer models.Transfer
+}
+
+func (r *transferResolver) ID() graphql.ID {
+	return graphql.ID(strconv.FormatUint(uint64(r.transfer.ID), 10))
+}
+
+func (r *transferResolver) Direction() string {
+	return r.transfer.Direction
+}
+
+func (r *transferResolver) Counterparty() string {
+	return r.transfer.Counterparty
+}
+
+func (r *transferResolver) Amount() int32 {
+	return int32(r.transfer.Amount)
+}
+
+func (r *transferResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: r.transfer.CreatedAt}
+}
+
+type transferPageResolver struct {
+	page   models.TransferHistoryPage
+	offset int
+}
+
+func (r *transferPageResolver) Transfers() []*transferResolver {
+	transfers := make([]*transferResolver, 0, len(r.page.Transfers))
+	for _, transfer := range r.page.Transfers {
+		transfers = append(transfers, &transferResolver{transfer: transfer})
+	}
+
+	return transfers
+}
+
+func (r *transferPageResolver) TotalCount() int32 {
+	return int32(r.page.Total)
+}
+
+func (r *transferPageResolver) HasNextPage() bool {
+	return int64(r.offset+len(r.page.Transfers)) < r.page.Total
+}
+
+type goodResolver struct {
+	good models.CatalogItem
+}
+
+func (r *goodResolver) Type() string {
+	return r.good.Type
+}
+
+func (r *goodResolver) Price() int32 {
+	return int32(r.good.Price)
+}
+
+func (r *goodResolver) OriginalPrice() *int32 {
+	if r.good.OriginalPrice == nil {
+		return nil
+	}
+
+	price := int32(*r.good.OriginalPrice)
+
+	return &price
+}
+
+func (r *goodResolver) SaleEndsAt() *graphql.Time {
+	if r.good.SaleEndsAt == nil {
+		return nil
+	}
+
+	return &graphql.Time{Time: *r.good.SaleEndsAt}
+}
+
+func (r *goodResolver) Variants() []*variantResolver {
+	variants := make([]*variantResolver, 0, len(r.good.Variants))
+	for _, variant := range r.good.Variants {
+		variants = append(variants, &variantResolver{variant: variant})
+	}
+
+	return variants
+}
+
+type variantResolver struct {
+	variant models.CatalogVariant
+}
+
+func (r *variantResolver) Name() string {
+	return r.variant.Name
+}
+
+func (r *variantResolver) Price() int32 {
+	return int32(r.variant.Price)
+}
+
+func (r *variantResolver) Stock() int32 {
+	return int32(r.variant.Stock)
+}
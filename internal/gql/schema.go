@@ -0,0 +1,83 @@
+// Package gql реализует GraphQL API магазина поверх тех же сервисов, что и REST API.
+// Клиент запрашивает только нужные поля, например баланс без истории операций.
+package gql
+
+import (
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/maksemen2/avito-shop/internal/services"
+)
+
+const schema = `
+schema {
+	query: Query
+	mutation: Mutation
+}
+
+scalar Time
+
+type Query {
+	balance: Int!
+	inventory: [Item!]!
+	# История переводов монет от новых к старым, first - размер страницы (не больше 100)
+	history(first: Int = 20, offset: Int = 0): TransferPage!
+	# Каталог с действующими ценами
+	goods: [Good!]!
+}
+
+type Mutation {
+	# Переводит монеты и возвращает новый баланс отправителя
+	sendCoin(toUser: String!, amount: Int!): Int!
+	# Покупает товар и возвращает новый баланс покупателя
+	buy(item: String!, variant: String, promo: String): Int!
+}
+
+type Item {
+	type: String!
+	variant: String
+	quantity: Int!
+}
+
+type Transfer {
+	id: ID!
+	# received или sent
+	direction: String!
+	# Отправитель входящего перевода или получатель исходящего
+	counterparty: String!
+	amount: Int!
+	createdAt: Time!
+}
+
+type TransferPage {
+	transfers: [Transfer!]!
+	totalCount: Int!
+	hasNextPage: Boolean!
+}
+
+type Good {
+	type: String!
+	price: Int!
+	originalPrice: Int
+	saleEndsAt: Time
+	variants: [Variant!]!
+}
+
+type Variant {
+	name: String!
+	price: Int!
+	stock: Int!
+}
+`
+
+// Services - сервисы, к которым обращаются резолверы.
+type Services struct {
+	Info     services.InfoService
+	Pricing  services.PricingService
+	Transfer services.TransferService
+	Purchase services.PurchaseService
+}
+
+// NewSchema разбирает схему и связывает её с резолверами.
+// Схема задана константой, поэтому ошибка разбора возможна только при её неверной правке.
+func NewSchema(svc Services) *graphql.Schema {
+	return graphql.MustParseSchema(schema, &resolver{services: svc})
+}
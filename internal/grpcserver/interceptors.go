@@ -0,0 +1,88 @@
+package grpcserver
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/maksemen2/avito-shop/internal/models"
+	"github.com/maksemen2/avito-shop/internal/services"
+	"github.com/maksemen2/avito-shop/pkg/auth"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type userContextKey struct{}
+
+type user struct {
+	id       uint
+	username string
+}
+
+// UserFromContext возвращает пользователя, токен которого проверил authInterceptor.
+func UserFromContext(ctx context.Context) (uint, string, bool) {
+	u, ok := ctx.Value(userContextKey{}).(user)
+	return u.id, u.username, ok
+}
+
+// authInterceptor проверяет токен из метаданных authorization и блокировку пользователя для всех методов, кроме публичных.
+func authInterceptor(
+	jwtManager *auth.JWTManager,
+	authService services.AuthService,
+	logger *zap.Logger,
+	publicMethods ...string,
+) grpc.UnaryServerInterceptor {
+	public := make(map[string]struct{}, len(publicMethods))
+	for _, method := range publicMethods {
+		public[method] = struct{}{}
+	}
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if _, ok := public[info.FullMethod]; ok {
+			return handler(ctx, req)
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+
+		values := md.Get("authorization")
+		if len(values) == 0 || values[0] == "" {
+			return nil, status.Error(codes.Unauthenticated, models.ErrUnauthorized)
+		}
+
+		userID, username, err := jwtManager.ValidateToken(strings.TrimPrefix(values[0], "Bearer "))
+		if err != nil {
+			logger.Debug("Invalid gRPC token", zap.String("method", info.FullMethod), zap.Error(err))
+			return nil, status.Error(codes.Unauthenticated, models.ErrUnauthorized)
+		}
+
+		locked, err := authService.IsLocked(ctx, userID)
+		if err != nil {
+			return nil, statusFromError(err)
+		}
+
+		if locked {
+			return nil, statusFromError(services.ErrUserLocked)
+		}
+
+		return handler(context.WithValue(ctx, userContextKey{}, user{id: userID, username: username}), req)
+	}
+}
+
+func loggingInterceptor(logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+
+		resp, err := handler(ctx, req)
+
+		logger.Info("gRPC request",
+			zap.String("method", info.FullMethod),
+			zap.String("code", status.Code(err).String()),
+			zap.Duration("duration", time.Since(start)),
+		)
+
+		return resp, err
+	}
+}
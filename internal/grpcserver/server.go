@@ -0,0 +1,102 @@
+// Package grpcserver реализует gRPC API магазина поверх тех же сервисов, что и REST API.
+package grpcserver
+
+import (
+	"context"
+	"errors"
+
+	"github.com/maksemen2/avito-shop/internal/models"
+	"github.com/maksemen2/avito-shop/internal/services"
+	"github.com/maksemen2/avito-shop/pkg/auth"
+	"github.com/maksemen2/avito-shop/pkg/shopgrpc"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Services - сервисы, к которым обращаются методы gRPC API.
+type Services struct {
+	Auth     services.AuthService
+	Info     services.InfoService
+	Transfer services.TransferService
+	Purchase services.PurchaseService
+}
+
+type shopServer struct {
+	services Services
+}
+
+// NewServer создает gRPC-сервер с зарегистрированным сервисом магазина.
+// Все методы, кроме Auth, требуют JWT-токен в метаданных authorization и отклоняются для заблокированных пользователей.
+func NewServer(svc Services, jwtManager *auth.JWTManager, logger *zap.Logger) *grpc.Server {
+	server := grpc.NewServer(
+		shopgrpc.ServerOption(),
+		grpc.ChainUnaryInterceptor(
+			loggingInterceptor(logger),
+			authInterceptor(jwtManager, svc.Auth, logger, shopgrpc.AuthMethod),
+		),
+	)
+
+	shopgrpc.RegisterShopServer(server, &shopServer{services: svc})
+
+	return server
+}
+
+func (s *shopServer) Auth(ctx context.Context, req *shopgrpc.AuthRequest) (*shopgrpc.AuthResponse, error) {
+	resp, err := s.services.Auth.Authenticate(ctx, *req)
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+
+	return &resp, nil
+}
+
+func (s *shopServer) Info(ctx context.Context, _ *shopgrpc.InfoRequest) (*shopgrpc.InfoResponse, error) {
+	userID, _, _ := UserFromContext(ctx)
+
+	resp, err := s.services.Info.GetInfo(ctx, userID)
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+
+	return &resp, nil
+}
+
+func (s *shopServer) SendCoins(ctx context.Context, req *shopgrpc.SendCoinsRequest) (*shopgrpc.SendCoinsResponse, error) {
+	userID, username, _ := UserFromContext(ctx)
+
+	if err := s.services.Transfer.SendCoins(ctx, userID, username, *req); err != nil {
+		return nil, statusFromError(err)
+	}
+
+	return &shopgrpc.SendCoinsResponse{}, nil
+}
+
+func (s *shopServer) BuyGood(ctx context.Context, req *shopgrpc.BuyGoodRequest) (*shopgrpc.BuyGoodResponse, error) {
+	userID, _, _ := UserFromContext(ctx)
+
+	err := s.services.Purchase.BuyGood(ctx, userID, req.Item, models.BuyOptions{
+		Variant: req.Variant,
+		Promo:   req.Promo,
+	})
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+
+	return &shopgrpc.BuyGoodResponse{}, nil
+}
+
+// statusFromError сопоставляет ошибки сервисов кодам gRPC так же, как обработчики REST API - кодам HTTP.
+func statusFromError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrInternal):
+		return status.Error(codes.Internal, models.ErrInternal)
+	case errors.Is(err, services.ErrAuthFailed):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, services.ErrDailyLimitExceeded), errors.Is(err, services.ErrAccountTooNew), errors.Is(err, services.ErrUserLocked):
+		return status.Error(codes.PermissionDenied, err.Error())
+	default:
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+}
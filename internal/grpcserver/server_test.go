@@ -0,0 +1,152 @@
+package grpcserver_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/maksemen2/avito-shop/config"
+	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/internal/grpcserver"
+	"github.com/maksemen2/avito-shop/internal/repository"
+	"github.com/maksemen2/avito-shop/internal/services"
+	"github.com/maksemen2/avito-shop/pkg/auth"
+	"github.com/maksemen2/avito-shop/pkg/shopgrpc"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+func getTestClient(t *testing.T) shopgrpc.ShopClient {
+	client, _ := getTestClientWithDB(t)
+
+	return client
+}
+
+func getTestClientWithDB(t *testing.T) (shopgrpc.ShopClient, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormLogger.Default.LogMode(gormLogger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to open in-memory database: %v", err)
+	}
+
+	db.AutoMigrate(&database.User{}, &database.Purchase{}, &database.Transaction{}, &database.Good{}, &database.CoinRequest{}, &database.GrantProgram{}, &database.Grant{}, &database.CoinLot{}, &database.CoinExpiration{}, &database.ItemTransfer{}, &database.Listing{}, &database.PromoCode{}, &database.PromoRedemption{}, &database.PriceSchedule{}, &database.GoodVariant{}, &database.WishlistItem{}, &database.Notification{}, &database.OutboxEvent{}, &database.Webhook{}, &database.WebhookDelivery{})
+	if err := db.Create(&database.Good{Type: "cup", Price: 20}).Error; err != nil {
+		t.Fatalf("failed to create good: %v", err)
+	}
+
+	logger := zap.NewNop()
+	repo := repository.NewHolderRepository(db, logger)
+	jwtManager := auth.NewJWTManager(config.AuthConfig{JwtKey: "very_secret_key", TokenLifetimeHours: 1})
+
+	server := grpcserver.NewServer(grpcserver.Services{
+		Auth:     services.NewAuthService(repo, jwtManager, logger),
+		Info:     services.NewInfoService(repo, logger),
+		Transfer: services.NewTransferService(repo, config.TransferConfig{}, logger),
+		Purchase: services.NewPurchaseService(repo, logger),
+	}, jwtManager, logger)
+
+	listener := bufconn.Listen(1 << 20)
+
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to dial grpc server: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return shopgrpc.NewShopClient(conn), db
+}
+
+func TestGRPC_Flow(t *testing.T) {
+	client := getTestClient(t)
+	ctx := context.Background()
+
+	sender, err := client.Auth(ctx, &shopgrpc.AuthRequest{Username: "sender", Password: "password"})
+	if err != nil {
+		t.Fatalf("failed to auth sender: %v", err)
+	}
+
+	_, err = client.Auth(ctx, &shopgrpc.AuthRequest{Username: "receiver", Password: "password"})
+	if err != nil {
+		t.Fatalf("failed to auth receiver: %v", err)
+	}
+
+	senderCtx := shopgrpc.WithToken(ctx, sender.Token)
+
+	_, err = client.SendCoins(senderCtx, &shopgrpc.SendCoinsRequest{ToUser: "receiver", Amount: 100})
+	assert.NoError(t, err, "failed to send coins")
+
+	_, err = client.BuyGood(senderCtx, &shopgrpc.BuyGoodRequest{Item: "cup"})
+	assert.NoError(t, err, "failed to buy good")
+
+	info, err := client.Info(senderCtx, &shopgrpc.InfoRequest{})
+	if err != nil {
+		t.Fatalf("failed to get info: %v", err)
+	}
+	assert.Equal(t, 880, info.Coins, "unexpected coins count")
+	assert.Equal(t, []shopgrpc.Item{{Type: "cup", Quantity: 1}}, info.Inventory, "unexpected inventory")
+	assert.Len(t, info.CoinHistory.Sent, 1, "unexpected sent history")
+}
+
+func TestGRPC_Errors(t *testing.T) {
+	client := getTestClient(t)
+	ctx := context.Background()
+
+	_, err := client.Info(ctx, &shopgrpc.InfoRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "info without token should be rejected")
+
+	_, err = client.Info(shopgrpc.WithToken(ctx, "garbage"), &shopgrpc.InfoRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "info with invalid token should be rejected")
+
+	resp, err := client.Auth(ctx, &shopgrpc.AuthRequest{Username: "user", Password: "password"})
+	if err != nil {
+		t.Fatalf("failed to auth: %v", err)
+	}
+
+	_, err = client.Auth(ctx, &shopgrpc.AuthRequest{Username: "user", Password: "wrong"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "wrong password should be rejected")
+
+	_, err = client.SendCoins(shopgrpc.WithToken(ctx, resp.Token), &shopgrpc.SendCoinsRequest{ToUser: "user", Amount: 10})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "unexpected self transfer code")
+	assert.Equal(t, services.ErrCantSelfTransfer.Error(), status.Convert(err).Message(), "unexpected self transfer message")
+}
+
+func TestGRPC_LockedUserToken(t *testing.T) {
+	client, db := getTestClientWithDB(t)
+	ctx := context.Background()
+
+	resp, err := client.Auth(ctx, &shopgrpc.AuthRequest{Username: "user", Password: "password"})
+	if err != nil {
+		t.Fatalf("failed to auth: %v", err)
+	}
+
+	userCtx := shopgrpc.WithToken(ctx, resp.Token)
+
+	if err := db.Model(&database.User{}).Where("username = ?", "user").Update("locked", true).Error; err != nil {
+		t.Fatalf("failed to lock user: %v", err)
+	}
+
+	_, err = client.Info(userCtx, &shopgrpc.InfoRequest{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "token issued before the lock should be rejected")
+
+	if err := db.Model(&database.User{}).Where("username = ?", "user").Update("locked", false).Error; err != nil {
+		t.Fatalf("failed to unlock user: %v", err)
+	}
+
+	_, err = client.Info(userCtx, &shopgrpc.InfoRequest{})
+	assert.NoError(t, err, "token should be accepted after unlock")
+}
@@ -16,7 +16,7 @@ func (h *RequestsHandler) Authenticate(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.authService.Authenticate(c.Request.Context(), req)
+	resp, err := h.AuthService.Authenticate(c.Request.Context(), req)
 	if err != nil {
 		errDetail := err.Error()
 
@@ -25,6 +25,8 @@ func (h *RequestsHandler) Authenticate(c *gin.Context) {
 			c.AbortWithStatusJSON(http.StatusBadRequest, models.NewDetailedErrorResponse(models.ErrBadRequest, errDetail))
 		case errors.Is(err, services.ErrAuthFailed):
 			c.AbortWithStatusJSON(http.StatusUnauthorized, models.NewDetailedErrorResponse(models.ErrUnauthorized, errDetail))
+		case errors.Is(err, services.ErrUserLocked):
+			c.AbortWithStatusJSON(http.StatusForbidden, models.NewDetailedErrorResponse(models.ErrForbidden, errDetail))
 		case errors.Is(err, services.ErrInternal):
 			c.AbortWithStatusJSON(http.StatusInternalServerError, models.NewErrorResponse(models.ErrInternal))
 		}
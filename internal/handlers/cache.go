@@ -0,0 +1,11 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *RequestsHandler) GetCacheStats(c *gin.Context) {
+	c.JSON(http.StatusOK, h.AdminService.CacheStats())
+}
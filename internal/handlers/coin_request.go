@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maksemen2/avito-shop/internal/middleware"
+	"github.com/maksemen2/avito-shop/internal/models"
+	"github.com/maksemen2/avito-shop/internal/services"
+)
+
+func (h *RequestsHandler) CreateCoinRequest(c *gin.Context) {
+	var req models.CreateCoinRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.NewErrorResponse(models.ErrBadRequest))
+		return
+	}
+
+	userID, _ := middleware.GetUserID(c)
+	username, _ := middleware.GetUsername(c)
+
+	resp, err := h.coinRequestService.CreateRequest(c.Request.Context(), userID, username, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInternal):
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.NewErrorResponse(models.ErrInternal))
+		default:
+			c.AbortWithStatusJSON(http.StatusBadRequest, models.NewDetailedErrorResponse(models.ErrBadRequest, err.Error()))
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *RequestsHandler) GetCoinRequests(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	resp, err := h.coinRequestService.ListRequests(c.Request.Context(), userID, c.Query("status"))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInternal):
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.NewErrorResponse(models.ErrInternal))
+		default:
+			c.AbortWithStatusJSON(http.StatusBadRequest, models.NewDetailedErrorResponse(models.ErrBadRequest, err.Error()))
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *RequestsHandler) AcceptCoinRequest(c *gin.Context) {
+	h.resolveCoinRequest(c, h.coinRequestService.AcceptRequest)
+}
+
+func (h *RequestsHandler) DeclineCoinRequest(c *gin.Context) {
+	h.resolveCoinRequest(c, h.coinRequestService.DeclineRequest)
+}
+
+// resolveCoinRequest разбирает идентификатор запроса из пути и применяет к нему resolve от имени текущего пользователя.
+func (h *RequestsHandler) resolveCoinRequest(c *gin.Context, resolve func(ctx context.Context, payerID, requestID uint) error) {
+	requestID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.NewDetailedErrorResponse(models.ErrBadRequest, "invalid request id"))
+		return
+	}
+
+	userID, _ := middleware.GetUserID(c)
+
+	if err := resolve(c.Request.Context(), userID, uint(requestID)); err != nil {
+		switch {
+		case errors.Is(err, services.ErrInternal):
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.NewErrorResponse(models.ErrInternal))
+		case errors.Is(err, services.ErrCoinRequestNotFound):
+			c.AbortWithStatusJSON(http.StatusNotFound, models.NewDetailedErrorResponse(models.ErrNotFound, err.Error()))
+		case errors.Is(err, services.ErrDailyLimitExceeded), errors.Is(err, services.ErrAccountTooNew), errors.Is(err, services.ErrUserLocked):
+			c.AbortWithStatusJSON(http.StatusForbidden, models.NewDetailedErrorResponse(models.ErrForbidden, err.Error()))
+		default:
+			c.AbortWithStatusJSON(http.StatusBadRequest, models.NewDetailedErrorResponse(models.ErrBadRequest, err.Error()))
+		}
+
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
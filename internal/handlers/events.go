@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"io"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maksemen2/avito-shop/internal/middleware"
+)
+
+// Интервал комментариев-пингов в потоке событий. Не даёт прокси закрыть соединение без трафика.
+const eventsHeartbeatInterval = 30 * time.Second
+
+// StreamEvents отправляет события пользователя в формате Server-Sent Events, пока клиент не отключится.
+func (h *RequestsHandler) StreamEvents(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	stream, unsubscribe := h.broker.Subscribe(userID)
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	heartbeat := time.NewTicker(eventsHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	// Комментарий сразу отправляет заголовки, и клиент знает, что подписка установлена
+	_, _ = io.WriteString(c.Writer, ": connected\n\n")
+	c.Writer.Flush()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-stream:
+			if !ok {
+				return false
+			}
+
+			c.SSEvent(event.Type, event.Data)
+
+			return true
+		case <-heartbeat.C:
+			_, err := io.WriteString(w, ": ping\n\n")
+			return err == nil
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maksemen2/avito-shop/internal/models"
+	"github.com/maksemen2/avito-shop/internal/services"
+)
+
+func (h *RequestsHandler) GetGrantPrograms(c *gin.Context) {
+	resp, err := h.grantService.ListPrograms(c.Request.Context())
+	if err != nil {
+		// может быть только services.ErrInternal
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.NewErrorResponse(models.ErrInternal))
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *RequestsHandler) CreateGrantProgram(c *gin.Context) {
+	var req models.CreateGrantProgramRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.NewErrorResponse(models.ErrBadRequest))
+		return
+	}
+
+	resp, err := h.grantService.CreateProgram(c.Request.Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInternal):
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.NewErrorResponse(models.ErrInternal))
+		default:
+			c.AbortWithStatusJSON(http.StatusBadRequest, models.NewDetailedErrorResponse(models.ErrBadRequest, err.Error()))
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *RequestsHandler) ActivateGrantProgram(c *gin.Context) {
+	h.setGrantProgramActive(c, true)
+}
+
+func (h *RequestsHandler) DeactivateGrantProgram(c *gin.Context) {
+	h.setGrantProgramActive(c, false)
+}
+
+func (h *RequestsHandler) ApplyGrants(c *gin.Context) {
+	granted, err := h.grantService.ApplyGrants(c.Request.Context(), time.Now())
+	if err != nil {
+		// может быть только services.ErrInternal
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.NewErrorResponse(models.ErrInternal))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ApplyGrantsResponse{Granted: granted})
+}
+
+func (h *RequestsHandler) setGrantProgramActive(c *gin.Context, active bool) {
+	programID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.NewDetailedErrorResponse(models.ErrBadRequest, "invalid program id"))
+		return
+	}
+
+	if err := h.grantService.SetProgramActive(c.Request.Context(), uint(programID), active); err != nil {
+		switch {
+		case errors.Is(err, services.ErrGrantProgramNotFound):
+			c.AbortWithStatusJSON(http.StatusNotFound, models.NewDetailedErrorResponse(models.ErrNotFound, err.Error()))
+		default:
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.NewErrorResponse(models.ErrInternal))
+		}
+
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
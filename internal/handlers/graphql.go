@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maksemen2/avito-shop/internal/gql"
+	"github.com/maksemen2/avito-shop/internal/middleware"
+	"github.com/maksemen2/avito-shop/internal/models"
+)
+
+// GraphQL выполняет GraphQL-запрос от имени текущего пользователя.
+// Ошибки выполнения запроса возвращаются в поле errors ответа с кодом 200, как принято в GraphQL.
+func (h *RequestsHandler) GraphQL(c *gin.Context) {
+	var req models.GraphQLRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Query == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.NewErrorResponse(models.ErrBadRequest))
+		return
+	}
+
+	userID, _ := middleware.GetUserID(c)
+	username, _ := middleware.GetUsername(c)
+	ctx := gql.WithUser(c.Request.Context(), userID, username)
+
+	c.JSON(http.StatusOK, h.graphQLSchema.Exec(ctx, req.Query, req.OperationName, req.Variables))
+}
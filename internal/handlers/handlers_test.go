@@ -1,19 +1,28 @@
 package handlers_test
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
 	"github.com/maksemen2/avito-shop/config"
 	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/internal/events"
 	"github.com/maksemen2/avito-shop/internal/handlers"
 	"github.com/maksemen2/avito-shop/internal/models"
+	"github.com/maksemen2/avito-shop/internal/repository"
 	"github.com/maksemen2/avito-shop/internal/routes"
+	"github.com/maksemen2/avito-shop/internal/services"
 	"github.com/maksemen2/avito-shop/pkg/auth"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
@@ -23,6 +32,12 @@ import (
 )
 
 func setupTest(t *testing.T) *gin.Engine {
+	router, _ := setupTestWithDB(t)
+
+	return router
+}
+
+func setupTestWithDB(t *testing.T) (*gin.Engine, *gorm.DB) {
 	gin.SetMode(gin.TestMode)
 
 	mockAuthConfig := config.AuthConfig{
@@ -37,7 +52,7 @@ func setupTest(t *testing.T) *gin.Engine {
 		t.Fatalf("failed to open database: %v", err)
 	}
 
-	if err = db.AutoMigrate(&database.User{}, &database.Purchase{}, &database.Transaction{}, &database.Good{}); err != nil {
+	if err = db.AutoMigrate(&database.User{}, &database.Purchase{}, &database.Transaction{}, &database.Good{}, &database.CoinRequest{}, &database.GrantProgram{}, &database.Grant{}, &database.CoinLot{}, &database.CoinExpiration{}, &database.ItemTransfer{}, &database.Listing{}, &database.PromoCode{}, &database.PromoRedemption{}, &database.PriceSchedule{}, &database.GoodVariant{}, &database.WishlistItem{}, &database.Notification{}, &database.OutboxEvent{}, &database.Webhook{}, &database.WebhookDelivery{}); err != nil {
 		t.Fatalf("failed to migrate database: %v", err)
 	}
 
@@ -60,10 +75,13 @@ func setupTest(t *testing.T) *gin.Engine {
 
 	logger := zap.NewNop()
 
-	reqHandler := handlers.NewRequestsHandler(db, jwtManager, logger)
+	broker := events.NewBroker(logger)
+	holderRepo := repository.NewHolderRepository(db, logger, repository.WithUserChangeHook(services.NewEventsHook(broker, logger)))
+
+	reqHandler := handlers.NewRequestsHandler(holderRepo, nil, jwtManager, broker, &config.Config{}, logger)
 	router := routes.SetupRoutes(reqHandler, logger, config.CorsConfig{AllowedOrigins: "*", AllowedMethods: "*", AllowedHeaders: "*", AllowCredientals: "true", MaxAge: "86300"})
 
-	return router
+	return router, db
 }
 
 func registerUser(t *testing.T, router *gin.Engine, username string) string {
@@ -149,6 +167,71 @@ func TestE2EBuyMerch(t *testing.T) {
 	assert.Equal(t, 920, info.Coins, "expected coin balance to be 920 after purchase")
 }
 
+func TestE2ELockedUserToken(t *testing.T) {
+	router, db := setupTestWithDB(t)
+	token := registerUser(t, router, "testUser")
+
+	infoCode := func() int {
+		req := httptest.NewRequest(http.MethodGet, "/api/info", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		return recorder.Code
+	}
+
+	assert.NoError(t, db.Model(&database.User{}).Where("username = ?", "testUser").Update("locked", true).Error, "failed to lock user")
+	assert.Equal(t, http.StatusForbidden, infoCode(), "expected token issued before the lock to be rejected")
+
+	code, _ := buyItem(router, "cup", token)
+	assert.Equal(t, http.StatusForbidden, code, "expected locked user to be unable to buy")
+
+	assert.NoError(t, db.Model(&database.User{}).Where("username = ?", "testUser").Update("locked", false).Error, "failed to unlock user")
+	assert.Equal(t, http.StatusOK, infoCode(), "expected token to be accepted after unlock")
+}
+
+func TestE2EWebSocketLockedAfterConnect(t *testing.T) {
+	router, db := setupTestWithDB(t)
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	token := registerUser(t, router, "sender")
+	registerUser(t, router, "receiver")
+
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+token)
+
+	conn, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/api/ws", header)
+	if !assert.NoError(t, err, "failed to connect to websocket") {
+		return
+	}
+
+	defer resp.Body.Close()
+	defer conn.Close()
+
+	assert.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	assert.NoError(t, db.Model(&database.User{}).Where("username = ?", "sender").Update("locked", true).Error, "failed to lock user")
+
+	for id, command := range map[string]models.WSCommand{
+		"1": {ID: "1", Type: models.WSCommandSendCoin, Payload: json.RawMessage(`{"toUser": "receiver", "amount": 100}`)},
+		"2": {ID: "2", Type: models.WSCommandBuy, Payload: json.RawMessage(`{"item": "cup"}`)},
+	} {
+		assert.NoError(t, conn.WriteJSON(command))
+
+		var message json.RawMessage
+
+		assert.NoError(t, conn.ReadJSON(&message), "failed to read websocket message")
+		assert.JSONEq(t, `{"id": "`+id+`", "type": "error", "error": "forbidden: user is locked"}`, string(message))
+	}
+
+	var sender database.User
+
+	assert.NoError(t, db.Where("username = ?", "sender").First(&sender).Error, "failed to fetch sender")
+	assert.Equal(t, 1000, sender.Coins, "locked user's coins must not change")
+}
+
 func TestE2EBuyMerchWithNoCoins(t *testing.T) {
 	router := setupTest(t)
 	token := registerUser(t, router, "testUser")
@@ -217,3 +300,637 @@ func TestTransferCoins(t *testing.T) {
 	assert.Len(t, info.CoinHistory.Received, 1, "expected one received entry in bank coin history")
 	assert.Equal(t, "receiverOne", info.CoinHistory.Received[0].FromUser, "expected sender of returned coins to be receiverOne")
 }
+
+func requestCoins(router *gin.Engine, payerUsername string, amount int, token string) (int, models.CoinRequest) {
+	payload := fmt.Sprintf(`{"fromUser": "%s", "amount": %d}`, payerUsername, amount)
+	req := httptest.NewRequest(http.MethodPost, "/api/coinRequests", bytes.NewBufferString(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	var resp models.CoinRequest
+
+	if recorder.Code == http.StatusOK {
+		_ = json.NewDecoder(recorder.Body).Decode(&resp)
+	}
+
+	return recorder.Code, resp
+}
+
+func resolveCoinRequest(router *gin.Engine, requestID uint, action string, token string) int {
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/coinRequests/%d/%s", requestID, action), nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	return recorder.Code
+}
+
+func TestE2ECoinRequests(t *testing.T) {
+	router := setupTest(t)
+
+	requesterToken := registerUser(t, router, "requester")
+	payerToken := registerUser(t, router, "payer")
+
+	// Запрашиваем монеты и принимаем запрос
+	code, request := requestCoins(router, "payer", 150, requesterToken)
+	assert.Equal(t, http.StatusOK, code, "expected OK response when creating coin request")
+	assert.Equal(t, "pending", request.Status, "expected new request to be pending")
+
+	// Запросивший не может принять собственный запрос
+	code = resolveCoinRequest(router, request.ID, "accept", requesterToken)
+	assert.Equal(t, http.StatusNotFound, code, "expected NotFound when requester accepts own request")
+
+	code = resolveCoinRequest(router, request.ID, "accept", payerToken)
+	assert.Equal(t, http.StatusOK, code, "expected OK response when accepting coin request")
+
+	// Повторно обработать запрос нельзя
+	code = resolveCoinRequest(router, request.ID, "decline", payerToken)
+	assert.Equal(t, http.StatusBadRequest, code, "expected BadRequest when resolving request twice")
+
+	assert.Equal(t, 1150, getInfo(t, router, requesterToken).Coins, "expected requester to receive coins")
+	assert.Equal(t, 850, getInfo(t, router, payerToken).Coins, "expected payer to lose coins")
+
+	// Отклонённый запрос не двигает монеты
+	_, request = requestCoins(router, "payer", 100, requesterToken)
+	code = resolveCoinRequest(router, request.ID, "decline", payerToken)
+	assert.Equal(t, http.StatusOK, code, "expected OK response when declining coin request")
+	assert.Equal(t, 850, getInfo(t, router, payerToken).Coins, "expected payer balance to stay the same")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/coinRequests", nil)
+	req.Header.Set("Authorization", "Bearer "+payerToken)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	var list models.CoinRequestsResponse
+
+	assert.NoError(t, json.NewDecoder(recorder.Body).Decode(&list), "failed decoding coin requests response")
+	assert.Len(t, list.Incoming, 2, "expected two incoming requests for payer")
+	assert.Len(t, list.Outgoing, 0, "expected no outgoing requests for payer")
+}
+
+func TestE2EGrants(t *testing.T) {
+	router, db := setupTestWithDB(t)
+
+	adminToken := registerUser(t, router, "admin")
+	userToken := registerUser(t, router, "user")
+
+	assert.NoError(t, db.Model(&database.User{}).Where("username = ?", "admin").Update("is_admin", true).Error, "failed to promote admin")
+
+	// Обычный пользователь не имеет доступа к админке
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/grants", bytes.NewBufferString(`{"name": "monthly", "amount": 200, "period": "monthly"}`))
+	req.Header.Set("Authorization", "Bearer "+userToken)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusForbidden, recorder.Code, "expected Forbidden for non-admin user")
+
+	req = httptest.NewRequest(http.MethodPost, "/api/admin/grants", bytes.NewBufferString(`{"name": "monthly", "amount": 200, "period": "monthly"}`))
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusOK, recorder.Code, "expected OK when admin creates grant program")
+
+	// Повторное применение за тот же период не начисляет монеты повторно
+	for i := 0; i < 2; i++ {
+		req = httptest.NewRequest(http.MethodPost, "/api/admin/grants/apply", nil)
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+
+		recorder = httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		assert.Equal(t, http.StatusOK, recorder.Code, "expected OK when applying grants")
+	}
+
+	info := getInfo(t, router, userToken)
+	assert.Equal(t, 1200, info.Coins, "expected user to receive grant once")
+	assert.Len(t, info.CoinHistory.Granted, 1, "expected one grant entry in coin history")
+	assert.Equal(t, "monthly", info.CoinHistory.Granted[0].Program, "unexpected grant program in history")
+
+	var notifications []database.Notification
+
+	assert.NoError(t, db.Where("type = ?", database.NotificationGrant).Find(&notifications).Error, "failed to get notifications")
+	assert.Len(t, notifications, 2, "expected each user to be notified about the grant once")
+}
+
+func TestE2EGiftMerch(t *testing.T) {
+	router := setupTest(t)
+
+	buyerToken := registerUser(t, router, "buyer")
+	recipientToken := registerUser(t, router, "recipient")
+
+	req := httptest.NewRequest(http.MethodPost, "/api/gift", bytes.NewBufferString(`{"toUser": "recipient", "item": "cup"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+buyerToken)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusOK, recorder.Code, "expected OK response for gift")
+
+	// Покупатель платит, но подарок не попадает в его инвентарь
+	buyerInfo := getInfo(t, router, buyerToken)
+	assert.Equal(t, 980, buyerInfo.Coins, "expected buyer to pay for the gift")
+	assert.Len(t, buyerInfo.Inventory, 0, "expected gift not to be in buyer's inventory")
+	assert.Len(t, buyerInfo.GiftHistory.Sent, 1, "expected one sent gift")
+	assert.Equal(t, "recipient", buyerInfo.GiftHistory.Sent[0].ToUser, "unexpected gift recipient")
+
+	recipientInfo := getInfo(t, router, recipientToken)
+	assert.Equal(t, 1000, recipientInfo.Coins, "expected recipient balance to stay the same")
+	assert.Len(t, recipientInfo.Inventory, 1, "expected gift in recipient's inventory")
+	assert.Equal(t, "cup", recipientInfo.Inventory[0].Type, "unexpected gifted item")
+	assert.Len(t, recipientInfo.GiftHistory.Received, 1, "expected one received gift")
+	assert.Equal(t, "buyer", recipientInfo.GiftHistory.Received[0].FromUser, "unexpected gift sender")
+}
+
+func TestE2ESendItems(t *testing.T) {
+	router := setupTest(t)
+
+	senderToken := registerUser(t, router, "sender")
+	receiverToken := registerUser(t, router, "receiver")
+
+	for range 3 {
+		code, _ := buyItem(router, "pen", senderToken)
+		assert.Equal(t, http.StatusOK, code, "expected OK response for buy")
+	}
+
+	sendItems := func(payload string) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/sendItem", bytes.NewBufferString(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+senderToken)
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		return recorder.Code
+	}
+
+	assert.Equal(t, http.StatusOK, sendItems(`{"toUser": "receiver", "item": "pen", "quantity": 2}`), "expected OK response for sendItem")
+	assert.Equal(t, http.StatusBadRequest, sendItems(`{"toUser": "receiver", "item": "pen", "quantity": 2}`), "expected error when items run out")
+	assert.Equal(t, http.StatusBadRequest, sendItems(`{"toUser": "receiver", "item": "pen", "quantity": 0}`), "expected error for zero quantity")
+
+	senderInfo := getInfo(t, router, senderToken)
+	assert.Equal(t, []models.Item{{Type: "pen", Quantity: 1}}, senderInfo.Inventory, "unexpected sender inventory")
+	assert.Equal(t, []models.SentItems{{ToUser: "receiver", Item: "pen", Quantity: 2}}, senderInfo.ItemHistory.Sent, "unexpected sent items history")
+
+	receiverInfo := getInfo(t, router, receiverToken)
+	assert.Equal(t, []models.Item{{Type: "pen", Quantity: 2}}, receiverInfo.Inventory, "unexpected receiver inventory")
+	assert.Equal(t, []models.ReceivedItems{{FromUser: "sender", Item: "pen", Quantity: 2}}, receiverInfo.ItemHistory.Received, "unexpected received items history")
+}
+
+func TestE2EMarketplace(t *testing.T) {
+	router := setupTest(t)
+
+	sellerToken := registerUser(t, router, "seller")
+	buyerToken := registerUser(t, router, "buyer")
+
+	code, _ := buyItem(router, "cup", sellerToken)
+	assert.Equal(t, http.StatusOK, code, "expected OK response for buy")
+
+	marketRequest := func(method, path, payload, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		return recorder
+	}
+
+	recorder := marketRequest(http.MethodPost, "/api/market", `{"item": "cup", "price": 100}`, sellerToken)
+	assert.Equal(t, http.StatusOK, recorder.Code, "expected OK response for listing creation")
+
+	var listing models.Listing
+
+	assert.NoError(t, json.NewDecoder(recorder.Body).Decode(&listing), "failed to decode listing")
+
+	recorder = marketRequest(http.MethodGet, "/api/market?item=cup", "", buyerToken)
+	assert.Equal(t, http.StatusOK, recorder.Code, "expected OK response for listings")
+
+	var listings []models.Listing
+
+	assert.NoError(t, json.NewDecoder(recorder.Body).Decode(&listings), "failed to decode listings")
+	assert.Len(t, listings, 1, "expected one active listing")
+	assert.Equal(t, "seller", listings[0].Seller)
+
+	path := fmt.Sprintf("/api/market/%d", listing.ID)
+
+	assert.Equal(t, http.StatusBadRequest, marketRequest(http.MethodPost, path+"/buy", "", sellerToken).Code, "expected error for buying own listing")
+	assert.Equal(t, http.StatusNotFound, marketRequest(http.MethodPost, "/api/market/999/buy", "", buyerToken).Code, "expected 404 for unknown listing")
+	assert.Equal(t, http.StatusOK, marketRequest(http.MethodPost, path+"/buy", "", buyerToken).Code, "expected OK response for buy")
+	assert.Equal(t, http.StatusBadRequest, marketRequest(http.MethodPost, path+"/cancel", "", sellerToken).Code, "expected error for cancelling sold listing")
+
+	sellerInfo := getInfo(t, router, sellerToken)
+	assert.Equal(t, 1080, sellerInfo.Coins, "expected seller to receive the price")
+	assert.Len(t, sellerInfo.Inventory, 0, "expected item to leave seller's inventory")
+
+	buyerInfo := getInfo(t, router, buyerToken)
+	assert.Equal(t, 900, buyerInfo.Coins, "expected buyer to pay the price")
+	assert.Equal(t, []models.Item{{Type: "cup", Quantity: 1}}, buyerInfo.Inventory, "expected item in buyer's inventory")
+}
+
+func TestE2ENotifications(t *testing.T) {
+	router, db := setupTestWithDB(t)
+
+	senderToken := registerUser(t, router, "sender")
+	receiverToken := registerUser(t, router, "receiver")
+	adminToken := registerUser(t, router, "admin")
+
+	assert.NoError(t, db.Model(&database.User{}).Where("username = ?", "admin").Update("is_admin", true).Error, "failed to promote admin")
+
+	request := func(method, path, payload, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		return recorder
+	}
+
+	getNotifications := func(path string) models.NotificationsResponse {
+		recorder := request(http.MethodGet, path, "", receiverToken)
+		assert.Equal(t, http.StatusOK, recorder.Code, "expected OK response for notifications")
+
+		var resp models.NotificationsResponse
+
+		assert.NoError(t, json.NewDecoder(recorder.Body).Decode(&resp), "failed to decode notifications")
+
+		return resp
+	}
+
+	code, _ := transferCoins(router, "receiver", senderToken)
+	assert.Equal(t, http.StatusOK, code, "expected OK response for transfer")
+
+	recorder := request(http.MethodPost, "/api/gift", `{"toUser": "receiver", "item": "pen"}`, senderToken)
+	assert.Equal(t, http.StatusOK, recorder.Code, "expected OK response for gift")
+
+	recorder = request(http.MethodPost, "/api/admin/notifications", `{"toUser": "receiver", "message": "Welcome!"}`, adminToken)
+	assert.Equal(t, http.StatusOK, recorder.Code, "expected OK response for admin notification")
+
+	recorder = request(http.MethodPost, "/api/admin/notifications", `{"message": "Welcome!"}`, receiverToken)
+	assert.Equal(t, http.StatusForbidden, recorder.Code, "expected Forbidden for non-admin user")
+
+	resp := getNotifications("/api/notifications")
+	assert.Equal(t, int64(3), resp.Unread, "expected all notifications to be unread")
+	assert.Len(t, resp.Notifications, 3)
+	assert.Equal(t, "admin", resp.Notifications[0].Type)
+	assert.Equal(t, "Welcome!", resp.Notifications[0].Message)
+	assert.Equal(t, "sender gifted you pen", resp.Notifications[1].Message)
+	assert.Equal(t, "sender sent you 100 coins", resp.Notifications[2].Message)
+
+	recorder = request(http.MethodPost, fmt.Sprintf("/api/notifications/%d/read", resp.Notifications[2].ID), "", receiverToken)
+	assert.Equal(t, http.StatusOK, recorder.Code, "expected OK response for mark read")
+
+	recorder = request(http.MethodPost, fmt.Sprintf("/api/notifications/%d/read", resp.Notifications[2].ID), "", senderToken)
+	assert.Equal(t, http.StatusNotFound, recorder.Code, "expected NotFound for someone else's notification")
+
+	resp = getNotifications("/api/notifications?unread=true")
+	assert.Equal(t, int64(2), resp.Unread)
+	assert.Len(t, resp.Notifications, 2, "expected read notification to be filtered out")
+
+	recorder = request(http.MethodPost, "/api/notifications/read", "", receiverToken)
+	assert.Equal(t, http.StatusOK, recorder.Code, "expected OK response for mark all read")
+
+	resp = getNotifications("/api/notifications")
+	assert.Zero(t, resp.Unread)
+	assert.Len(t, resp.Notifications, 3)
+	assert.True(t, resp.Notifications[0].Read)
+}
+
+func TestE2EEventStream(t *testing.T) {
+	router := setupTest(t)
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	senderToken := registerUser(t, router, "sender")
+	receiverToken := registerUser(t, router, "receiver")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/events", nil)
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+receiverToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err, "failed to connect to event stream")
+
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+
+	// nextEvent читает поток до следующего события и возвращает его тип и данные
+	nextEvent := func() (string, string) {
+		var eventType, data string
+
+		for {
+			line, err := reader.ReadString('\n')
+			if !assert.NoError(t, err, "failed to read event stream") {
+				return "", ""
+			}
+
+			line = strings.TrimRight(line, "\n")
+
+			switch {
+			case strings.HasPrefix(line, "event:"):
+				eventType = strings.TrimPrefix(line, "event:")
+			case strings.HasPrefix(line, "data:"):
+				data = strings.TrimPrefix(line, "data:")
+			case line == "" && eventType != "":
+				return eventType, data
+			}
+		}
+	}
+
+	// Ждём подтверждения подписки, чтобы не пропустить события
+	line, err := reader.ReadString('\n')
+	assert.NoError(t, err)
+	assert.Equal(t, ": connected\n", line)
+
+	code, _ := transferCoins(router, "receiver", senderToken)
+	assert.Equal(t, http.StatusOK, code, "expected OK response for transfer")
+
+	eventType, data := nextEvent()
+	assert.Equal(t, events.TypeTransfer, eventType)
+	assert.JSONEq(t, `{"fromUser": "sender", "amount": 100}`, data)
+
+	eventType, data = nextEvent()
+	assert.Equal(t, events.TypeBalance, eventType)
+	assert.JSONEq(t, `{"coins": 1100}`, data)
+
+	code, _ = buyItem(router, "cup", receiverToken)
+	assert.Equal(t, http.StatusOK, code, "expected OK response for buy")
+
+	eventType, data = nextEvent()
+	assert.Equal(t, events.TypePurchase, eventType)
+	assert.JSONEq(t, `{"item": "cup", "price": 20}`, data)
+
+	eventType, data = nextEvent()
+	assert.Equal(t, events.TypeBalance, eventType)
+	assert.JSONEq(t, `{"coins": 1080}`, data)
+}
+
+func TestE2EWebSocket(t *testing.T) {
+	router := setupTest(t)
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	senderToken := registerUser(t, router, "sender")
+	registerUser(t, router, "receiver")
+
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+senderToken)
+
+	conn, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/api/ws", header)
+	if !assert.NoError(t, err, "failed to connect to websocket") {
+		return
+	}
+
+	defer resp.Body.Close()
+	defer conn.Close()
+
+	assert.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	// readUntil читает сообщения, пока не встретит подходящее под match
+	readUntil := func(match func(models.WSMessage) bool) json.RawMessage {
+		for {
+			var raw json.RawMessage
+			if !assert.NoError(t, conn.ReadJSON(&raw), "failed to read websocket message") {
+				return nil
+			}
+
+			var message models.WSMessage
+
+			assert.NoError(t, json.Unmarshal(raw, &message))
+
+			if match(message) {
+				return raw
+			}
+		}
+	}
+
+	byID := func(id string) func(models.WSMessage) bool {
+		return func(message models.WSMessage) bool { return message.ID == id }
+	}
+
+	assert.NoError(t, conn.WriteJSON(models.WSCommand{ID: "1", Type: models.WSCommandSendCoin, Payload: json.RawMessage(`{"toUser": "receiver", "amount": 100}`)}))
+	assert.JSONEq(t, `{"id": "1", "type": "result"}`, string(readUntil(byID("1"))))
+
+	assert.NoError(t, conn.WriteJSON(models.WSCommand{ID: "2", Type: models.WSCommandSendCoin, Payload: json.RawMessage(`{"toUser": "sender", "amount": 100}`)}))
+	assert.JSONEq(t, `{"id": "2", "type": "error", "error": "bad request: can't transfer to yourself"}`, string(readUntil(byID("2"))))
+
+	assert.NoError(t, conn.WriteJSON(models.WSCommand{ID: "3", Type: models.WSCommandBuy, Payload: json.RawMessage(`{"item": "cup"}`)}))
+
+	// События и ответы на команды идут в одном соединении, поэтому событие может прийти раньше ответа
+	var result, purchase json.RawMessage
+
+	for result == nil || purchase == nil {
+		raw := readUntil(func(message models.WSMessage) bool { return message.ID == "3" || message.Event == events.TypePurchase })
+		if raw == nil {
+			break
+		}
+
+		if strings.Contains(string(raw), `"event"`) {
+			purchase = raw
+		} else {
+			result = raw
+		}
+	}
+
+	assert.JSONEq(t, `{"id": "3", "type": "result"}`, string(result))
+	assert.JSONEq(t, `{"type": "event", "event": "purchase", "data": {"item": "cup", "price": 20}}`, string(purchase))
+
+	assert.NoError(t, conn.WriteJSON(models.WSCommand{ID: "4", Type: "unknown"}))
+	assert.JSONEq(t, `{"id": "4", "type": "error", "error": "bad request: unknown command"}`, string(readUntil(byID("4"))))
+
+	assert.NoError(t, conn.WriteJSON(models.WSCommand{ID: "5", Type: models.WSCommandInfo}))
+
+	var info struct {
+		Data models.InfoResponse `json:"data"`
+	}
+
+	assert.NoError(t, json.Unmarshal(readUntil(byID("5")), &info))
+	assert.Equal(t, 1000-100-20, info.Data.Coins, "expected transfer and purchase to be applied")
+	assert.Equal(t, []models.Item{{Type: "cup", Quantity: 1}}, info.Data.Inventory)
+}
+
+func TestE2EGraphQL(t *testing.T) {
+	router := setupTest(t)
+
+	senderToken := registerUser(t, router, "sender")
+	registerUser(t, router, "receiver")
+
+	type graphQLResponse struct {
+		Data struct {
+			SendCoin  int `json:"sendCoin"`
+			Buy       int `json:"buy"`
+			Balance   int `json:"balance"`
+			Inventory []struct {
+				Type     string `json:"type"`
+				Quantity int    `json:"quantity"`
+			} `json:"inventory"`
+			History struct {
+				Transfers []struct {
+					Direction    string `json:"direction"`
+					Counterparty string `json:"counterparty"`
+					Amount       int    `json:"amount"`
+				} `json:"transfers"`
+				TotalCount  int  `json:"totalCount"`
+				HasNextPage bool `json:"hasNextPage"`
+			} `json:"history"`
+			Goods []struct {
+				Type  string `json:"type"`
+				Price int    `json:"price"`
+			} `json:"goods"`
+		} `json:"data"`
+		Errors []struct {
+			Message    string `json:"message"`
+			Extensions struct {
+				Code string `json:"code"`
+			} `json:"extensions"`
+		} `json:"errors"`
+	}
+
+	query := func(q string, variables map[string]interface{}) graphQLResponse {
+		payload, err := json.Marshal(models.GraphQLRequest{Query: q, Variables: variables})
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/graphql", bytes.NewBuffer(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+senderToken)
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		assert.Equal(t, http.StatusOK, recorder.Code, "expected OK response for graphql")
+
+		var resp graphQLResponse
+
+		assert.NoError(t, json.NewDecoder(recorder.Body).Decode(&resp), "failed to decode graphql response")
+
+		return resp
+	}
+
+	resp := query(`mutation($to: String!) { sendCoin(toUser: $to, amount: 100) }`, map[string]interface{}{"to": "receiver"})
+	assert.Empty(t, resp.Errors)
+	assert.Equal(t, 900, resp.Data.SendCoin, "expected new balance after transfer")
+
+	resp = query(`mutation { buy(item: "cup") }`, nil)
+	assert.Empty(t, resp.Errors)
+	assert.Equal(t, 880, resp.Data.Buy, "expected new balance after purchase")
+
+	resp = query(`{ balance }`, nil)
+	assert.Equal(t, 880, resp.Data.Balance)
+	assert.Nil(t, resp.Data.Inventory, "only requested fields should be returned")
+
+	resp = query(`{ inventory { type quantity } history(first: 1) { transfers { direction counterparty amount } totalCount hasNextPage } goods { type price } }`, nil)
+	assert.Empty(t, resp.Errors)
+	assert.Len(t, resp.Data.Inventory, 1)
+	assert.Equal(t, "cup", resp.Data.Inventory[0].Type)
+	assert.Len(t, resp.Data.History.Transfers, 1)
+	assert.Equal(t, models.TransferDirectionSent, resp.Data.History.Transfers[0].Direction)
+	assert.Equal(t, "receiver", resp.Data.History.Transfers[0].Counterparty)
+	assert.Equal(t, 1, resp.Data.History.TotalCount)
+	assert.False(t, resp.Data.History.HasNextPage)
+	assert.NotEmpty(t, resp.Data.Goods, "expected goods catalog")
+
+	resp = query(`mutation { sendCoin(toUser: "receiver", amount: 100000) }`, nil)
+	assert.Len(t, resp.Errors, 1)
+	assert.Equal(t, services.ErrInsufficientFunds.Error(), resp.Errors[0].Message)
+	assert.Equal(t, "BAD_REQUEST", resp.Errors[0].Extensions.Code)
+
+	resp = query(`{ history(first: 1000) { totalCount } }`, nil)
+	assert.Len(t, resp.Errors, 1)
+	assert.Equal(t, services.ErrInvalidPage.Error(), resp.Errors[0].Message)
+}
+
+func TestOpenAPIDocumentCoversRoutes(t *testing.T) {
+	router := setupTest(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusOK, recorder.Code, "expected OK response for openapi document")
+
+	var doc struct {
+		OpenAPI string                            `json:"openapi"`
+		Paths   map[string]map[string]interface{} `json:"paths"`
+	}
+
+	assert.NoError(t, json.NewDecoder(recorder.Body).Decode(&doc), "failed to decode openapi document")
+	assert.Equal(t, "3.0.3", doc.OpenAPI)
+
+	documented := make(map[string]bool)
+
+	for path, item := range doc.Paths {
+		for method := range item {
+			documented[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+
+	pathParam := regexp.MustCompile(`:(\w+)`)
+
+	for _, route := range router.Routes() {
+		key := route.Method + " " + pathParam.ReplaceAllString(route.Path, "{$1}")
+		assert.True(t, documented[key], "route %s is not documented", key)
+		delete(documented, key)
+	}
+
+	assert.Empty(t, documented, "documented operations without routes")
+}
+
+func TestE2ERequestValidation(t *testing.T) {
+	router := setupTest(t)
+	token := registerUser(t, router, "user")
+
+	request := func(method, path, payload string) (int, models.ErrorResponse) {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		var resp models.ErrorResponse
+
+		_ = json.NewDecoder(recorder.Body).Decode(&resp)
+
+		return recorder.Code, resp
+	}
+
+	code, resp := request(http.MethodPost, "/api/sendCoin", `{"toUser": "user2", "amount": "100"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.True(t, strings.HasPrefix(resp.Errors, "bad request: request body: field amount:"), "unexpected error: %s", resp.Errors)
+
+	code, resp = request(http.MethodPost, "/api/sendCoin", `{"toUser": `)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.True(t, strings.HasPrefix(resp.Errors, "bad request: request body:"), "unexpected error: %s", resp.Errors)
+
+	code, resp = request(http.MethodPost, "/api/sendCoin", "")
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.True(t, strings.HasPrefix(resp.Errors, "bad request: request body:"), "unexpected error: %s", resp.Errors)
+
+	code, resp = request(http.MethodGet, "/api/notifications?unread=maybe", "")
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.True(t, strings.HasPrefix(resp.Errors, "bad request: query parameter unread:"), "unexpected error: %s", resp.Errors)
+
+	code, resp = request(http.MethodPost, "/api/market/abc/buy", "")
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.True(t, strings.HasPrefix(resp.Errors, "bad request: path parameter id:"), "unexpected error: %s", resp.Errors)
+
+	code, _ = request(http.MethodPost, "/api/sendCoin", `{"toUser": "user", "amount": 100}`)
+	assert.Equal(t, http.StatusBadRequest, code, "valid request should reach the handler")
+
+	code, _ = request(http.MethodGet, "/api/unknown", "")
+	assert.Equal(t, http.StatusNotFound, code, "unknown routes should be left to the router")
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maksemen2/avito-shop/internal/middleware"
+	"github.com/maksemen2/avito-shop/internal/models"
+	"github.com/maksemen2/avito-shop/internal/services"
+)
+
+func (h *RequestsHandler) GetListings(c *gin.Context) {
+	resp, err := h.marketService.ListListings(c.Request.Context(), c.Query("item"), c.Query("seller"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.NewErrorResponse(models.ErrInternal))
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *RequestsHandler) CreateListing(c *gin.Context) {
+	var req models.CreateListingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.NewErrorResponse(models.ErrBadRequest))
+		return
+	}
+
+	userID, _ := middleware.GetUserID(c)
+	username, _ := middleware.GetUsername(c)
+
+	resp, err := h.marketService.CreateListing(c.Request.Context(), userID, username, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInternal):
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.NewErrorResponse(models.ErrInternal))
+		default:
+			c.AbortWithStatusJSON(http.StatusBadRequest, models.NewDetailedErrorResponse(models.ErrBadRequest, err.Error()))
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *RequestsHandler) BuyListing(c *gin.Context) {
+	h.handleListing(c, h.marketService.BuyListing)
+}
+
+func (h *RequestsHandler) CancelListing(c *gin.Context) {
+	h.handleListing(c, h.marketService.CancelListing)
+}
+
+// handleListing разбирает идентификатор объявления из пути и применяет к нему action от имени текущего пользователя.
+func (h *RequestsHandler) handleListing(c *gin.Context, action func(ctx context.Context, userID, listingID uint) error) {
+	listingID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.NewDetailedErrorResponse(models.ErrBadRequest, "invalid listing id"))
+		return
+	}
+
+	userID, _ := middleware.GetUserID(c)
+
+	if err := action(c.Request.Context(), userID, uint(listingID)); err != nil {
+		switch {
+		case errors.Is(err, services.ErrInternal):
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.NewErrorResponse(models.ErrInternal))
+		case errors.Is(err, services.ErrListingNotFound):
+			c.AbortWithStatusJSON(http.StatusNotFound, models.NewDetailedErrorResponse(models.ErrNotFound, err.Error()))
+		case errors.Is(err, services.ErrDailyLimitExceeded), errors.Is(err, services.ErrAccountTooNew), errors.Is(err, services.ErrUserLocked):
+			c.AbortWithStatusJSON(http.StatusForbidden, models.NewDetailedErrorResponse(models.ErrForbidden, err.Error()))
+		default:
+			c.AbortWithStatusJSON(http.StatusBadRequest, models.NewDetailedErrorResponse(models.ErrBadRequest, err.Error()))
+		}
+
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maksemen2/avito-shop/internal/middleware"
+	"github.com/maksemen2/avito-shop/internal/models"
+	"github.com/maksemen2/avito-shop/internal/services"
+)
+
+func (h *RequestsHandler) GetNotifications(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	resp, err := h.notificationService.List(c.Request.Context(), userID, c.Query("unread") == "true")
+	if err != nil {
+		// может быть только services.ErrInternal
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.NewErrorResponse(models.ErrInternal))
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *RequestsHandler) MarkNotificationRead(c *gin.Context) {
+	notificationID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.NewDetailedErrorResponse(models.ErrBadRequest, "invalid notification id"))
+		return
+	}
+
+	userID, _ := middleware.GetUserID(c)
+
+	if err := h.notificationService.MarkRead(c.Request.Context(), userID, uint(notificationID)); err != nil {
+		switch {
+		case errors.Is(err, services.ErrNotificationNotFound):
+			c.AbortWithStatusJSON(http.StatusNotFound, models.NewDetailedErrorResponse(models.ErrNotFound, err.Error()))
+		default:
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.NewErrorResponse(models.ErrInternal))
+		}
+
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (h *RequestsHandler) MarkAllNotificationsRead(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	marked, err := h.notificationService.MarkAllRead(c.Request.Context(), userID)
+	if err != nil {
+		// может быть только services.ErrInternal
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.NewErrorResponse(models.ErrInternal))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MarkAllReadResponse{Marked: marked})
+}
+
+func (h *RequestsHandler) SendNotification(c *gin.Context) {
+	var req models.AdminNotificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.NewErrorResponse(models.ErrBadRequest))
+		return
+	}
+
+	sent, err := h.notificationService.Send(c.Request.Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInternal):
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.NewErrorResponse(models.ErrInternal))
+		default:
+			c.AbortWithStatusJSON(http.StatusBadRequest, models.NewDetailedErrorResponse(models.ErrBadRequest, err.Error()))
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, models.AdminNotificationResponse{Sent: sent})
+}
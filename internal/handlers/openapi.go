@@ -0,0 +1,12 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetOpenAPIDocument отдаёт OpenAPI-документ, по которому ValidationMiddleware проверяет запросы.
+func (h *RequestsHandler) GetOpenAPIDocument(c *gin.Context) {
+	c.JSON(http.StatusOK, h.OpenAPIDocument)
+}
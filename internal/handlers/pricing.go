@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maksemen2/avito-shop/internal/models"
+	"github.com/maksemen2/avito-shop/internal/services"
+)
+
+func (h *RequestsHandler) GetCatalog(c *gin.Context) {
+	resp, err := h.pricingService.Catalog(c.Request.Context(), time.Now())
+	if err != nil {
+		// может быть только services.ErrInternal
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.NewErrorResponse(models.ErrInternal))
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *RequestsHandler) GetPriceSchedules(c *gin.Context) {
+	resp, err := h.pricingService.ListSchedules(c.Request.Context())
+	if err != nil {
+		// может быть только services.ErrInternal
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.NewErrorResponse(models.ErrInternal))
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *RequestsHandler) CreatePriceSchedule(c *gin.Context) {
+	var req models.CreatePriceScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.NewErrorResponse(models.ErrBadRequest))
+		return
+	}
+
+	resp, err := h.pricingService.CreateSchedule(c.Request.Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInternal):
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.NewErrorResponse(models.ErrInternal))
+		default:
+			c.AbortWithStatusJSON(http.StatusBadRequest, models.NewDetailedErrorResponse(models.ErrBadRequest, err.Error()))
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *RequestsHandler) DeletePriceSchedule(c *gin.Context) {
+	scheduleID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.NewDetailedErrorResponse(models.ErrBadRequest, "invalid schedule id"))
+		return
+	}
+
+	if err := h.pricingService.DeleteSchedule(c.Request.Context(), uint(scheduleID)); err != nil {
+		switch {
+		case errors.Is(err, services.ErrPriceScheduleNotFound):
+			c.AbortWithStatusJSON(http.StatusNotFound, models.NewDetailedErrorResponse(models.ErrNotFound, err.Error()))
+		default:
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.NewErrorResponse(models.ErrInternal))
+		}
+
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
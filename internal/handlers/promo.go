@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maksemen2/avito-shop/internal/models"
+	"github.com/maksemen2/avito-shop/internal/services"
+)
+
+func (h *RequestsHandler) GetPromoCodes(c *gin.Context) {
+	resp, err := h.promoService.ListPromoCodes(c.Request.Context())
+	if err != nil {
+		// может быть только services.ErrInternal
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.NewErrorResponse(models.ErrInternal))
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *RequestsHandler) CreatePromoCode(c *gin.Context) {
+	var req models.CreatePromoCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.NewErrorResponse(models.ErrBadRequest))
+		return
+	}
+
+	resp, err := h.promoService.CreatePromoCode(c.Request.Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInternal):
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.NewErrorResponse(models.ErrInternal))
+		default:
+			c.AbortWithStatusJSON(http.StatusBadRequest, models.NewDetailedErrorResponse(models.ErrBadRequest, err.Error()))
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *RequestsHandler) ActivatePromoCode(c *gin.Context) {
+	h.setPromoCodeActive(c, true)
+}
+
+func (h *RequestsHandler) DeactivatePromoCode(c *gin.Context) {
+	h.setPromoCodeActive(c, false)
+}
+
+func (h *RequestsHandler) setPromoCodeActive(c *gin.Context, active bool) {
+	promoCodeID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.NewDetailedErrorResponse(models.ErrBadRequest, "invalid promo code id"))
+		return
+	}
+
+	if err := h.promoService.SetPromoCodeActive(c.Request.Context(), uint(promoCodeID), active); err != nil {
+		switch {
+		case errors.Is(err, services.ErrPromoCodeNotFound):
+			c.AbortWithStatusJSON(http.StatusNotFound, models.NewDetailedErrorResponse(models.ErrNotFound, err.Error()))
+		default:
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.NewErrorResponse(models.ErrInternal))
+		}
+
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
@@ -13,14 +13,19 @@ import (
 func (h *RequestsHandler) BuyItem(c *gin.Context) {
 	item := c.Param("item")
 	userID, _ := middleware.GetUserID(c)
-	err := h.purchaseService.BuyGood(c.Request.Context(), userID, item)
+	err := h.purchaseService.BuyGood(c.Request.Context(), userID, item, models.BuyOptions{
+		Variant: c.Query("variant"),
+		Promo:   c.Query("promo"),
+	})
 
 	if err != nil {
 		switch {
 		case errors.Is(err, services.ErrInternal):
 			c.AbortWithStatusJSON(http.StatusInternalServerError, models.NewErrorResponse(models.ErrInternal))
+		case errors.Is(err, services.ErrUserLocked):
+			c.AbortWithStatusJSON(http.StatusForbidden, models.NewDetailedErrorResponse(models.ErrForbidden, err.Error()))
 		default:
-			// Могут возвращаться только ошибки с кодом ответа 400, поэтому можем себе позволить поступить так
+			// Остальные ошибки соответствуют коду ответа 400, поэтому можем себе позволить поступить так
 			c.AbortWithStatusJSON(http.StatusBadRequest, models.NewDetailedErrorResponse(models.ErrBadRequest, err.Error()))
 		}
 
@@ -1,33 +1,81 @@
 package handlers
 
 import (
+	"github.com/getkin/kin-openapi/openapi3"
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/maksemen2/avito-shop/config"
+	"github.com/maksemen2/avito-shop/internal/events"
+	"github.com/maksemen2/avito-shop/internal/gql"
+	"github.com/maksemen2/avito-shop/internal/openapi"
 	"github.com/maksemen2/avito-shop/internal/repository"
 	"github.com/maksemen2/avito-shop/internal/services"
 	"github.com/maksemen2/avito-shop/pkg/auth"
 	"go.uber.org/zap"
-	"gorm.io/gorm"
 )
 
 type RequestsHandler struct {
-	JWTManager      *auth.JWTManager
-	authService     services.AuthService
-	transferService services.TransferService
-	purchaseService services.PurchaseService
-	infoService     services.InfoService
-	logger          *zap.Logger
+	AdminService        services.AdminService
+	AuthService         services.AuthService
+	JWTManager          *auth.JWTManager
+	OpenAPIDocument     *openapi3.T
+	transferService     services.TransferService
+	purchaseService     services.PurchaseService
+	infoService         services.InfoService
+	coinRequestService  services.CoinRequestService
+	grantService        services.GrantService
+	itemTransferService services.ItemTransferService
+	marketService       services.MarketService
+	promoService        services.PromoService
+	pricingService      services.PricingService
+	variantService      services.VariantService
+	wishlistService     services.WishlistService
+	notificationService services.NotificationService
+	webhookService      services.WebhookService
+	graphQLSchema       *graphql.Schema
+	broker              *events.Broker
+	logger              *zap.Logger
 }
 
 // NewRequestsHandler создаёт новый экземпляр RequestsHandler.
 // Эта структура нужна для инъекции зависимостей в хендлеры.
-func NewRequestsHandler(db *gorm.DB, jwtManager *auth.JWTManager, logger *zap.Logger) *RequestsHandler {
-	repository := repository.NewHolderRepository(db, logger)
-
-	return &RequestsHandler{
-		JWTManager:      jwtManager,
-		logger:          logger,
-		authService:     services.NewAuthService(repository, jwtManager, logger),
-		transferService: services.NewTransferService(repository, logger),
-		purchaseService: services.NewPurchaseService(repository, logger),
-		infoService:     services.NewInfoService(repository, logger),
+// Из broker хендлеры /api/events и /api/ws получают события, которые публикует хук services.NewEventsHook репозитория.
+// Если infoCache не nil, сводки /api/info кешируются в нём.
+func NewRequestsHandler(
+	repository repository.HolderRepository,
+	infoCache services.InfoCache,
+	jwtManager *auth.JWTManager,
+	broker *events.Broker,
+	cfg *config.Config,
+	logger *zap.Logger,
+) *RequestsHandler {
+	handler := &RequestsHandler{
+		AdminService:        services.NewAdminService(repository, infoCache, logger),
+		JWTManager:          jwtManager,
+		OpenAPIDocument:     openapi.MustLoad(),
+		broker:              broker,
+		logger:              logger,
+		AuthService:         services.NewAuthService(repository, jwtManager, logger),
+		transferService:     services.NewTransferService(repository, cfg.Transfer, logger),
+		purchaseService:     services.NewPurchaseService(repository, logger),
+		infoService:         services.NewCachedInfoService(services.NewInfoService(repository, logger), infoCache),
+		coinRequestService:  services.NewCoinRequestService(repository, cfg.Transfer, logger),
+		grantService:        services.NewGrantService(repository, logger),
+		itemTransferService: services.NewItemTransferService(repository, logger),
+		marketService:       services.NewMarketService(repository, cfg.Transfer, logger),
+		promoService:        services.NewPromoService(repository, logger),
+		pricingService:      services.NewPricingService(repository, logger),
+		variantService:      services.NewVariantService(repository, logger),
+		wishlistService:     services.NewWishlistService(repository, logger),
+		notificationService: services.NewNotificationService(repository, logger),
+		webhookService:      services.NewWebhookService(repository, cfg.Webhooks, logger),
 	}
+
+	handler.graphQLSchema = gql.NewSchema(gql.Services{
+		Info:     handler.infoService,
+		Pricing:  handler.pricingService,
+		Transfer: handler.transferService,
+		Purchase: handler.purchaseService,
+	})
+
+	return handler
 }
@@ -26,8 +26,10 @@ func (h *RequestsHandler) SendCoin(c *gin.Context) {
 		switch {
 		case errors.Is(err, services.ErrInternal):
 			c.AbortWithStatusJSON(http.StatusInternalServerError, models.NewErrorResponse(models.ErrInternal))
+		case errors.Is(err, services.ErrDailyLimitExceeded), errors.Is(err, services.ErrAccountTooNew), errors.Is(err, services.ErrUserLocked):
+			c.AbortWithStatusJSON(http.StatusForbidden, models.NewDetailedErrorResponse(models.ErrForbidden, err.Error()))
 		default:
-			// Могут возвращаться только ошибки с кодом ответа 400, поэтому можем себе позволить поступить так
+			// Остальные ошибки соответствуют коду ответа 400, поэтому можем себе позволить поступить так
 			c.AbortWithStatusJSON(http.StatusBadRequest, models.NewDetailedErrorResponse(models.ErrBadRequest, err.Error()))
 		}
 
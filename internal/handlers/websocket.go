@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/maksemen2/avito-shop/internal/middleware"
+	"github.com/maksemen2/avito-shop/internal/models"
+	"github.com/maksemen2/avito-shop/internal/services"
+	"go.uber.org/zap"
+)
+
+const (
+	// Время на отправку одного сообщения клиенту
+	wsWriteWait = 10 * time.Second
+	// Время ожидания pong от клиента. Ping отправляется чуть чаще, чтобы pong успел прийти
+	wsPongWait   = 60 * time.Second
+	wsPingPeriod = wsPongWait * 9 / 10
+	// Максимальный размер команды клиента
+	wsMaxCommandSize = 4096
+	// Размер очереди ответов на команды
+	wsResultsBuffer = 16
+)
+
+// WebSocket обслуживает двунаправленный канал: принимает команды sendCoin, buy и info
+// и отправляет клиенту результаты команд и события пользователя из брокера.
+// Команды выполняются через те же сервисы, что и HTTP API, и обрабатываются по очереди.
+func (h *RequestsHandler) WebSocket(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	username, _ := middleware.GetUsername(c)
+
+	// Origin проверяется по умолчанию: с чужого домена браузер подключиться не сможет,
+	// а клиенты без Origin, например боты, смогут
+	upgrader := websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+	}
+
+	// При ошибке апгрейдер сам отвечает клиенту
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	stream, unsubscribe := h.broker.Subscribe(userID)
+	defer unsubscribe()
+
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	results := make(chan models.WSMessage, wsResultsBuffer)
+
+	go func() {
+		// Закрытие соединения ошибкой чтения завершает и цикл записи
+		defer cancel()
+
+		h.readWSCommands(ctx, conn, userID, username, results)
+	}()
+
+	ping := time.NewTicker(wsPingPeriod)
+	defer ping.Stop()
+
+	// Писать в соединение можно только из одной горутины, поэтому все сообщения отправляются отсюда
+	for {
+		var message models.WSMessage
+
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-stream:
+			if !ok {
+				_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""), time.Now().Add(wsWriteWait))
+				return
+			}
+
+			message = models.WSMessage{Type: models.WSMessageEvent, Event: event.Type, Data: event.Data}
+		case message = <-results:
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+				return
+			}
+
+			continue
+		}
+
+		_ = conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+
+		if err := conn.WriteJSON(message); err != nil {
+			h.logger.Debug("failed to write websocket message", zap.Uint("userID", userID), zap.Error(err))
+			return
+		}
+	}
+}
+
+// readWSCommands читает команды клиента, пока соединение открыто, и кладёт ответы на них в results.
+func (h *RequestsHandler) readWSCommands(ctx context.Context, conn *websocket.Conn, userID uint, username string, results chan<- models.WSMessage) {
+	conn.SetReadLimit(wsMaxCommandSize)
+	_ = conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		var command models.WSCommand
+		if err := conn.ReadJSON(&command); err != nil {
+			var syntaxErr *json.SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				return
+			}
+
+			// Некорректный JSON не разрывает соединение
+			command = models.WSCommand{}
+		}
+
+		select {
+		case results <- h.executeWSCommand(ctx, userID, username, command):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// executeWSCommand выполняет команду клиента и возвращает ответ на неё.
+func (h *RequestsHandler) executeWSCommand(ctx context.Context, userID uint, username string, command models.WSCommand) models.WSMessage {
+	var (
+		data any
+		err  error
+	)
+
+	switch command.Type {
+	case models.WSCommandSendCoin:
+		var req models.SendCoinRequest
+		if err := json.Unmarshal(command.Payload, &req); err != nil {
+			return wsError(command.ID, models.NewErrorResponse(models.ErrBadRequest))
+		}
+
+		err = h.transferService.SendCoins(ctx, userID, username, req)
+	case models.WSCommandBuy:
+		var req models.WSBuyPayload
+		if err := json.Unmarshal(command.Payload, &req); err != nil {
+			return wsError(command.ID, models.NewErrorResponse(models.ErrBadRequest))
+		}
+
+		err = h.purchaseService.BuyGood(ctx, userID, req.Item, models.BuyOptions{Variant: req.Variant, Promo: req.Promo})
+	case models.WSCommandInfo:
+		data, err = h.infoService.GetInfo(ctx, userID)
+	default:
+		return wsError(command.ID, models.NewDetailedErrorResponse(models.ErrBadRequest, "unknown command"))
+	}
+
+	if err != nil {
+		if errors.Is(err, services.ErrInternal) {
+			return wsError(command.ID, models.NewErrorResponse(models.ErrInternal))
+		}
+
+		// Блокировка, выставленная после подключения, проверяется транзакцией списания каждой команды
+		if errors.Is(err, services.ErrUserLocked) {
+			return wsError(command.ID, models.NewDetailedErrorResponse(models.ErrForbidden, err.Error()))
+		}
+
+		return wsError(command.ID, models.NewDetailedErrorResponse(models.ErrBadRequest, err.Error()))
+	}
+
+	return models.WSMessage{ID: command.ID, Type: models.WSMessageResult, Data: data}
+}
+
+func wsError(id string, resp models.ErrorResponse) models.WSMessage {
+	return models.WSMessage{ID: id, Type: models.WSMessageError, Error: resp.Errors}
+}
@@ -0,0 +1,77 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Job - периодическая фоновая задача.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Scheduler запускает фоновые задачи с заданным интервалом до отмены контекста.
+type Scheduler struct {
+	jobs   []Job
+	logger *zap.Logger
+	wg     sync.WaitGroup
+}
+
+func NewScheduler(logger *zap.Logger) *Scheduler {
+	return &Scheduler{logger: logger}
+}
+
+// Add регистрирует задачу. Задачи с неположительным интервалом не запускаются.
+func (s *Scheduler) Add(job Job) {
+	if job.Interval <= 0 {
+		s.logger.Info("Job disabled", zap.String("job", job.Name))
+		return
+	}
+
+	s.jobs = append(s.jobs, job)
+}
+
+// Start запускает все задачи. Первый запуск каждой задачи происходит сразу, следующие - по таймеру.
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+
+		go func(job Job) {
+			defer s.wg.Done()
+
+			ticker := time.NewTicker(job.Interval)
+			defer ticker.Stop()
+
+			for {
+				s.run(ctx, job)
+
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}(job)
+	}
+}
+
+// Wait дожидается завершения всех задач после отмены контекста.
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Scheduler) run(ctx context.Context, job Job) {
+	start := time.Now()
+
+	if err := job.Run(ctx); err != nil {
+		s.logger.Error("Job failed", zap.String("job", job.Name), zap.Error(err))
+		return
+	}
+
+	s.logger.Debug("Job finished", zap.String("job", job.Name), zap.Duration("duration", time.Since(start)))
+}
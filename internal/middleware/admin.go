@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maksemen2/avito-shop/internal/models"
+	"go.uber.org/zap"
+)
+
+// AdminChecker проверяет, является ли пользователь администратором.
+type AdminChecker interface {
+	IsAdmin(ctx context.Context, userID uint) (bool, error)
+}
+
+// AdminMiddleware пропускает дальше только администраторов. Должен подключаться после AuthMiddleware.
+func AdminMiddleware(logger *zap.Logger, checker AdminChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := GetUserID(c)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, models.ErrorResponse{Errors: models.ErrUnauthorized})
+			return
+		}
+
+		isAdmin, err := checker.IsAdmin(c.Request.Context(), userID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.NewErrorResponse(models.ErrInternal))
+			return
+		}
+
+		if !isAdmin {
+			logger.Warn("Non-admin user tried to access admin API",
+				zap.Uint("userID", userID),
+				zap.String("path", c.Request.URL.Path))
+			c.AbortWithStatusJSON(http.StatusForbidden, models.ErrorResponse{Errors: models.ErrForbidden})
+
+			return
+		}
+
+		c.Next()
+	}
+}
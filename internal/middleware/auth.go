@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"strings"
 
@@ -11,7 +12,13 @@ import (
 	"go.uber.org/zap"
 )
 
-func AuthMiddleware(logger *zap.Logger, jwtManager *auth.JWTManager) gin.HandlerFunc {
+// LockChecker проверяет, заблокирован ли пользователь.
+type LockChecker interface {
+	IsLocked(ctx context.Context, userID uint) (bool, error)
+}
+
+// AuthMiddleware проверяет токен из заголовка Authorization и отклоняет запросы заблокированных пользователей.
+func AuthMiddleware(logger *zap.Logger, jwtManager *auth.JWTManager, checker LockChecker) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tokenStr := c.GetHeader("Authorization")
 		if tokenStr == "" {
@@ -49,7 +56,24 @@ func AuthMiddleware(logger *zap.Logger, jwtManager *auth.JWTManager) gin.Handler
 			return
 		}
 
-		c.Set(auth.UserIDKey, uint(userIDFloat))
+		userID := uint(userIDFloat)
+
+		locked, err := checker.IsLocked(c.Request.Context(), userID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.NewErrorResponse(models.ErrInternal))
+			return
+		}
+
+		if locked {
+			logger.Info("Locked user tried to access API",
+				zap.Uint("userID", userID),
+				zap.String("path", c.Request.URL.Path))
+			c.AbortWithStatusJSON(http.StatusForbidden, models.ErrorResponse{Errors: models.ErrForbidden})
+
+			return
+		}
+
+		c.Set(auth.UserIDKey, userID)
 		c.Set(auth.UsernameKey, username)
 		c.Next()
 	}
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/maksemen2/avito-shop/internal/repository"
+)
+
+// PrimaryReadsHeader - заголовок, которым клиент просит читать данные с основной базы, а не с реплики.
+// Нужен сразу после записи, например для /api/info после перевода, чтобы не получить отстающие данные.
+const PrimaryReadsHeader = "X-Read-From-Primary"
+
+func PrimaryReadsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader(PrimaryReadsHeader) == "true" {
+			c.Request = c.Request.WithContext(repository.WithPrimaryReads(c.Request.Context()))
+		}
+
+		c.Next()
+	}
+}
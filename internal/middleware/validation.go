@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/legacy"
+	"github.com/gin-gonic/gin"
+	"github.com/maksemen2/avito-shop/internal/models"
+	"go.uber.org/zap"
+)
+
+// ValidationMiddleware проверяет параметры и тело запроса по OpenAPI-документу и отвечает 400 с описанием первой ошибки.
+// Маршруты, которых нет в документе, пропускаются - их обрабатывает gin.
+// Аутентификация здесь не проверяется, это делает AuthMiddleware.
+func ValidationMiddleware(logger *zap.Logger, doc *openapi3.T) gin.HandlerFunc {
+	router, err := legacy.NewRouter(doc)
+	if err != nil {
+		logger.Fatal("Failed to create OpenAPI router", zap.Error(err))
+	}
+
+	options := &openapi3filter.Options{
+		AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+	}
+
+	return func(c *gin.Context) {
+		route, pathParams, err := router.FindRoute(c.Request)
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		// API принимает только JSON, поэтому тело без Content-Type считаем JSON, как и ShouldBindJSON
+		if c.Request.ContentLength != 0 && c.GetHeader("Content-Type") == "" {
+			c.Request.Header.Set("Content-Type", "application/json")
+		}
+
+		err = openapi3filter.ValidateRequest(c.Request.Context(), &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    options,
+		})
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, models.NewDetailedErrorResponse(models.ErrBadRequest, validationDetails(err)))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// validationDetails сокращает ошибку валидации до одной строки: где ошибка и почему.
+// Полный текст ошибок openapi3filter содержит схему и значение и не подходит для ответа клиенту.
+func validationDetails(err error) string {
+	var requestErr *openapi3filter.RequestError
+	if !errors.As(err, &requestErr) {
+		return err.Error()
+	}
+
+	location := "request body"
+	if requestErr.Parameter != nil {
+		location = fmt.Sprintf("%s parameter %s", requestErr.Parameter.In, requestErr.Parameter.Name)
+	}
+
+	var schemaErr *openapi3.SchemaError
+	if errors.As(requestErr.Err, &schemaErr) {
+		if pointer := schemaErr.JSONPointer(); len(pointer) > 0 {
+			return fmt.Sprintf("%s: field %s: %s", location, strings.Join(pointer, "."), schemaErr.Reason)
+		}
+
+		return fmt.Sprintf("%s: %s", location, schemaErr.Reason)
+	}
+
+	if requestErr.Err != nil {
+		return fmt.Sprintf("%s: %s", location, requestErr.Err.Error())
+	}
+
+	return fmt.Sprintf("%s: %s", location, requestErr.Reason)
+}
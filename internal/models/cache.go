@@ -0,0 +1,16 @@
+package models
+
+// Счётчики кеша с момента запуска сервера. Для отключённого кеша Enabled равно false, а счётчики нулевые
+type CacheStats struct {
+	Enabled bool    `json:"enabled"`
+	Hits    uint64  `json:"hits"`
+	Misses  uint64  `json:"misses"`
+	HitRate float64 `json:"hitRate"`
+	Entries int     `json:"entries"`
+}
+
+// Модель для ответа GET /api/admin/cache
+type CacheStatsResponse struct {
+	Goods CacheStats `json:"goods"`
+	Info  CacheStats `json:"info"`
+}
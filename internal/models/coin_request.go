@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Модель для запроса POST /api/coinRequests
+type CreateCoinRequestRequest struct {
+	FromUser string `json:"fromUser"`
+	Amount   int    `json:"amount"`
+}
+
+// Модель запроса монет в ответах /api/coinRequests
+type CoinRequest struct {
+	ID         uint       `json:"id"`
+	Requester  string     `json:"requester"`
+	Payer      string     `json:"payer"`
+	Amount     int        `json:"amount"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	ResolvedAt *time.Time `json:"resolvedAt,omitempty"`
+}
+
+// Модель для ответа GET /api/coinRequests
+type CoinRequestsResponse struct {
+	Incoming []CoinRequest `json:"incoming"`
+	Outgoing []CoinRequest `json:"outgoing"`
+}
@@ -1,6 +1,29 @@
 package models
 
+import "time"
+
 type SendCoinRequest struct {
 	ToUser string `json:"toUser"`
 	Amount int    `json:"amount"`
 }
+
+// Направления перевода в истории переводов
+const (
+	TransferDirectionReceived = "received"
+	TransferDirectionSent     = "sent"
+)
+
+// Перевод монет в постраничной истории. Counterparty - отправитель для входящих переводов и получатель для исходящих.
+type Transfer struct {
+	ID           uint      `json:"id"`
+	Direction    string    `json:"direction"`
+	Counterparty string    `json:"counterparty"`
+	Amount       int       `json:"amount"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// Страница истории переводов
+type TransferHistoryPage struct {
+	Transfers []Transfer `json:"transfers"`
+	Total     int64      `json:"total"`
+}
@@ -5,6 +5,8 @@ import "fmt"
 const (
 	ErrBadRequest   = "bad request"
 	ErrUnauthorized = "unauthorized"
+	ErrForbidden    = "forbidden"
+	ErrNotFound     = "not found"
 	ErrInternal     = "internal server error"
 )
 
@@ -0,0 +1,20 @@
+package models
+
+// Данные события balance в /api/events
+type BalanceEvent struct {
+	Coins int `json:"coins"`
+}
+
+// Данные события transfer в /api/events
+type TransferEvent struct {
+	FromUser string `json:"fromUser"`
+	Amount   int    `json:"amount"`
+}
+
+// Данные события purchase в /api/events. ToUser заполняется для покупок в подарок.
+type PurchaseEvent struct {
+	Item    string `json:"item"`
+	Variant string `json:"variant,omitempty"`
+	Price   int    `json:"price"`
+	ToUser  string `json:"toUser,omitempty"`
+}
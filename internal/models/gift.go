@@ -0,0 +1,23 @@
+package models
+
+// Модель для запроса POST /api/gift
+type GiftRequest struct {
+	ToUser  string `json:"toUser"`
+	Item    string `json:"item"`
+	Variant string `json:"variant,omitempty"`
+}
+
+type ReceivedGift struct {
+	FromUser string `json:"fromUser"`
+	Item     string `json:"item"`
+}
+
+type SentGift struct {
+	ToUser string `json:"toUser"`
+	Item   string `json:"item"`
+}
+
+type GiftHistory struct {
+	Received []ReceivedGift `json:"received"`
+	Sent     []SentGift     `json:"sent"`
+}
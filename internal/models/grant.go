@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Модель для запроса POST /api/admin/grants
+type CreateGrantProgramRequest struct {
+	Name   string `json:"name"`
+	Amount int    `json:"amount"`
+	Period string `json:"period"`
+}
+
+// Модель программы пособий в ответах /api/admin/grants
+type GrantProgram struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Amount    int       `json:"amount"`
+	Period    string    `json:"period"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Модель для ответа POST /api/admin/grants/apply
+type ApplyGrantsResponse struct {
+	Granted int64 `json:"granted"`
+}
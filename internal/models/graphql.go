@@ -0,0 +1,8 @@
+package models
+
+// Модель для запроса /api/graphql
+type GraphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
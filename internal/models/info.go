@@ -1,14 +1,21 @@
 package models
 
+import "time"
+
 // Модель для ответа /api/info
 type InfoResponse struct {
-	Coins       int         `json:"coins"`
-	Inventory   []Item      `json:"inventory"`
-	CoinHistory CoinHistory `json:"coinHistory"`
+	Coins               int               `json:"coins"`
+	Inventory           []Item            `json:"inventory"`
+	CoinHistory         CoinHistory       `json:"coinHistory"`
+	GiftHistory         GiftHistory       `json:"giftHistory"`
+	ItemHistory         ItemHistory       `json:"itemHistory"`
+	UpcomingExpirations []CoinsExpiration `json:"upcomingExpirations"`
 }
 
+// Товар в инвентаре. Для товаров с вариантами каждый вариант учитывается отдельно.
 type Item struct {
 	Type     string `json:"type"`
+	Variant  string `json:"variant,omitempty"`
 	Quantity int    `json:"quantity"`
 }
 
@@ -22,7 +29,28 @@ type SentCoins struct {
 	Amount int    `json:"amount"`
 }
 
+// Начисление монет по программе пособий
+type GrantedCoins struct {
+	Program string `json:"program"`
+	Amount  int    `json:"amount"`
+	Period  string `json:"period"`
+}
+
+// Сгоревшие монеты
+type ExpiredCoins struct {
+	Amount    int       `json:"amount"`
+	ExpiredAt time.Time `json:"expiredAt"`
+}
+
 type CoinHistory struct {
 	Received []ReceivedCoins `json:"received"`
 	Sent     []SentCoins     `json:"sent"`
+	Granted  []GrantedCoins  `json:"granted"`
+	Expired  []ExpiredCoins  `json:"expired"`
+}
+
+// Монеты, которые сгорят в указанный день, если не будут потрачены
+type CoinsExpiration struct {
+	Amount    int       `json:"amount"`
+	ExpiresAt time.Time `json:"expiresAt"`
 }
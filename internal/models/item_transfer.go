@@ -0,0 +1,28 @@
+package models
+
+// Модель для запроса POST /api/sendItem
+type SendItemRequest struct {
+	ToUser   string `json:"toUser"`
+	Item     string `json:"item"`
+	Variant  string `json:"variant,omitempty"`
+	Quantity int    `json:"quantity"`
+}
+
+type ReceivedItems struct {
+	FromUser string `json:"fromUser"`
+	Item     string `json:"item"`
+	Variant  string `json:"variant,omitempty"`
+	Quantity int    `json:"quantity"`
+}
+
+type SentItems struct {
+	ToUser   string `json:"toUser"`
+	Item     string `json:"item"`
+	Variant  string `json:"variant,omitempty"`
+	Quantity int    `json:"quantity"`
+}
+
+type ItemHistory struct {
+	Received []ReceivedItems `json:"received"`
+	Sent     []SentItems     `json:"sent"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Модель для запроса POST /api/market
+type CreateListingRequest struct {
+	Item    string `json:"item"`
+	Variant string `json:"variant,omitempty"`
+	Price   int    `json:"price"`
+}
+
+// Модель объявления в ответах /api/market
+type Listing struct {
+	ID        uint      `json:"id"`
+	Seller    string    `json:"seller"`
+	Item      string    `json:"item"`
+	Variant   string    `json:"variant,omitempty"`
+	Price     int       `json:"price"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// Модель уведомления в ответе GET /api/notifications
+type Notification struct {
+	ID        uint       `json:"id"`
+	Type      string     `json:"type"`
+	Message   string     `json:"message"`
+	Read      bool       `json:"read"`
+	ReadAt    *time.Time `json:"readAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// Модель для ответа GET /api/notifications
+type NotificationsResponse struct {
+	Unread        int64          `json:"unread"`
+	Notifications []Notification `json:"notifications"`
+}
+
+// Модель для ответа POST /api/notifications/read
+type MarkAllReadResponse struct {
+	Marked int64 `json:"marked"`
+}
+
+// Модель для запроса POST /api/admin/notifications.
+// Пустой ToUser отправляет уведомление всем пользователям.
+type AdminNotificationRequest struct {
+	ToUser  string `json:"toUser,omitempty"`
+	Message string `json:"message"`
+}
+
+// Модель для ответа POST /api/admin/notifications
+type AdminNotificationResponse struct {
+	Sent int64 `json:"sent"`
+}
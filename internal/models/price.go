@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// Модель для запроса POST /api/admin/prices
+type CreatePriceScheduleRequest struct {
+	Item            string    `json:"item"`
+	Price           int       `json:"price,omitempty"`
+	DiscountPercent int       `json:"discountPercent,omitempty"`
+	StartsAt        time.Time `json:"startsAt"`
+	EndsAt          time.Time `json:"endsAt"`
+}
+
+// Модель расписания цены в ответах /api/admin/prices
+type PriceSchedule struct {
+	ID              uint      `json:"id"`
+	Item            string    `json:"item"`
+	Price           int       `json:"price,omitempty"`
+	DiscountPercent int       `json:"discountPercent,omitempty"`
+	StartsAt        time.Time `json:"startsAt"`
+	EndsAt          time.Time `json:"endsAt"`
+	CreatedAt       time.Time `json:"createdAt"`
+}
+
+// Модель товара в ответе GET /api/goods.
+// OriginalPrice и SaleEndsAt заполняются, только если сейчас на товар действует особая цена.
+// Variants заполняется только для товаров с вариантами.
+type CatalogItem struct {
+	Type          string           `json:"type"`
+	Price         int              `json:"price"`
+	OriginalPrice *int             `json:"originalPrice,omitempty"`
+	SaleEndsAt    *time.Time       `json:"saleEndsAt,omitempty"`
+	Variants      []CatalogVariant `json:"variants,omitempty"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Модель для запроса POST /api/admin/promos
+type CreatePromoCodeRequest struct {
+	Code           string     `json:"code"`
+	DiscountType   string     `json:"discountType"`
+	DiscountValue  int        `json:"discountValue"`
+	Item           string     `json:"item,omitempty"`
+	MaxUses        int        `json:"maxUses"`
+	MaxUsesPerUser int        `json:"maxUsesPerUser"`
+	ValidFrom      *time.Time `json:"validFrom,omitempty"`
+	ValidUntil     *time.Time `json:"validUntil,omitempty"`
+}
+
+// Модель промокода в ответах /api/admin/promos
+type PromoCode struct {
+	ID             uint       `json:"id"`
+	Code           string     `json:"code"`
+	DiscountType   string     `json:"discountType"`
+	DiscountValue  int        `json:"discountValue"`
+	Item           string     `json:"item,omitempty"`
+	MaxUses        int        `json:"maxUses"`
+	MaxUsesPerUser int        `json:"maxUsesPerUser"`
+	UsedCount      int        `json:"usedCount"`
+	ValidFrom      *time.Time `json:"validFrom,omitempty"`
+	ValidUntil     *time.Time `json:"validUntil,omitempty"`
+	Active         bool       `json:"active"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
@@ -0,0 +1,7 @@
+package models
+
+// Необязательные параметры запроса GET /api/buy/:item
+type BuyOptions struct {
+	Variant string
+	Promo   string
+}
@@ -0,0 +1,29 @@
+package models
+
+// Модель для запроса POST /api/admin/goods/:item/variants
+type CreateVariantRequest struct {
+	Name       string `json:"name"`
+	Stock      int    `json:"stock"`
+	PriceDelta int    `json:"priceDelta"`
+}
+
+// Модель для запроса POST /api/admin/variants/:id/restock
+type RestockVariantRequest struct {
+	Quantity int `json:"quantity"`
+}
+
+// Модель варианта товара в ответах /api/admin/goods/:item/variants
+type Variant struct {
+	ID         uint   `json:"id"`
+	Item       string `json:"item"`
+	Name       string `json:"name"`
+	Stock      int    `json:"stock"`
+	PriceDelta int    `json:"priceDelta"`
+}
+
+// Вариант товара в каталоге с итоговой ценой
+type CatalogVariant struct {
+	Name  string `json:"name"`
+	Price int    `json:"price"`
+	Stock int    `json:"stock"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Модель для запроса POST /api/admin/webhooks. Если Secret не указан, он генерируется.
+type CreateWebhookRequest struct {
+	URL    string `json:"url"`
+	Secret string `json:"secret,omitempty"`
+}
+
+// Модель вебхука в ответах /api/admin/webhooks. Secret возвращается только при создании.
+type Webhook struct {
+	ID        uint      `json:"id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret,omitempty"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Модель доставки события в ответе GET /api/admin/deliveries
+type WebhookDelivery struct {
+	ID            uint       `json:"id"`
+	WebhookID     uint       `json:"webhookId"`
+	EventID       uint       `json:"eventId"`
+	EventType     string     `json:"eventType"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt time.Time  `json:"nextAttemptAt"`
+	LastError     string     `json:"lastError,omitempty"`
+	DeliveredAt   *time.Time `json:"deliveredAt,omitempty"`
+}
+
+// Тело запроса, которое получает вебхук
+type WebhookPayload struct {
+	ID        uint            `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Данные события transfer.created
+type TransferCreatedEvent struct {
+	TransactionID uint   `json:"transactionId"`
+	FromUser      string `json:"fromUser"`
+	ToUser        string `json:"toUser"`
+	Amount        int    `json:"amount"`
+}
+
+// Данные события purchase.created. User - владелец товара, GiftedBy заполняется для подарков.
+type PurchaseCreatedEvent struct {
+	PurchaseID uint   `json:"purchaseId"`
+	User       string `json:"user"`
+	Item       string `json:"item"`
+	Variant    string `json:"variant,omitempty"`
+	Price      int    `json:"price"`
+	GiftedBy   string `json:"giftedBy,omitempty"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import "encoding/json"
+
+// Команды WebSocket API /api/ws
+const (
+	WSCommandSendCoin = "sendCoin"
+	WSCommandBuy      = "buy"
+	WSCommandInfo     = "info"
+)
+
+// Типы сообщений WebSocket API /api/ws
+const (
+	WSMessageResult = "result"
+	WSMessageError  = "error"
+	WSMessageEvent  = "event"
+)
+
+// Команда клиента WebSocket API. ID возвращается в ответе на команду, чтобы клиент мог сопоставить их.
+// Payload для sendCoin имеет формат SendCoinRequest, для buy - WSBuyPayload, для info не нужен.
+type WSCommand struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// Параметры команды buy
+type WSBuyPayload struct {
+	Item    string `json:"item"`
+	Variant string `json:"variant,omitempty"`
+	Promo   string `json:"promo,omitempty"`
+}
+
+// Сообщение сервера WebSocket API: результат или ошибка команды с её ID либо событие с типом Event.
+type WSMessage struct {
+	ID    string `json:"id,omitempty"`
+	Type  string `json:"type"`
+	Event string `json:"event,omitempty"`
+	Data  any    `json:"data,omitempty"`
+	Error string `json:"error,omitempty"`
+}
@@ -0,0 +1,15 @@
+package models
+
+// Модель для запроса POST /api/wishlist
+type AddToWishlistRequest struct {
+	Item string `json:"item"`
+}
+
+// Модель товара в ответе GET /api/wishlist.
+// InStock ложно только для товаров с вариантами, у которых закончились все варианты.
+type WishlistItem struct {
+	Item      string `json:"item"`
+	Price     int    `json:"price"`
+	InStock   bool   `json:"inStock"`
+	CanAfford bool   `json:"canAfford"`
+}
@@ -0,0 +1,180 @@
+// Package openapi строит OpenAPI 3 документ API из описаний маршрутов и моделей пакета models.
+package openapi
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3gen"
+	"github.com/maksemen2/avito-shop/internal/models"
+)
+
+const securitySchemeName = "bearerAuth"
+
+// MustLoad строит OpenAPI-документ API.
+// Документ собирается из кода, поэтому ошибка означает неверное описание маршрута и завершает работу программы.
+func MustLoad() *openapi3.T {
+	doc, err := NewDocument()
+	if err != nil {
+		log.Fatalf("Error building OpenAPI document: %v", err)
+	}
+
+	return doc
+}
+
+// NewDocument строит и проверяет OpenAPI-документ API. Схемы тел запросов и ответов генерируются из моделей.
+func NewDocument() (*openapi3.T, error) {
+	doc := &openapi3.T{
+		OpenAPI: "3.0.3",
+		Info: &openapi3.Info{
+			Title:   "Avito Merch Shop API",
+			Version: "1.0.0",
+		},
+		Paths: openapi3.NewPaths(),
+		Components: &openapi3.Components{
+			Schemas: openapi3.Schemas{},
+			SecuritySchemes: openapi3.SecuritySchemes{
+				securitySchemeName: &openapi3.SecuritySchemeRef{Value: openapi3.NewJWTSecurityScheme()},
+			},
+		},
+	}
+
+	errorSchema, err := newSchemaRef(models.ErrorResponse{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate error schema: %w", err)
+	}
+
+	doc.Components.Schemas["ErrorResponse"] = errorSchema
+
+	for _, op := range operations() {
+		operation, err := newOperation(op, openapi3.NewSchemaRef("#/components/schemas/ErrorResponse", errorSchema.Value))
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", op.method, op.path, err)
+		}
+
+		doc.AddOperation(op.path, op.method, operation)
+	}
+
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document: %w", err)
+	}
+
+	return doc, nil
+}
+
+func newOperation(op operation, errorSchema *openapi3.SchemaRef) (*openapi3.Operation, error) {
+	operation := openapi3.NewOperation()
+	operation.Summary = op.summary
+	operation.Tags = []string{op.tag}
+
+	for _, segment := range strings.Split(op.path, "/") {
+		if !strings.HasPrefix(segment, "{") {
+			continue
+		}
+
+		name := strings.Trim(segment, "{}")
+		schema := openapi3.NewStringSchema()
+
+		if name == "id" {
+			schema = openapi3.NewInt64Schema().WithMin(1)
+		}
+
+		operation.AddParameter(openapi3.NewPathParameter(name).WithSchema(schema))
+	}
+
+	for _, param := range op.query {
+		schema := openapi3.NewStringSchema()
+		if param.boolean {
+			schema = openapi3.NewBoolSchema()
+		}
+
+		operation.AddParameter(openapi3.NewQueryParameter(param.name).WithDescription(param.description).WithSchema(schema))
+	}
+
+	if op.request != nil {
+		schema, err := newSchemaRef(op.request)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate request schema: %w", err)
+		}
+
+		operation.RequestBody = &openapi3.RequestBodyRef{
+			Value: openapi3.NewRequestBody().WithRequired(true).WithJSONSchemaRef(schema),
+		}
+	}
+
+	success, err := successResponse(op.response)
+	if err != nil {
+		return nil, err
+	}
+
+	operation.AddResponse(success.status, success.response)
+
+	errorCodes := []int{http.StatusBadRequest, http.StatusInternalServerError}
+
+	if op.access != public {
+		operation.Security = openapi3.NewSecurityRequirements().With(openapi3.NewSecurityRequirement().Authenticate(securitySchemeName))
+		errorCodes = append(errorCodes, http.StatusUnauthorized)
+	}
+
+	if op.access == admin {
+		errorCodes = append(errorCodes, http.StatusForbidden)
+	}
+
+	if strings.Contains(op.path, "{") {
+		errorCodes = append(errorCodes, http.StatusNotFound)
+	}
+
+	for _, code := range errorCodes {
+		operation.AddResponse(code, openapi3.NewResponse().
+			WithDescription(http.StatusText(code)).
+			WithJSONSchemaRef(errorSchema))
+	}
+
+	return operation, nil
+}
+
+// newSchemaRef генерирует схему модели. Пустые срезы и словари encoding/json кодирует как null,
+// поэтому такие поля допускают null.
+func newSchemaRef(value any) (*openapi3.SchemaRef, error) {
+	return openapi3gen.NewSchemaRefForValue(value, nil, openapi3gen.SchemaCustomizer(
+		func(_ string, t reflect.Type, _ reflect.StructTag, schema *openapi3.Schema) error {
+			if t.Kind() == reflect.Slice || t.Kind() == reflect.Map {
+				schema.Nullable = true
+			}
+
+			return nil
+		},
+	))
+}
+
+type statusResponse struct {
+	status   int
+	response *openapi3.Response
+}
+
+func successResponse(response any) (statusResponse, error) {
+	switch response.(type) {
+	case nil:
+		return statusResponse{http.StatusOK, openapi3.NewResponse().WithDescription("OK")}, nil
+	case anyJSON:
+		return statusResponse{http.StatusOK, openapi3.NewResponse().WithDescription("OK").WithJSONSchema(openapi3.NewObjectSchema())}, nil
+	case eventStream:
+		return statusResponse{http.StatusOK, openapi3.NewResponse().
+			WithDescription("Поток событий balance, transfer и purchase").
+			WithContent(openapi3.NewContentWithSchema(openapi3.NewStringSchema(), []string{"text/event-stream"}))}, nil
+	case webSocket:
+		return statusResponse{http.StatusSwitchingProtocols, openapi3.NewResponse().WithDescription("Соединение переключено на WebSocket")}, nil
+	}
+
+	schema, err := newSchemaRef(response)
+	if err != nil {
+		return statusResponse{}, fmt.Errorf("failed to generate response schema: %w", err)
+	}
+
+	return statusResponse{http.StatusOK, openapi3.NewResponse().WithDescription("OK").WithJSONSchemaRef(schema)}, nil
+}
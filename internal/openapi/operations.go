@@ -0,0 +1,155 @@
+package openapi
+
+import (
+	"net/http"
+
+	"github.com/maksemen2/avito-shop/internal/models"
+)
+
+// access - кто может вызывать маршрут.
+type access int
+
+const (
+	public access = iota
+	authorized
+	admin
+)
+
+// Особые типы ответов, которые не описываются JSON-схемой модели.
+type (
+	eventStream struct{}
+	webSocket   struct{}
+	anyJSON     struct{}
+)
+
+type queryParam struct {
+	name        string
+	description string
+	boolean     bool
+}
+
+// operation описывает маршрут из routes.SetupRoutes. Параметры пути вида {id} считаются целыми числами,
+// остальные - строками. Пустой request означает запрос без тела, пустой response - ответ 200 без тела.
+type operation struct {
+	method   string
+	path     string
+	tag      string
+	summary  string
+	access   access
+	query    []queryParam
+	request  any
+	response any
+}
+
+// operations перечисляет все маршруты API. Тест в handlers проверяет, что список совпадает с маршрутами gin.
+func operations() []operation {
+	return []operation{
+		{method: http.MethodGet, path: "/api/openapi.json", tag: "meta", summary: "OpenAPI-документ API",
+			access: public, response: anyJSON{}},
+		{method: http.MethodPost, path: "/api/auth", tag: "auth", summary: "Вход или регистрация, возвращает JWT-токен",
+			access: public, request: models.AuthRequest{}, response: models.AuthResponse{}},
+
+		{method: http.MethodGet, path: "/api/info", tag: "coins", summary: "Баланс, инвентарь и история операций",
+			access: authorized, response: models.InfoResponse{}},
+		{method: http.MethodGet, path: "/api/goods", tag: "shop", summary: "Каталог товаров с действующими ценами",
+			access: authorized, response: []models.CatalogItem{}},
+		{method: http.MethodGet, path: "/api/buy/{item}", tag: "shop", summary: "Покупка товара",
+			access: authorized, query: []queryParam{
+				{name: "variant", description: "Вариант товара, обязателен для товаров с вариантами"},
+				{name: "promo", description: "Промокод"},
+			}},
+		{method: http.MethodPost, path: "/api/gift", tag: "shop", summary: "Покупка товара в подарок другому пользователю",
+			access: authorized, request: models.GiftRequest{}},
+		{method: http.MethodPost, path: "/api/sendCoin", tag: "coins", summary: "Перевод монет",
+			access: authorized, request: models.SendCoinRequest{}},
+		{method: http.MethodPost, path: "/api/sendItem", tag: "shop", summary: "Передача товаров из инвентаря",
+			access: authorized, request: models.SendItemRequest{}},
+		{method: http.MethodGet, path: "/api/coinRequests", tag: "coins", summary: "Входящие и исходящие запросы монет",
+			access: authorized, query: []queryParam{{name: "status", description: "Фильтр по статусу запроса"}},
+			response: models.CoinRequestsResponse{}},
+		{method: http.MethodPost, path: "/api/coinRequests", tag: "coins", summary: "Запрос монет у другого пользователя",
+			access: authorized, request: models.CreateCoinRequestRequest{}, response: models.CoinRequest{}},
+		{method: http.MethodPost, path: "/api/coinRequests/{id}/accept", tag: "coins", summary: "Оплата запроса монет",
+			access: authorized},
+		{method: http.MethodPost, path: "/api/coinRequests/{id}/decline", tag: "coins", summary: "Отклонение запроса монет",
+			access: authorized},
+		{method: http.MethodGet, path: "/api/market", tag: "market", summary: "Активные объявления",
+			access: authorized, query: []queryParam{
+				{name: "item", description: "Фильтр по товару"},
+				{name: "seller", description: "Фильтр по продавцу"},
+			}, response: []models.Listing{}},
+		{method: http.MethodPost, path: "/api/market", tag: "market", summary: "Выставление товара на продажу",
+			access: authorized, request: models.CreateListingRequest{}, response: models.Listing{}},
+		{method: http.MethodPost, path: "/api/market/{id}/buy", tag: "market", summary: "Покупка по объявлению",
+			access: authorized},
+		{method: http.MethodPost, path: "/api/market/{id}/cancel", tag: "market", summary: "Снятие объявления",
+			access: authorized},
+		{method: http.MethodGet, path: "/api/wishlist", tag: "wishlist", summary: "Список желаемого",
+			access: authorized, response: []models.WishlistItem{}},
+		{method: http.MethodPost, path: "/api/wishlist", tag: "wishlist", summary: "Добавление товара в список желаемого",
+			access: authorized, request: models.AddToWishlistRequest{}},
+		{method: http.MethodDelete, path: "/api/wishlist/{item}", tag: "wishlist", summary: "Удаление товара из списка желаемого",
+			access: authorized},
+		{method: http.MethodGet, path: "/api/notifications", tag: "notifications", summary: "Уведомления пользователя",
+			access: authorized, query: []queryParam{{name: "unread", description: "Только непрочитанные", boolean: true}},
+			response: models.NotificationsResponse{}},
+		{method: http.MethodPost, path: "/api/notifications/read", tag: "notifications", summary: "Отметка всех уведомлений прочитанными",
+			access: authorized, response: models.MarkAllReadResponse{}},
+		{method: http.MethodPost, path: "/api/notifications/{id}/read", tag: "notifications", summary: "Отметка уведомления прочитанным",
+			access: authorized},
+		{method: http.MethodGet, path: "/api/events", tag: "events", summary: "Поток Server-Sent Events текущего пользователя",
+			access: authorized, response: eventStream{}},
+		{method: http.MethodGet, path: "/api/ws", tag: "events", summary: "WebSocket для команд и событий",
+			access: authorized, response: webSocket{}},
+		{method: http.MethodPost, path: "/api/graphql", tag: "graphql", summary: "GraphQL-запрос",
+			access: authorized, request: models.GraphQLRequest{}, response: anyJSON{}},
+
+		{method: http.MethodGet, path: "/api/admin/grants", tag: "admin", summary: "Программы пособий",
+			access: admin, response: []models.GrantProgram{}},
+		{method: http.MethodPost, path: "/api/admin/grants", tag: "admin", summary: "Создание программы пособий",
+			access: admin, request: models.CreateGrantProgramRequest{}, response: models.GrantProgram{}},
+		{method: http.MethodPost, path: "/api/admin/grants/apply", tag: "admin", summary: "Начисление пособий вне расписания",
+			access: admin, response: models.ApplyGrantsResponse{}},
+		{method: http.MethodPost, path: "/api/admin/grants/{id}/activate", tag: "admin", summary: "Включение программы пособий",
+			access: admin},
+		{method: http.MethodPost, path: "/api/admin/grants/{id}/deactivate", tag: "admin", summary: "Отключение программы пособий",
+			access: admin},
+		{method: http.MethodGet, path: "/api/admin/promos", tag: "admin", summary: "Промокоды",
+			access: admin, response: []models.PromoCode{}},
+		{method: http.MethodPost, path: "/api/admin/promos", tag: "admin", summary: "Создание промокода",
+			access: admin, request: models.CreatePromoCodeRequest{}, response: models.PromoCode{}},
+		{method: http.MethodPost, path: "/api/admin/promos/{id}/activate", tag: "admin", summary: "Включение промокода",
+			access: admin},
+		{method: http.MethodPost, path: "/api/admin/promos/{id}/deactivate", tag: "admin", summary: "Отключение промокода",
+			access: admin},
+		{method: http.MethodGet, path: "/api/admin/prices", tag: "admin", summary: "Расписания цен",
+			access: admin, response: []models.PriceSchedule{}},
+		{method: http.MethodPost, path: "/api/admin/prices", tag: "admin", summary: "Создание расписания цены",
+			access: admin, request: models.CreatePriceScheduleRequest{}, response: models.PriceSchedule{}},
+		{method: http.MethodDelete, path: "/api/admin/prices/{id}", tag: "admin", summary: "Удаление расписания цены",
+			access: admin},
+		{method: http.MethodGet, path: "/api/admin/goods/{item}/variants", tag: "admin", summary: "Варианты товара",
+			access: admin, response: []models.Variant{}},
+		{method: http.MethodPost, path: "/api/admin/goods/{item}/variants", tag: "admin", summary: "Создание варианта товара",
+			access: admin, request: models.CreateVariantRequest{}, response: models.Variant{}},
+		{method: http.MethodPost, path: "/api/admin/variants/{id}/restock", tag: "admin", summary: "Пополнение остатка варианта",
+			access: admin, request: models.RestockVariantRequest{}, response: models.Variant{}},
+		{method: http.MethodPost, path: "/api/admin/notifications", tag: "admin", summary: "Сообщение пользователю или всем пользователям",
+			access: admin, request: models.AdminNotificationRequest{}, response: models.AdminNotificationResponse{}},
+		{method: http.MethodGet, path: "/api/admin/webhooks", tag: "admin", summary: "Вебхуки",
+			access: admin, response: []models.Webhook{}},
+		{method: http.MethodPost, path: "/api/admin/webhooks", tag: "admin", summary: "Создание вебхука",
+			access: admin, request: models.CreateWebhookRequest{}, response: models.Webhook{}},
+		{method: http.MethodPost, path: "/api/admin/webhooks/{id}/activate", tag: "admin", summary: "Включение вебхука",
+			access: admin},
+		{method: http.MethodPost, path: "/api/admin/webhooks/{id}/deactivate", tag: "admin", summary: "Отключение вебхука",
+			access: admin},
+		{method: http.MethodGet, path: "/api/admin/deliveries", tag: "admin", summary: "Доставки вебхуков",
+			access: admin, query: []queryParam{{name: "status", description: "Фильтр по статусу доставки"}},
+			response: []models.WebhookDelivery{}},
+		{method: http.MethodPost, path: "/api/admin/deliveries/{id}/retry", tag: "admin", summary: "Повтор доставки из dead-letter",
+			access: admin},
+		{method: http.MethodGet, path: "/api/admin/cache", tag: "admin", summary: "Счётчики попаданий и промахов кешей",
+			access: admin, response: models.CacheStatsResponse{}},
+	}
+}
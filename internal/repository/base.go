@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -10,8 +11,13 @@ import (
 
 // BaseRepository содержит общие зависимости и утилиты для всех репозиториев.
 type BaseRepository struct {
-	db     *gorm.DB
-	Logger *zap.Logger
+	db       *gorm.DB
+	replicas *readReplicas
+	// coinLifetime - срок жизни новых партий монет, нулевой срок означает, что партии не сгорают
+	coinLifetime time.Duration
+	// onUserChange получает изменения, зафиксированные репозиторием, см. WithUserChangeHook
+	onUserChange func(ctx context.Context, change UserChange)
+	Logger       *zap.Logger
 }
 
 // WithTransaction выполняет переданную функцию в контексте транзакции.
@@ -27,3 +33,32 @@ func WrapError(context string, err error) error {
 func (r *BaseRepository) DB(ctx context.Context) *gorm.DB {
 	return r.db.WithContext(ctx)
 }
+
+// ReadDB возвращает подключение для чтений, допускающих отставание: одну из реплик, если они настроены
+// и контекст не помечен WithPrimaryReads, иначе основную базу.
+func (r *BaseRepository) ReadDB(ctx context.Context) *gorm.DB {
+	if r.replicas == nil || PrimaryReads(ctx) {
+		return r.DB(ctx)
+	}
+
+	return r.replicas.pick().WithContext(ctx)
+}
+
+func (r *BaseRepository) setReplicas(replicas *readReplicas) {
+	r.replicas = replicas
+}
+
+func (r *BaseRepository) setCoinLifetime(lifetime time.Duration) {
+	r.coinLifetime = lifetime
+}
+
+// coinLotExpiry возвращает момент сгорания партии монет, созданной в момент createdAt, или nil, если партии не сгорают.
+func (r *BaseRepository) coinLotExpiry(createdAt time.Time) *time.Time {
+	if r.coinLifetime <= 0 {
+		return nil
+	}
+
+	expiresAt := createdAt.Add(r.coinLifetime)
+
+	return &expiresAt
+}
@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/maksemen2/avito-shop/internal/database"
+)
+
+// CacheStats - счётчики попаданий и промахов кеша.
+type CacheStats struct {
+	Hits    uint64
+	Misses  uint64
+	Entries int
+}
+
+type cachedGood struct {
+	good      database.Good
+	expiresAt time.Time
+}
+
+// CachedGoodRepository кеширует чтения каталога товаров поверх другого GoodRepository.
+// Записи живут ttl, а изменения через SetPrice сбрасывают кеш целиком - каталог небольшой.
+// Изменения, сделанные другим процессом (например, shopctl), становятся видны не позже чем через ttl.
+// Ошибки, включая ErrGoodNotFound, не кешируются.
+type CachedGoodRepository struct {
+	inner GoodRepository
+	ttl   time.Duration
+
+	mu sync.RWMutex
+	// generation растёт при каждом сбросе, чтобы чтение, начатое до сброса, не вернуло в кеш старые данные
+	generation uint64
+	byName     map[string]cachedGood
+	byID       map[uint]cachedGood
+	all        []database.Good
+	allExpiry  time.Time
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+func NewCachedGoodRepository(inner GoodRepository, ttl time.Duration) *CachedGoodRepository {
+	return &CachedGoodRepository{
+		inner:  inner,
+		ttl:    ttl,
+		byName: make(map[string]cachedGood),
+		byID:   make(map[uint]cachedGood),
+	}
+}
+
+func (r *CachedGoodRepository) GetByName(ctx context.Context, name string) (*database.Good, error) {
+	r.mu.RLock()
+	entry, ok := r.byName[name]
+	generation := r.generation
+	r.mu.RUnlock()
+
+	if ok && time.Now().Before(entry.expiresAt) {
+		r.hits.Add(1)
+		return &entry.good, nil
+	}
+
+	r.misses.Add(1)
+
+	good, err := r.inner.GetByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	r.store(good, generation)
+
+	return good, nil
+}
+
+func (r *CachedGoodRepository) GetByID(ctx context.Context, id uint) (*database.Good, error) {
+	r.mu.RLock()
+	entry, ok := r.byID[id]
+	generation := r.generation
+	r.mu.RUnlock()
+
+	if ok && time.Now().Before(entry.expiresAt) {
+		r.hits.Add(1)
+		return &entry.good, nil
+	}
+
+	r.misses.Add(1)
+
+	good, err := r.inner.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	r.store(good, generation)
+
+	return good, nil
+}
+
+func (r *CachedGoodRepository) GetAll(ctx context.Context) ([]database.Good, error) {
+	r.mu.RLock()
+	all, expiresAt, generation := r.all, r.allExpiry, r.generation
+	r.mu.RUnlock()
+
+	if all != nil && time.Now().Before(expiresAt) {
+		r.hits.Add(1)
+		return append([]database.Good(nil), all...), nil
+	}
+
+	r.misses.Add(1)
+
+	goods, err := r.inner.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt = time.Now().Add(r.ttl)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if generation != r.generation {
+		return goods, nil
+	}
+
+	r.all = append(make([]database.Good, 0, len(goods)), goods...)
+	r.allExpiry = expiresAt
+
+	for _, good := range goods {
+		r.byName[good.Type] = cachedGood{good: good, expiresAt: expiresAt}
+		r.byID[good.ID] = cachedGood{good: good, expiresAt: expiresAt}
+	}
+
+	return goods, nil
+}
+
+// SetPrice меняет цену через исходный репозиторий и сбрасывает кеш.
+func (r *CachedGoodRepository) SetPrice(ctx context.Context, name string, price int) error {
+	if err := r.inner.SetPrice(ctx, name, price); err != nil {
+		return err
+	}
+
+	r.Invalidate()
+
+	return nil
+}
+
+// Invalidate сбрасывает все закешированные товары. Счётчики попаданий и промахов сохраняются.
+func (r *CachedGoodRepository) Invalidate() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.byName = make(map[string]cachedGood)
+	r.byID = make(map[uint]cachedGood)
+	r.all = nil
+	r.generation++
+}
+
+// Stats возвращает счётчики кеша с момента запуска.
+func (r *CachedGoodRepository) Stats() CacheStats {
+	r.mu.RLock()
+	entries := len(r.byID)
+	r.mu.RUnlock()
+
+	return CacheStats{
+		Hits:    r.hits.Load(),
+		Misses:  r.misses.Load(),
+		Entries: entries,
+	}
+}
+
+// store кладёт копию товара в кеш, чтобы изменения вызывающего кода не портили закешированное значение.
+func (r *CachedGoodRepository) store(good *database.Good, generation uint64) {
+	entry := cachedGood{good: *good, expiresAt: time.Now().Add(r.ttl)}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if generation != r.generation {
+		return
+	}
+
+	r.byName[good.Type] = entry
+	r.byID[good.ID] = entry
+}
@@ -0,0 +1,79 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachedGoodRepository(t *testing.T) {
+	inner, db := setupTestRepository(t)
+	ctx := context.Background()
+
+	if err := db.Create(&database.Good{Type: "cup", Price: 20}).Error; err != nil {
+		t.Fatalf("failed to create good: %v", err)
+	}
+
+	repo := repository.NewCachedGoodRepository(inner, time.Hour)
+
+	good, err := repo.GetByName(ctx, "cup")
+	assert.NoError(t, err)
+	assert.Equal(t, 20, good.Price)
+
+	// Изменение в обход кеша не видно до сброса, а правка возвращённого значения не портит кеш
+	assert.NoError(t, db.Model(&database.Good{}).Where("type = ?", "cup").UpdateColumn("price", 25).Error)
+	good.Price = 1
+
+	good, err = repo.GetByName(ctx, "cup")
+	assert.NoError(t, err)
+	assert.Equal(t, 20, good.Price)
+
+	good, err = repo.GetByID(ctx, good.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 20, good.Price, "lookup by name should fill the cache by id too")
+
+	_, err = repo.GetByName(ctx, "mug")
+	assert.ErrorIs(t, err, repository.ErrGoodNotFound)
+
+	assert.Equal(t, repository.CacheStats{Hits: 2, Misses: 2, Entries: 1}, repo.Stats())
+
+	assert.NoError(t, repo.SetPrice(ctx, "cup", 30))
+
+	good, err = repo.GetByName(ctx, "cup")
+	assert.NoError(t, err)
+	assert.Equal(t, 30, good.Price, "SetPrice should invalidate the cache")
+
+	goods, err := repo.GetAll(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, goods, 1)
+
+	_, err = repo.GetAll(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, repository.CacheStats{Hits: 3, Misses: 4, Entries: 1}, repo.Stats())
+}
+
+func TestCachedGoodRepository_TTL(t *testing.T) {
+	inner, db := setupTestRepository(t)
+	ctx := context.Background()
+
+	if err := db.Create(&database.Good{Type: "cup", Price: 20}).Error; err != nil {
+		t.Fatalf("failed to create good: %v", err)
+	}
+
+	repo := repository.NewCachedGoodRepository(inner, 50*time.Millisecond)
+
+	_, err := repo.GetByName(ctx, "cup")
+	assert.NoError(t, err)
+
+	assert.NoError(t, db.Model(&database.Good{}).Where("type = ?", "cup").UpdateColumn("price", 25).Error)
+	time.Sleep(60 * time.Millisecond)
+
+	good, err := repo.GetByName(ctx, "cup")
+	assert.NoError(t, err)
+	assert.Equal(t, 25, good.Price, "expired entry should be reloaded")
+	assert.Equal(t, uint64(2), repo.Stats().Misses)
+}
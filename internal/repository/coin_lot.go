@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// CoinLotRepository описывает операции с партиями монет и их сгоранием.
+type CoinLotRepository interface {
+	GetActiveLots(ctx context.Context, userID uint) ([]database.CoinLot, error)
+	ExpireLots(ctx context.Context, now time.Time) (int, error)
+	GetExpirationHistory(ctx context.Context, userID uint) ([]models.ExpiredCoins, error)
+}
+
+// GormCoinLotRepository реализует CoinLotRepository.
+type GormCoinLotRepository struct {
+	BaseRepository
+}
+
+func NewCoinLotRepository(db *gorm.DB, logger *zap.Logger) CoinLotRepository {
+	return &GormCoinLotRepository{
+		BaseRepository: BaseRepository{
+			db:     db,
+			Logger: logger,
+		},
+	}
+}
+
+// GetActiveLots возвращает партии пользователя с непотраченным остатком от старых к новым.
+func (r *GormCoinLotRepository) GetActiveLots(ctx context.Context, userID uint) ([]database.CoinLot, error) {
+	var lots []database.CoinLot
+	if err := r.ReadDB(ctx).
+		Where("user_id = ? AND remaining > 0", userID).
+		Order("created_at ASC, id ASC").
+		Find(&lots).Error; err != nil {
+		r.Logger.Error("failed to get coin lots", zap.Uint("userID", userID), zap.Error(err))
+		return nil, WrapError(ErrGetCoinLots.Error(), err)
+	}
+
+	return lots, nil
+}
+
+// ExpireLots сжигает остатки всех партий, срок жизни которых истёк к моменту now, и списывает их с баланса владельцев.
+// Для каждого пользователя создаётся одна запись истории на суммарно сгоревшие монеты.
+// Возвращает количество пользователей, у которых сгорели монеты.
+func (r *GormCoinLotRepository) ExpireLots(ctx context.Context, now time.Time) (int, error) {
+	var userIDs []uint
+	if err := r.DB(ctx).Model(&database.CoinLot{}).
+		Distinct("user_id").
+		Where("expires_at < ? AND remaining > 0", now).
+		Pluck("user_id", &userIDs).Error; err != nil {
+		r.Logger.Error("failed to get expired coin lots", zap.Error(err))
+		return 0, WrapError(ErrExpireCoins.Error(), err)
+	}
+
+	expired := 0
+
+	// Каждый пользователь обрабатывается в отдельной транзакции, чтобы не держать блокировки на всех балансах сразу
+	for _, userID := range userIDs {
+		var total int
+
+		err := r.DB(ctx).Transaction(func(tx *gorm.DB) error {
+			// Блокируем строку пользователя до чтения партий: списания монет начинаются с изменения той же строки,
+			// поэтому до конца транзакции остатки партий этого пользователя никто не изменит
+			if err := tx.Model(&database.User{}).
+				Where("id = ?", userID).
+				UpdateColumn("coins", gorm.Expr("coins")).Error; err != nil {
+				return err
+			}
+
+			var lots []database.CoinLot
+			if err := tx.Where("user_id = ? AND expires_at < ? AND remaining > 0", userID, now).
+				Find(&lots).Error; err != nil {
+				return err
+			}
+
+			for _, lot := range lots {
+				total += lot.Remaining
+			}
+
+			if total == 0 {
+				return nil
+			}
+
+			if err := tx.Model(&database.CoinLot{}).
+				Where("user_id = ? AND expires_at < ? AND remaining > 0", userID, now).
+				UpdateColumn("remaining", 0).Error; err != nil {
+				return err
+			}
+
+			if err := tx.Model(&database.User{}).
+				Where("id = ?", userID).
+				UpdateColumn("coins", gorm.Expr("coins - ?", total)).Error; err != nil {
+				return err
+			}
+
+			return tx.Create(&database.CoinExpiration{UserID: userID, Amount: total}).Error
+		})
+
+		if total > 0 {
+			err = r.usersChanged(ctx, err, usersOnly(userID))
+		}
+
+		if err != nil {
+			r.Logger.Error("failed to expire coins", zap.Uint("userID", userID), zap.Error(err))
+			return expired, WrapError(ErrExpireCoins.Error(), err)
+		}
+
+		if total > 0 {
+			expired++
+		}
+	}
+
+	return expired, nil
+}
+
+func (r *GormCoinLotRepository) GetExpirationHistory(ctx context.Context, userID uint) ([]models.ExpiredCoins, error) {
+	var history []models.ExpiredCoins
+
+	if err := r.ReadDB(ctx).Model(&database.CoinExpiration{}).
+		Select("amount, created_at as expired_at").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Scan(&history).Error; err != nil {
+		r.Logger.Error("failed to get expired coins", zap.Uint("userID", userID), zap.Error(err))
+		return nil, WrapError(ErrGetHistory.Error(), err)
+	}
+
+	if history == nil {
+		return []models.ExpiredCoins{}, nil
+	}
+
+	return history, nil
+}
+
+// addCoinLotTx создаёт партию монет пользователя со сроком сгорания expiresAt в рамках транзакции tx.
+func addCoinLotTx(tx *gorm.DB, userID uint, source string, amount int, expiresAt *time.Time) error {
+	return tx.Create(&database.CoinLot{
+		UserID:    userID,
+		Source:    source,
+		Amount:    amount,
+		Remaining: amount,
+		ExpiresAt: expiresAt,
+	}).Error
+}
+
+// consumeCoinLotsTx расходует amount монет из партий пользователя от старых к новым в рамках транзакции tx.
+// Монеты, начисленные до появления партий, не сгорают и расходуются только после всех партий.
+// Должна вызываться после списания баланса: изменение строки пользователя блокирует её до конца транзакции,
+// поэтому остатки партий не могут измениться конкурентно.
+func consumeCoinLotsTx(tx *gorm.DB, userID uint, amount int) error {
+	var lots []database.CoinLot
+	if err := tx.Where("user_id = ? AND remaining > 0", userID).
+		Order("created_at ASC, id ASC").
+		Find(&lots).Error; err != nil {
+		return err
+	}
+
+	for _, lot := range lots {
+		if amount == 0 {
+			break
+		}
+
+		take := min(lot.Remaining, amount)
+
+		if err := tx.Model(&database.CoinLot{}).
+			Where("id = ?", lot.ID).
+			UpdateColumn("remaining", gorm.Expr("remaining - ?", take)).Error; err != nil {
+			return err
+		}
+
+		amount -= take
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+func setupTestCoinLotRepository(t *testing.T) (repository.HolderRepository, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: gormLogger.Default.LogMode(gormLogger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open in-memory database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&database.User{}, &database.Purchase{}, &database.Transaction{}, &database.Good{}, &database.CoinLot{}, &database.CoinExpiration{}, &database.Notification{}, &database.OutboxEvent{}, &database.Webhook{}, &database.WebhookDelivery{}); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	logger := zap.NewNop()
+	holderRepo := repository.NewHolderRepository(db, logger)
+
+	return holderRepo, db
+}
+
+func TestTransferCoins_ConsumesOldestLots(t *testing.T) {
+	holderRepo, db := setupTestCoinLotRepository(t)
+	ctx := context.Background()
+
+	sender, err := holderRepo.User().Create(ctx, "sender", "hash")
+	assert.NoError(t, err, "failed to create sender")
+
+	receiver, err := holderRepo.User().Create(ctx, "receiver", "hash")
+	assert.NoError(t, err, "failed to create receiver")
+
+	// Более свежая партия отправителя должна остаться нетронутой
+	assert.NoError(t, db.Create(&database.CoinLot{UserID: sender.ID, Source: database.CoinSourceGrant, Amount: 200, Remaining: 200}).Error)
+	assert.NoError(t, db.Model(&database.User{}).Where("id = ?", sender.ID).Update("coins", 1200).Error)
+
+	assert.NoError(t, holderRepo.TransferCoins(ctx, sender.ID, receiver.ID, 1100, 0), "expected successful transfer")
+
+	senderLots, err := holderRepo.CoinLot().GetActiveLots(ctx, sender.ID)
+	assert.NoError(t, err, "failed to get sender lots")
+	assert.Len(t, senderLots, 1, "expected initial lot to be fully consumed")
+	assert.Equal(t, database.CoinSourceGrant, senderLots[0].Source, "expected newer lot to remain")
+	assert.Equal(t, 100, senderLots[0].Remaining, "expected newer lot to be partially consumed")
+
+	receiverLots, err := holderRepo.CoinLot().GetActiveLots(ctx, receiver.ID)
+	assert.NoError(t, err, "failed to get receiver lots")
+	assert.Len(t, receiverLots, 2, "expected receiver to get a new lot")
+	assert.Equal(t, 1100, receiverLots[1].Remaining, "unexpected received lot size")
+}
+
+func TestExpireLots(t *testing.T) {
+	holderRepo, db := setupTestCoinLotRepository(t)
+	ctx := context.Background()
+
+	user := database.User{Username: "user", Coins: 300}
+	assert.NoError(t, db.Create(&user).Error, "failed to create user")
+
+	now := time.Now()
+	yesterday, tomorrow := now.Add(-24*time.Hour), now.Add(24*time.Hour)
+
+	assert.NoError(t, db.Create(&database.CoinLot{UserID: user.ID, Source: database.CoinSourceInitial, Amount: 100, Remaining: 40, ExpiresAt: &yesterday, CreatedAt: now.Add(-48 * time.Hour)}).Error)
+	assert.NoError(t, db.Create(&database.CoinLot{UserID: user.ID, Source: database.CoinSourceGrant, Amount: 200, Remaining: 200, ExpiresAt: &tomorrow, CreatedAt: now}).Error)
+
+	expired, err := holderRepo.CoinLot().ExpireLots(ctx, now)
+	assert.NoError(t, err, "expected successful expiry")
+	assert.Equal(t, 1, expired, "expected one user with expired coins")
+
+	// Повторный запуск ничего не сжигает
+	expired, err = holderRepo.CoinLot().ExpireLots(ctx, now)
+	assert.NoError(t, err, "expected successful repeated expiry")
+	assert.Equal(t, 0, expired, "expected nothing to expire twice")
+
+	balance, err := holderRepo.User().GetBalance(ctx, user.ID)
+	assert.NoError(t, err, "failed to get balance")
+	assert.Equal(t, 260, balance, "expected remaining coins of old lot to expire")
+
+	history, err := holderRepo.CoinLot().GetExpirationHistory(ctx, user.ID)
+	assert.NoError(t, err, "failed to get expiration history")
+	assert.Len(t, history, 1, "expected one expiration entry")
+	assert.Equal(t, 40, history[0].Amount, "unexpected expired amount")
+}
+
+func TestCoinLots_ExpiryFixedAtCreation(t *testing.T) {
+	_, db := setupTestCoinLotRepository(t)
+	ctx := context.Background()
+
+	holderRepo := repository.NewHolderRepository(db, zap.NewNop(), repository.WithCoinLifetime(24*time.Hour))
+
+	user, err := holderRepo.User().Create(ctx, "user", "hash")
+	assert.NoError(t, err, "failed to create user")
+
+	// Репозиторий с другим сроком жизни не меняет срок уже начисленных монет
+	otherRepo := repository.NewHolderRepository(db, zap.NewNop(), repository.WithCoinLifetime(48*time.Hour))
+	assert.NoError(t, otherRepo.AdjustCoins(ctx, user.ID, 100, ""), "expected successful adjustment")
+
+	lots, err := holderRepo.CoinLot().GetActiveLots(ctx, user.ID)
+	assert.NoError(t, err, "failed to get lots")
+	assert.Len(t, lots, 2, "expected initial and admin lots")
+
+	for i, lifetime := range []time.Duration{24 * time.Hour, 48 * time.Hour} {
+		if assert.NotNil(t, lots[i].ExpiresAt, "expected lot expiry to be stored") {
+			assert.WithinDuration(t, lots[i].CreatedAt.Add(lifetime), *lots[i].ExpiresAt, time.Second)
+		}
+	}
+
+	expired, err := holderRepo.CoinLot().ExpireLots(ctx, time.Now().Add(36*time.Hour))
+	assert.NoError(t, err, "expected successful expiry")
+	assert.Equal(t, 1, expired)
+
+	balance, err := holderRepo.User().GetBalance(ctx, user.ID)
+	assert.NoError(t, err, "failed to get balance")
+	assert.Equal(t, 100, balance, "expected only the initial lot to expire")
+}
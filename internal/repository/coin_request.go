@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// CoinRequestRepository описывает операции для работы с запросами монет.
+type CoinRequestRepository interface {
+	Create(ctx context.Context, requesterID, payerID uint, amount int) (*database.CoinRequest, error)
+	GetByID(ctx context.Context, id uint) (*database.CoinRequest, error)
+	Decline(ctx context.Context, id, payerID uint) error
+	GetByUserID(ctx context.Context, userID uint, status string) (models.CoinRequestsResponse, error)
+}
+
+// GormCoinRequestRepository реализует CoinRequestRepository.
+type GormCoinRequestRepository struct {
+	BaseRepository
+}
+
+func NewCoinRequestRepository(db *gorm.DB, logger *zap.Logger) CoinRequestRepository {
+	return &GormCoinRequestRepository{
+		BaseRepository: BaseRepository{
+			db:     db,
+			Logger: logger,
+		},
+	}
+}
+
+func (r *GormCoinRequestRepository) Create(ctx context.Context, requesterID, payerID uint, amount int) (*database.CoinRequest, error) {
+	request := &database.CoinRequest{
+		RequesterID: requesterID,
+		PayerID:     payerID,
+		Amount:      amount,
+		Status:      database.CoinRequestPending,
+	}
+
+	if err := r.DB(ctx).Create(request).Error; err != nil {
+		r.Logger.Error("failed to create coin request", zap.Uint("requesterID", requesterID), zap.Uint("payerID", payerID), zap.Error(err))
+		return nil, WrapError(ErrCreateCoinRequest.Error(), err)
+	}
+
+	return request, nil
+}
+
+func (r *GormCoinRequestRepository) GetByID(ctx context.Context, id uint) (*database.CoinRequest, error) {
+	var request database.CoinRequest
+	if err := r.DB(ctx).First(&request, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCoinRequestNotFound
+		}
+
+		r.Logger.Error("failed to get coin request", zap.Uint("requestID", id), zap.Error(err))
+
+		return nil, WrapError(ErrGetCoinRequest.Error(), err)
+	}
+
+	return &request, nil
+}
+
+// Decline отклоняет ожидающий запрос монет. Отклонить запрос может только плательщик.
+func (r *GormCoinRequestRepository) Decline(ctx context.Context, id, payerID uint) error {
+	err := r.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		return resolveCoinRequestTx(tx, id, payerID, database.CoinRequestDeclined)
+	})
+
+	if err != nil && !errors.Is(err, ErrCoinRequestNotFound) && !errors.Is(err, ErrCoinRequestResolved) {
+		r.Logger.Error("failed to decline coin request", zap.Uint("requestID", id), zap.Uint("payerID", payerID), zap.Error(err))
+	}
+
+	return err
+}
+
+func (r *GormCoinRequestRepository) GetByUserID(ctx context.Context, userID uint, status string) (models.CoinRequestsResponse, error) {
+	var incoming []models.CoinRequest
+
+	var outgoing []models.CoinRequest
+
+	query := func(column string) *gorm.DB {
+		q := r.DB(ctx).Table("coin_requests").
+			Select("coin_requests.id, requesters.username as requester, payers.username as payer, "+
+				"coin_requests.amount, coin_requests.status, coin_requests.created_at, coin_requests.resolved_at").
+			Joins("JOIN users requesters ON coin_requests.requester_id = requesters.id").
+			Joins("JOIN users payers ON coin_requests.payer_id = payers.id").
+			Where("coin_requests."+column+" = ?", userID).
+			Order("coin_requests.created_at DESC")
+
+		if status != "" {
+			q = q.Where("coin_requests.status = ?", status)
+		}
+
+		return q
+	}
+
+	if err := query("payer_id").Scan(&incoming).Error; err != nil {
+		r.Logger.Error("failed to get incoming coin requests", zap.Uint("userID", userID), zap.Error(err))
+		return models.CoinRequestsResponse{}, WrapError(ErrGetCoinRequest.Error(), err)
+	}
+
+	if err := query("requester_id").Scan(&outgoing).Error; err != nil {
+		r.Logger.Error("failed to get outgoing coin requests", zap.Uint("userID", userID), zap.Error(err))
+		return models.CoinRequestsResponse{}, WrapError(ErrGetCoinRequest.Error(), err)
+	}
+
+	if incoming == nil {
+		incoming = make([]models.CoinRequest, 0)
+	}
+
+	if outgoing == nil {
+		outgoing = make([]models.CoinRequest, 0)
+	}
+
+	return models.CoinRequestsResponse{
+		Incoming: incoming,
+		Outgoing: outgoing,
+	}, nil
+}
+
+// resolveCoinRequestTx переводит ожидающий запрос в конечный статус в рамках транзакции tx.
+// Условие на статус в самом UPDATE защищает от повторной обработки одного запроса конкурентными вызовами.
+func resolveCoinRequestTx(tx *gorm.DB, id, payerID uint, status string) error {
+	res := tx.Model(&database.CoinRequest{}).
+		Where("id = ? AND payer_id = ? AND status = ?", id, payerID, database.CoinRequestPending).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"resolved_at": time.Now(),
+		})
+
+	if res.Error != nil {
+		return WrapError(ErrResolveCoinRequest.Error(), res.Error)
+	}
+
+	if res.RowsAffected > 0 {
+		return nil
+	}
+
+	// Запрос не обновился - выясняем, не существует ли он или уже обработан
+	var request database.CoinRequest
+	if err := tx.Select("id", "payer_id", "status").First(&request, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCoinRequestNotFound
+		}
+
+		return WrapError(ErrResolveCoinRequest.Error(), err)
+	}
+
+	// Чужие запросы для пользователя не существуют
+	if request.PayerID != payerID {
+		return ErrCoinRequestNotFound
+	}
+
+	return ErrCoinRequestResolved
+}
@@ -0,0 +1,109 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+func setupTestCoinRequestRepository(t *testing.T) (repository.HolderRepository, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: gormLogger.Default.LogMode(gormLogger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open in-memory database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&database.User{}, &database.Purchase{}, &database.Transaction{}, &database.Good{}, &database.CoinRequest{}, &database.GrantProgram{}, &database.Grant{}, &database.CoinLot{}, &database.CoinExpiration{}, &database.ItemTransfer{}, &database.Listing{}, &database.PromoCode{}, &database.PromoRedemption{}, &database.PriceSchedule{}, &database.GoodVariant{}, &database.WishlistItem{}, &database.Notification{}, &database.OutboxEvent{}, &database.Webhook{}, &database.WebhookDelivery{}); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	logger := zap.NewNop()
+	holderRepo := repository.NewHolderRepository(db, logger)
+
+	return holderRepo, db
+}
+
+func TestAcceptCoinRequest_Success(t *testing.T) {
+	holderRepo, db := setupTestCoinRequestRepository(t)
+	ctx := context.Background()
+
+	requester := database.User{Username: "requester", Coins: 100}
+	payer := database.User{Username: "payer", Coins: 100}
+
+	assert.NoError(t, db.Create(&requester).Error, "failed to create requester")
+	assert.NoError(t, db.Create(&payer).Error, "failed to create payer")
+
+	request, err := holderRepo.CoinRequest().Create(ctx, requester.ID, payer.ID, 40)
+	assert.NoError(t, err, "expected coin request creation")
+
+	assert.NoError(t, holderRepo.AcceptCoinRequest(ctx, request.ID, payer.ID, 0), "expected successful accept")
+
+	var updatedRequester, updatedPayer database.User
+
+	assert.NoError(t, db.First(&updatedRequester, requester.ID).Error, "failed to fetch requester")
+	assert.NoError(t, db.First(&updatedPayer, payer.ID).Error, "failed to fetch payer")
+	assert.Equal(t, 140, updatedRequester.Coins, "requester's coins should be credited")
+	assert.Equal(t, 60, updatedPayer.Coins, "payer's coins should be deducted")
+
+	stored, err := holderRepo.CoinRequest().GetByID(ctx, request.ID)
+	assert.NoError(t, err, "failed to fetch coin request")
+	assert.Equal(t, database.CoinRequestAccepted, stored.Status, "request should be accepted")
+	assert.NotNil(t, stored.ResolvedAt, "resolve time should be set")
+
+	err = holderRepo.AcceptCoinRequest(ctx, request.ID, payer.ID, 0)
+	assert.ErrorIs(t, err, repository.ErrCoinRequestResolved, "expected ErrCoinRequestResolved on second accept")
+}
+
+func TestAcceptCoinRequest_InsufficientFunds(t *testing.T) {
+	holderRepo, db := setupTestCoinRequestRepository(t)
+	ctx := context.Background()
+
+	requester := database.User{Username: "requester", Coins: 100}
+	payer := database.User{Username: "payer", Coins: 10}
+
+	assert.NoError(t, db.Create(&requester).Error, "failed to create requester")
+	assert.NoError(t, db.Create(&payer).Error, "failed to create payer")
+
+	request, err := holderRepo.CoinRequest().Create(ctx, requester.ID, payer.ID, 40)
+	assert.NoError(t, err, "expected coin request creation")
+
+	err = holderRepo.AcceptCoinRequest(ctx, request.ID, payer.ID, 0)
+	assert.ErrorIs(t, err, repository.ErrInsufficientFunds, "expected ErrInsufficientFunds")
+
+	stored, err := holderRepo.CoinRequest().GetByID(ctx, request.ID)
+	assert.NoError(t, err, "failed to fetch coin request")
+	assert.Equal(t, database.CoinRequestPending, stored.Status, "request should stay pending after failed accept")
+}
+
+func TestDeclineCoinRequest_WrongPayer(t *testing.T) {
+	holderRepo, db := setupTestCoinRequestRepository(t)
+	ctx := context.Background()
+
+	requester := database.User{Username: "requester", Coins: 100}
+	payer := database.User{Username: "payer", Coins: 100}
+
+	assert.NoError(t, db.Create(&requester).Error, "failed to create requester")
+	assert.NoError(t, db.Create(&payer).Error, "failed to create payer")
+
+	request, err := holderRepo.CoinRequest().Create(ctx, requester.ID, payer.ID, 40)
+	assert.NoError(t, err, "expected coin request creation")
+
+	err = holderRepo.CoinRequest().Decline(ctx, request.ID, requester.ID)
+	assert.ErrorIs(t, err, repository.ErrCoinRequestNotFound, "only payer should be able to decline request")
+
+	assert.NoError(t, holderRepo.CoinRequest().Decline(ctx, request.ID, payer.ID), "expected successful decline")
+
+	requests, err := holderRepo.CoinRequest().GetByUserID(ctx, requester.ID, database.CoinRequestDeclined)
+	assert.NoError(t, err, "failed to list coin requests")
+	assert.Len(t, requests.Outgoing, 1, "expected one declined outgoing request")
+	assert.Len(t, requests.Incoming, 0, "expected no incoming requests")
+	assert.Equal(t, "payer", requests.Outgoing[0].Payer, "unexpected payer username")
+}
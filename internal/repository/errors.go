@@ -6,6 +6,7 @@ var (
 	ErrInsufficientFunds = errors.New("insufficient funds")
 	ErrGoodNotFound      = errors.New("good not found")
 	ErrUserNotFound      = errors.New("user not found")
+	ErrUserLocked        = errors.New("user is locked")
 	ErrInvalidAmount     = errors.New("invalid amount")
 	ErrSelfTransfer      = errors.New("self-transfer not allowed")
 	ErrCreateUser        = errors.New("failed to create user")
@@ -16,4 +17,79 @@ var (
 	ErrTransferCoins     = errors.New("failed to transfer coins")
 	ErrBuyItem           = errors.New("failed to buy item")
 	ErrGetGood           = errors.New("failed to get good")
+	ErrUpdateUser        = errors.New("failed to update user")
+	ErrUpdateGood        = errors.New("failed to update good")
+	ErrAdjustCoins       = errors.New("failed to adjust coins")
+	ErrDailyLimit        = errors.New("daily transfer limit exceeded")
+
+	ErrTransactionNotFound   = errors.New("transaction not found")
+	ErrTransactionReversed   = errors.New("transaction already reversed")
+	ErrTransactionIsReversal = errors.New("transaction is a reversal and can't be reversed")
+	ErrGetTransaction        = errors.New("failed to get transaction")
+	ErrReverseTransfer       = errors.New("failed to reverse transfer")
+
+	ErrCoinRequestNotFound = errors.New("coin request not found")
+	ErrCoinRequestResolved = errors.New("coin request already resolved")
+	ErrCreateCoinRequest   = errors.New("failed to create coin request")
+	ErrGetCoinRequest      = errors.New("failed to get coin request")
+	ErrResolveCoinRequest  = errors.New("failed to resolve coin request")
+
+	ErrGrantProgramNotFound = errors.New("grant program not found")
+	ErrGrantProgramExists   = errors.New("grant program already exists")
+	ErrCreateGrantProgram   = errors.New("failed to create grant program")
+	ErrGetGrantProgram      = errors.New("failed to get grant program")
+	ErrUpdateGrantProgram   = errors.New("failed to update grant program")
+	ErrApplyGrantProgram    = errors.New("failed to apply grant program")
+
+	ErrGetCoinLots = errors.New("failed to get coin lots")
+	ErrExpireCoins = errors.New("failed to expire coins")
+
+	ErrInsufficientItems = errors.New("insufficient items")
+	ErrTransferItems     = errors.New("failed to transfer items")
+
+	ErrListingNotFound = errors.New("listing not found")
+	ErrListingClosed   = errors.New("listing is no longer active")
+	ErrCreateListing   = errors.New("failed to create listing")
+	ErrGetListing      = errors.New("failed to get listing")
+	ErrCancelListing   = errors.New("failed to cancel listing")
+	ErrBuyListing      = errors.New("failed to buy listing")
+
+	ErrPromoCodeNotFound  = errors.New("promo code not found")
+	ErrPromoCodeExists    = errors.New("promo code already exists")
+	ErrPromoCodeExhausted = errors.New("promo code usage limit reached")
+	ErrPromoCodeUserLimit = errors.New("promo code user limit reached")
+	ErrCreatePromoCode    = errors.New("failed to create promo code")
+	ErrGetPromoCode       = errors.New("failed to get promo code")
+	ErrUpdatePromoCode    = errors.New("failed to update promo code")
+
+	ErrPriceScheduleNotFound = errors.New("price schedule not found")
+	ErrCreatePriceSchedule   = errors.New("failed to create price schedule")
+	ErrGetPriceSchedule      = errors.New("failed to get price schedule")
+	ErrDeletePriceSchedule   = errors.New("failed to delete price schedule")
+	ErrGetGoods              = errors.New("failed to get goods")
+
+	ErrVariantNotFound = errors.New("good variant not found")
+	ErrVariantExists   = errors.New("good variant already exists")
+	ErrOutOfStock      = errors.New("good variant is out of stock")
+	ErrCreateVariant   = errors.New("failed to create good variant")
+	ErrGetVariant      = errors.New("failed to get good variant")
+	ErrUpdateVariant   = errors.New("failed to update good variant")
+
+	ErrAlreadyInWishlist = errors.New("good already in wishlist")
+	ErrNotInWishlist     = errors.New("good not in wishlist")
+	ErrGetWishlist       = errors.New("failed to get wishlist")
+	ErrUpdateWishlist    = errors.New("failed to update wishlist")
+
+	ErrNotificationNotFound = errors.New("notification not found")
+	ErrCreateNotification   = errors.New("failed to create notification")
+	ErrGetNotifications     = errors.New("failed to get notifications")
+	ErrUpdateNotification   = errors.New("failed to update notification")
+
+	ErrWebhookNotFound         = errors.New("webhook not found")
+	ErrWebhookDeliveryNotFound = errors.New("dead webhook delivery not found")
+	ErrCreateWebhook           = errors.New("failed to create webhook")
+	ErrGetWebhook              = errors.New("failed to get webhooks")
+	ErrUpdateWebhook           = errors.New("failed to update webhook")
+	ErrGetWebhookDelivery      = errors.New("failed to get webhook deliveries")
+	ErrUpdateWebhookDelivery   = errors.New("failed to update webhook delivery")
 )
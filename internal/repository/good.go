@@ -12,6 +12,9 @@ import (
 // GoodRepository описывает операции для работы с товарами.
 type GoodRepository interface {
 	GetByName(ctx context.Context, name string) (*database.Good, error)
+	GetByID(ctx context.Context, id uint) (*database.Good, error)
+	GetAll(ctx context.Context) ([]database.Good, error)
+	SetPrice(ctx context.Context, name string, price int) error
 }
 
 // GormGoodRepository реализует GoodRepository.
@@ -30,7 +33,7 @@ func NewGoodRepository(db *gorm.DB, logger *zap.Logger) GoodRepository {
 
 func (r *GormGoodRepository) GetByName(ctx context.Context, name string) (*database.Good, error) {
 	var good database.Good
-	if err := r.DB(ctx).Where("type = ?", name).First(&good).Error; err != nil {
+	if err := r.ReadDB(ctx).Where("type = ?", name).First(&good).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrGoodNotFound
 		}
@@ -42,3 +45,47 @@ func (r *GormGoodRepository) GetByName(ctx context.Context, name string) (*datab
 
 	return &good, nil
 }
+
+func (r *GormGoodRepository) GetByID(ctx context.Context, id uint) (*database.Good, error) {
+	var good database.Good
+	if err := r.DB(ctx).First(&good, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrGoodNotFound
+		}
+
+		r.Logger.Error("failed to get good", zap.Uint("goodID", id), zap.Error(err))
+
+		return nil, WrapError(ErrGetGood.Error(), err)
+	}
+
+	return &good, nil
+}
+
+func (r *GormGoodRepository) GetAll(ctx context.Context) ([]database.Good, error) {
+	var goods []database.Good
+	if err := r.DB(ctx).Order("type ASC").Find(&goods).Error; err != nil {
+		r.Logger.Error("failed to get goods", zap.Error(err))
+		return nil, WrapError(ErrGetGoods.Error(), err)
+	}
+
+	return goods, nil
+}
+
+// SetPrice меняет базовую цену товара. Действующие расписания цен продолжают применяться поверх неё.
+func (r *GormGoodRepository) SetPrice(ctx context.Context, name string, price int) error {
+	if price <= 0 {
+		return ErrInvalidAmount
+	}
+
+	res := r.DB(ctx).Model(&database.Good{}).Where("type = ?", name).UpdateColumn("price", price)
+	if res.Error != nil {
+		r.Logger.Error("failed to update good price", zap.String("name", name), zap.Error(res.Error))
+		return WrapError(ErrUpdateGood.Error(), res.Error)
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrGoodNotFound
+	}
+
+	return nil
+}
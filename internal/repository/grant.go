@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// GrantRepository описывает операции для работы с программами пособий и начислениями по ним.
+type GrantRepository interface {
+	CreateProgram(ctx context.Context, name string, amount int, period string) (*database.GrantProgram, error)
+	GetPrograms(ctx context.Context) ([]database.GrantProgram, error)
+	GetActivePrograms(ctx context.Context) ([]database.GrantProgram, error)
+	SetProgramActive(ctx context.Context, id uint, active bool) error
+	ApplyProgram(ctx context.Context, program database.GrantProgram, period string) (int64, error)
+	GetHistoryByUserID(ctx context.Context, userID uint) ([]models.GrantedCoins, error)
+}
+
+// GormGrantRepository реализует GrantRepository.
+type GormGrantRepository struct {
+	BaseRepository
+}
+
+func NewGrantRepository(db *gorm.DB, logger *zap.Logger) GrantRepository {
+	return &GormGrantRepository{
+		BaseRepository: BaseRepository{
+			db:     db,
+			Logger: logger,
+		},
+	}
+}
+
+func (r *GormGrantRepository) CreateProgram(ctx context.Context, name string, amount int, period string) (*database.GrantProgram, error) {
+	program := &database.GrantProgram{
+		Name:   name,
+		Amount: amount,
+		Period: period,
+		Active: true,
+	}
+
+	err := r.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&database.GrantProgram{}).Where("name = ?", name).Count(&count).Error; err != nil {
+			return err
+		}
+
+		if count > 0 {
+			return ErrGrantProgramExists
+		}
+
+		return tx.Create(program).Error
+	})
+
+	if err != nil {
+		if errors.Is(err, ErrGrantProgramExists) {
+			return nil, err
+		}
+
+		r.Logger.Error("failed to create grant program", zap.String("name", name), zap.Error(err))
+
+		return nil, WrapError(ErrCreateGrantProgram.Error(), err)
+	}
+
+	return program, nil
+}
+
+func (r *GormGrantRepository) GetPrograms(ctx context.Context) ([]database.GrantProgram, error) {
+	var programs []database.GrantProgram
+	if err := r.DB(ctx).Order("id ASC").Find(&programs).Error; err != nil {
+		r.Logger.Error("failed to get grant programs", zap.Error(err))
+		return nil, WrapError(ErrGetGrantProgram.Error(), err)
+	}
+
+	return programs, nil
+}
+
+func (r *GormGrantRepository) GetActivePrograms(ctx context.Context) ([]database.GrantProgram, error) {
+	var programs []database.GrantProgram
+	if err := r.DB(ctx).Where("active = ?", true).Order("id ASC").Find(&programs).Error; err != nil {
+		r.Logger.Error("failed to get active grant programs", zap.Error(err))
+		return nil, WrapError(ErrGetGrantProgram.Error(), err)
+	}
+
+	return programs, nil
+}
+
+func (r *GormGrantRepository) SetProgramActive(ctx context.Context, id uint, active bool) error {
+	res := r.DB(ctx).Model(&database.GrantProgram{}).Where("id = ?", id).Update("active", active)
+	if res.Error != nil {
+		r.Logger.Error("failed to update grant program", zap.Uint("programID", id), zap.Error(res.Error))
+		return WrapError(ErrUpdateGrantProgram.Error(), res.Error)
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrGrantProgramNotFound
+	}
+
+	return nil
+}
+
+// ApplyProgram начисляет монеты по программе всем пользователям, которые ещё не получили их за период period.
+// Возвращает количество пользователей, получивших начисление.
+// Повторный вызов для того же периода ничего не начисляет, а уникальный индекс на grants откатывает
+// транзакцию, если два вызова для одного периода выполняются одновременно.
+func (r *GormGrantRepository) ApplyProgram(ctx context.Context, program database.GrantProgram, period string) (int64, error) {
+	var (
+		granted    int64
+		grantedIDs []uint
+	)
+
+	now := time.Now()
+
+	err := r.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		// Фиксируем круг пользователей, чтобы зарегистрированные во время начисления не получили
+		// запись о пособии без самих монет
+		var maxUserID uint
+		if err := tx.Model(&database.User{}).Select("COALESCE(MAX(id), 0)").Scan(&maxUserID).Error; err != nil {
+			return err
+		}
+
+		notGranted := tx.Model(&database.Grant{}).
+			Select("1").
+			Where("grants.program_id = ? AND grants.period = ? AND grants.user_id = users.id", program.ID, period)
+
+		// Список получателей нужен только хукам изменений, без них его не читаем
+		if r.onUserChange != nil {
+			if err := tx.Model(&database.User{}).
+				Where("id <= ? AND NOT EXISTS (?)", maxUserID, notGranted).
+				Pluck("id", &grantedIDs).Error; err != nil {
+				return err
+			}
+		}
+
+		res := tx.Model(&database.User{}).
+			Where("id <= ? AND NOT EXISTS (?)", maxUserID, notGranted).
+			UpdateColumn("coins", gorm.Expr("coins + ?", program.Amount))
+		if res.Error != nil {
+			return res.Error
+		}
+
+		granted = res.RowsAffected
+
+		if granted == 0 {
+			return nil
+		}
+
+		// Пособие - отдельная партия монет со своим сроком жизни
+		if err := tx.Exec(
+			"INSERT INTO coin_lots (user_id, source, amount, remaining, expires_at, created_at) "+
+				"SELECT users.id, ?, ?, ?, ?, ? FROM users WHERE users.id <= ? AND NOT EXISTS (?)",
+			database.CoinSourceGrant, program.Amount, program.Amount, r.coinLotExpiry(now), now, maxUserID, notGranted,
+		).Error; err != nil {
+			return err
+		}
+
+		// Уведомления создаются до записей о пособии, пока условие notGranted ещё выбирает тех же пользователей
+		if err := tx.Exec(
+			"INSERT INTO notifications (user_id, type, message, created_at) "+
+				"SELECT users.id, ?, ?, ? FROM users WHERE users.id <= ? AND NOT EXISTS (?)",
+			database.NotificationGrant, fmt.Sprintf("You received %d coins from the %s grant program", program.Amount, program.Name),
+			now, maxUserID, notGranted,
+		).Error; err != nil {
+			return err
+		}
+
+		return tx.Exec(
+			"INSERT INTO grants (program_id, user_id, amount, period, created_at) "+
+				"SELECT ?, users.id, ?, ?, ? FROM users WHERE users.id <= ? AND NOT EXISTS (?)",
+			program.ID, program.Amount, period, now, maxUserID, notGranted,
+		).Error
+	})
+
+	if err != nil {
+		r.Logger.Error("failed to apply grant program", zap.Uint("programID", program.ID), zap.String("period", period), zap.Error(err))
+		return 0, WrapError(ErrApplyGrantProgram.Error(), err)
+	}
+
+	return granted, r.usersChanged(ctx, nil, usersOnly(grantedIDs...))
+}
+
+func (r *GormGrantRepository) GetHistoryByUserID(ctx context.Context, userID uint) ([]models.GrantedCoins, error) {
+	var granted []models.GrantedCoins
+
+	if err := r.ReadDB(ctx).Table("grants").
+		Select("grant_programs.name as program, grants.amount, grants.period").
+		Joins("JOIN grant_programs ON grants.program_id = grant_programs.id").
+		Where("grants.user_id = ?", userID).
+		Order("grants.created_at DESC").
+		Scan(&granted).Error; err != nil {
+		r.Logger.Error("failed to get granted coins", zap.Uint("userID", userID), zap.Error(err))
+		return nil, WrapError(ErrGetHistory.Error(), err)
+	}
+
+	if granted == nil {
+		return []models.GrantedCoins{}, nil
+	}
+
+	return granted, nil
+}
@@ -0,0 +1,81 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+func setupTestGrantRepository(t *testing.T) (repository.GrantRepository, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: gormLogger.Default.LogMode(gormLogger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open in-memory database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&database.User{}, &database.GrantProgram{}, &database.Grant{}, &database.CoinLot{}, &database.Notification{}, &database.OutboxEvent{}, &database.Webhook{}, &database.WebhookDelivery{}); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	logger := zap.NewNop()
+	repo := repository.NewGrantRepository(db, logger)
+
+	return repo, db
+}
+
+func TestApplyProgram_Idempotent(t *testing.T) {
+	repo, db := setupTestGrantRepository(t)
+	ctx := context.Background()
+
+	alice := database.User{Username: "alice", Coins: 100}
+	bob := database.User{Username: "bob", Coins: 50}
+
+	assert.NoError(t, db.Create(&alice).Error, "failed to create alice")
+	assert.NoError(t, db.Create(&bob).Error, "failed to create bob")
+
+	program, err := repo.CreateProgram(ctx, "allowance", 200, database.GrantPeriodMonthly)
+	assert.NoError(t, err, "failed to create grant program")
+
+	granted, err := repo.ApplyProgram(ctx, *program, "2026-10")
+	assert.NoError(t, err, "expected successful grant")
+	assert.Equal(t, int64(2), granted, "expected both users to be granted")
+
+	granted, err = repo.ApplyProgram(ctx, *program, "2026-10")
+	assert.NoError(t, err, "expected successful repeated grant")
+	assert.Equal(t, int64(0), granted, "expected no users to be granted twice in one period")
+
+	granted, err = repo.ApplyProgram(ctx, *program, "2026-11")
+	assert.NoError(t, err, "expected successful grant for next period")
+	assert.Equal(t, int64(2), granted, "expected both users to be granted in new period")
+
+	var updatedAlice database.User
+
+	assert.NoError(t, db.First(&updatedAlice, alice.ID).Error, "failed to fetch alice")
+	assert.Equal(t, 500, updatedAlice.Coins, "expected alice to be granted twice")
+
+	history, err := repo.GetHistoryByUserID(ctx, alice.ID)
+	assert.NoError(t, err, "failed to get grant history")
+	assert.Len(t, history, 2, "expected two grant entries")
+	assert.Equal(t, "allowance", history[0].Program, "unexpected program name")
+}
+
+func TestCreateProgram_Duplicate(t *testing.T) {
+	repo, _ := setupTestGrantRepository(t)
+	ctx := context.Background()
+
+	_, err := repo.CreateProgram(ctx, "allowance", 200, database.GrantPeriodMonthly)
+	assert.NoError(t, err, "failed to create grant program")
+
+	_, err = repo.CreateProgram(ctx, "allowance", 100, database.GrantPeriodDaily)
+	assert.ErrorIs(t, err, repository.ErrGrantProgramExists, "expected ErrGrantProgramExists")
+
+	assert.ErrorIs(t, repo.SetProgramActive(ctx, 9999, false), repository.ErrGrantProgramNotFound, "expected ErrGrantProgramNotFound")
+}
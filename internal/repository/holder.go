@@ -2,123 +2,810 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/internal/models"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
 type HolderRepository interface {
-	TransferCoins(ctx context.Context, senderID, receiverID uint, amount int) error
-	BuyItem(ctx context.Context, buyerID, goodID uint, goodPrice int) error
+	TransferCoins(ctx context.Context, senderID, receiverID uint, amount, dailyLimit int) error
+	BuyItem(ctx context.Context, buyerID, goodID uint, variantID *uint, goodPrice int) error
+	BuyItemWithPromo(ctx context.Context, buyerID, goodID uint, variantID *uint, goodPrice int, promo *database.PromoCode, discount int) error
+	GiftItem(ctx context.Context, buyerID, recipientID, goodID uint, variantID *uint, goodPrice int) error
+	AcceptCoinRequest(ctx context.Context, requestID, payerID uint, dailyLimit int) error
+	TransferItems(ctx context.Context, senderID, receiverID, goodID uint, variantID *uint, quantity int) error
+	BuyListing(ctx context.Context, listingID, buyerID uint) error
+	AdjustCoins(ctx context.Context, userID uint, delta int, message string) error
+	ReverseTransfer(ctx context.Context, transactionID uint) (*database.Transaction, error)
 	User() UserRepository
 	Purchase() PurchaseRepository
 	Transaction() TransactionRepository
 	Good() GoodRepository
+	CoinRequest() CoinRequestRepository
+	Grant() GrantRepository
+	CoinLot() CoinLotRepository
+	ItemTransfer() ItemTransferRepository
+	Listing() ListingRepository
+	PromoCode() PromoCodeRepository
+	PriceSchedule() PriceScheduleRepository
+	Variant() GoodVariantRepository
+	Wishlist() WishlistRepository
+	Notification() NotificationRepository
+	Webhook() WebhookRepository
 }
 
 type GormHolderRepository struct {
-	user        UserRepository
-	purchase    PurchaseRepository
-	transaction TransactionRepository
-	good        GoodRepository
-	logger      *zap.Logger
+	user          UserRepository
+	purchase      PurchaseRepository
+	transaction   TransactionRepository
+	good          GoodRepository
+	coinRequest   CoinRequestRepository
+	grant         GrantRepository
+	coinLot       CoinLotRepository
+	itemTransfer  ItemTransferRepository
+	listing       ListingRepository
+	promoCode     PromoCodeRepository
+	priceSchedule PriceScheduleRepository
+	variant       GoodVariantRepository
+	wishlist      WishlistRepository
+	notification  NotificationRepository
+	webhook       WebhookRepository
+	logger        *zap.Logger
 	BaseRepository
 }
 
-func NewHolderRepository(db *gorm.DB, logger *zap.Logger) HolderRepository {
-	return &GormHolderRepository{
-		user:        NewUserRepository(db, logger),
-		purchase:    NewPurchaseRepository(db, logger),
-		transaction: NewTransactionRepository(db, logger),
-		good:        NewGoodRepository(db, logger),
+// HolderOption настраивает HolderRepository при создании.
+type HolderOption func(*holderOptions)
+
+type holderOptions struct {
+	replicas      []*gorm.DB
+	goodsCacheTTL time.Duration
+	coinLifetime  time.Duration
+	onUserChange  []UserChangeHook
+}
+
+// WithReadReplicas распределяет по replicas чтения товаров и все чтения сводки пользователя:
+// баланс, инвентарь, истории переводов, пособий, сгорания монет, подарков и передачи товаров.
+func WithReadReplicas(replicas ...*gorm.DB) HolderOption {
+	return func(o *holderOptions) {
+		o.replicas = replicas
+	}
+}
+
+// WithGoodsCache кеширует каталог товаров на ttl. Неположительный ttl отключает кеш.
+func WithGoodsCache(ttl time.Duration) HolderOption {
+	return func(o *holderOptions) {
+		o.goodsCacheTTL = ttl
+	}
+}
+
+// WithCoinLifetime задаёт срок жизни партий монет, начисленных через репозиторий.
+// Срок записывается в партию при её создании, поэтому его изменение не влияет на уже начисленные монеты.
+func WithCoinLifetime(lifetime time.Duration) HolderOption {
+	return func(o *holderOptions) {
+		o.coinLifetime = lifetime
+	}
+}
+
+// WithUserChangeHook подписывает hook на переводы, покупки и другие операции HolderRepository,
+// а также на начисление пособий, сгорание монет и выставление и снятие объявлений. Хуки вызываются в порядке подписки.
+func WithUserChangeHook(hook UserChangeHook) HolderOption {
+	return func(o *holderOptions) {
+		o.onUserChange = append(o.onUserChange, hook)
+	}
+}
+
+// NewHolderRepository создаёт репозитории поверх основной базы db.
+func NewHolderRepository(db *gorm.DB, logger *zap.Logger, opts ...HolderOption) HolderRepository {
+	var options holderOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	holder := &GormHolderRepository{
+		user:          NewUserRepository(db, logger),
+		purchase:      NewPurchaseRepository(db, logger),
+		transaction:   NewTransactionRepository(db, logger),
+		good:          NewGoodRepository(db, logger),
+		coinRequest:   NewCoinRequestRepository(db, logger),
+		grant:         NewGrantRepository(db, logger),
+		coinLot:       NewCoinLotRepository(db, logger),
+		itemTransfer:  NewItemTransferRepository(db, logger),
+		listing:       NewListingRepository(db, logger),
+		promoCode:     NewPromoCodeRepository(db, logger),
+		priceSchedule: NewPriceScheduleRepository(db, logger),
+		variant:       NewGoodVariantRepository(db, logger),
+		wishlist:      NewWishlistRepository(db, logger),
+		notification:  NewNotificationRepository(db, logger),
+		webhook:       NewWebhookRepository(db, logger),
+		logger:        logger,
 		BaseRepository: BaseRepository{
 			db:     db,
 			Logger: logger,
 		},
 	}
+
+	if reads := newReadReplicas(options.replicas); reads != nil {
+		for _, repository := range []any{holder.user, holder.purchase, holder.transaction, holder.good, holder.grant, holder.coinLot, holder.itemTransfer} {
+			if aware, ok := repository.(interface{ setReplicas(*readReplicas) }); ok {
+				aware.setReplicas(reads)
+			}
+		}
+	}
+
+	if len(options.onUserChange) > 0 {
+		notify := func(ctx context.Context, change UserChange) {
+			for _, hook := range options.onUserChange {
+				hook(ctx, holder, change)
+			}
+		}
+
+		for _, repository := range []any{holder, holder.grant, holder.coinLot, holder.listing} {
+			if aware, ok := repository.(interface {
+				setUserChangeHook(func(context.Context, UserChange))
+			}); ok {
+				aware.setUserChangeHook(notify)
+			}
+		}
+	}
+
+	if options.coinLifetime > 0 {
+		for _, repository := range []any{holder, holder.user, holder.grant} {
+			if aware, ok := repository.(interface{ setCoinLifetime(time.Duration) }); ok {
+				aware.setCoinLifetime(options.coinLifetime)
+			}
+		}
+	}
+
+	// Кеш оборачивает репозиторий после настройки реплик, чтобы промахи тоже читали с реплик
+	if options.goodsCacheTTL > 0 {
+		holder.good = NewCachedGoodRepository(holder.good, options.goodsCacheTTL)
+	}
+
+	return holder
 }
 
 // TransferCoins переводит монеты от одного пользователя к другому.
-func (r *GormHolderRepository) TransferCoins(ctx context.Context, senderID, receiverID uint, amount int) error {
-	return r.DB(ctx).Transaction(func(tx *gorm.DB) error {
-		// Списываем баланс с дополнительной проверкой на его наличие
-		res := tx.Model(&database.User{}).
-			Where("id = ? AND coins >= ?", senderID, amount).
-			UpdateColumn("coins", gorm.Expr("coins - ?", amount))
+// Если dailyLimit больше нуля, возвращает ErrDailyLimit, когда перевод превысит суточный лимит отправителя.
+func (r *GormHolderRepository) TransferCoins(ctx context.Context, senderID, receiverID uint, amount, dailyLimit int) error {
+	var transfer TransferChange
 
-		if res.Error != nil {
-			r.logger.Error("failed to transfer coins", zap.Uint("senderID", senderID), zap.Uint("recieverID", receiverID), zap.Error(res.Error))
-			return WrapError(ErrTransferCoins.Error(), res.Error)
+	err := r.DB(ctx).Transaction(func(tx *gorm.DB) (err error) {
+		transfer, err = r.transferCoinsTx(tx, senderID, receiverID, amount, database.TransactionKindTransfer, dailyLimit)
+		return err
+	})
+
+	return r.usersChanged(ctx, err, UserChange{UserIDs: []uint{senderID, receiverID}, Transfers: []TransferChange{transfer}})
+}
+
+// AcceptCoinRequest принимает запрос монет и в той же транзакции переводит монеты от плательщика запросившему.
+// Перевод учитывается в суточном лимите плательщика так же, как в TransferCoins.
+func (r *GormHolderRepository) AcceptCoinRequest(ctx context.Context, requestID, payerID uint, dailyLimit int) error {
+	var (
+		requesterID uint
+		transfer    TransferChange
+	)
+
+	err := r.DB(ctx).Transaction(func(tx *gorm.DB) (err error) {
+		if err := resolveCoinRequestTx(tx, requestID, payerID, database.CoinRequestAccepted); err != nil {
+			if !errors.Is(err, ErrCoinRequestNotFound) && !errors.Is(err, ErrCoinRequestResolved) {
+				r.Logger.Error("failed to accept coin request", zap.Uint("requestID", requestID), zap.Uint("payerID", payerID), zap.Error(err))
+			}
+
+			return err
 		}
 
-		if res.RowsAffected == 0 {
-			fmt.Println("no")
-			// При валидации jwt токена мы можем верить что он создан именно сервером и не может быть подделан,
-			// поэтому пользователь точно существует и проблема связана с недостатком средств
-			return ErrInsufficientFunds
+		var request database.CoinRequest
+		if err := tx.First(&request, requestID).Error; err != nil {
+			r.Logger.Error("failed to get accepted coin request", zap.Uint("requestID", requestID), zap.Error(err))
+			return WrapError(ErrResolveCoinRequest.Error(), err)
 		}
 
-		// Начисляем баланс получателю
-		res = tx.Model(&database.User{}).
-			Where("id = ?", receiverID).
-			UpdateColumn("coins", gorm.Expr("coins + ?", amount))
+		requesterID = request.RequesterID
+		transfer, err = r.transferCoinsTx(tx, payerID, request.RequesterID, request.Amount, database.TransactionKindCoinRequest, dailyLimit)
 
-		if res.Error != nil {
-			r.logger.Error("failed to transfer coins", zap.Uint("senderID", senderID), zap.Uint("recieverID", receiverID), zap.Error(res.Error))
-			return WrapError(ErrTransferCoins.Error(), res.Error)
+		return err
+	})
+
+	return r.usersChanged(ctx, err, UserChange{UserIDs: []uint{payerID, requesterID}, Transfers: []TransferChange{transfer}})
+}
+
+// transferCoinsTx переводит монеты вида kind в рамках уже открытой транзакции tx.
+func (r *GormHolderRepository) transferCoinsTx(
+	tx *gorm.DB,
+	senderID, receiverID uint,
+	amount int,
+	kind string,
+	dailyLimit int,
+) (TransferChange, error) {
+	return r.createTransferTx(tx, &database.Transaction{
+		FromUserID: senderID,
+		ToUserID:   receiverID,
+		Amount:     amount,
+		Kind:       kind,
+	}, dailyLimit)
+}
+
+// createTransferTx проводит перевод transaction в рамках уже открытой транзакции tx, сохраняет запись о нём
+// и возвращает перевод для хуков изменений. Суточный лимит dailyLimit проверяется, только если он больше нуля.
+func (r *GormHolderRepository) createTransferTx(tx *gorm.DB, transaction *database.Transaction, dailyLimit int) (TransferChange, error) {
+	senderID, receiverID, amount := transaction.FromUserID, transaction.ToUserID, transaction.Amount
+
+	// Списываем баланс с дополнительной проверкой на его наличие
+	debit := tx.Model(&database.User{}).Where("id = ? AND coins >= ?", senderID, amount)
+
+	// Сторнирование выполняет администратор, поэтому блокировка получателя исходного перевода ему не мешает
+	if transaction.Kind != database.TransactionKindReversal {
+		debit = debit.Where("locked = ?", false)
+	}
+
+	res := debit.UpdateColumn("coins", gorm.Expr("coins - ?", amount))
+
+	if res.Error != nil {
+		r.Logger.Error("failed to transfer coins", zap.Uint("senderID", senderID), zap.Uint("recieverID", receiverID), zap.Error(res.Error))
+		return TransferChange{}, WrapError(ErrTransferCoins.Error(), res.Error)
+	}
+
+	if res.RowsAffected == 0 {
+		return TransferChange{}, r.debitError(tx, senderID, ErrTransferCoins)
+	}
+
+	// Строка отправителя уже заблокирована списанием, поэтому конкурентные переводы того же отправителя
+	// дождутся фиксации этой транзакции и увидят её перевод в сумме за сутки
+	if dailyLimit > 0 {
+		sent, err := sentTodayTx(tx, senderID)
+		if err != nil {
+			r.Logger.Error("failed to get sent amount", zap.Uint("senderID", senderID), zap.Error(err))
+			return TransferChange{}, WrapError(ErrTransferCoins.Error(), err)
 		}
 
-		if res.RowsAffected == 0 {
-			return ErrUserNotFound
+		if sent+amount > dailyLimit {
+			return TransferChange{}, ErrDailyLimit
 		}
+	}
 
-		// Создаем запись о переводе
-		if err := tx.Create(&database.Transaction{
-			FromUserID: senderID,
-			ToUserID:   receiverID,
-			Amount:     amount,
-		}).Error; err != nil {
-			r.logger.Error("failed to create transaction", zap.Uint("senderID", senderID), zap.Uint("recieverID", receiverID), zap.Error(err))
-			return WrapError(ErrTransferCoins.Error(), err)
+	// Начисляем баланс получателю
+	res = tx.Model(&database.User{}).
+		Where("id = ?", receiverID).
+		UpdateColumn("coins", gorm.Expr("coins + ?", amount))
+
+	if res.Error != nil {
+		r.Logger.Error("failed to transfer coins", zap.Uint("senderID", senderID), zap.Uint("recieverID", receiverID), zap.Error(res.Error))
+		return TransferChange{}, WrapError(ErrTransferCoins.Error(), res.Error)
+	}
+
+	if res.RowsAffected == 0 {
+		return TransferChange{}, ErrUserNotFound
+	}
+
+	// Переносим монеты между партиями: у отправителя сгорают самые старые, у получателя начинается новый срок жизни
+	if err := consumeCoinLotsTx(tx, senderID, amount); err != nil {
+		r.Logger.Error("failed to consume coin lots", zap.Uint("senderID", senderID), zap.Error(err))
+		return TransferChange{}, WrapError(ErrTransferCoins.Error(), err)
+	}
+
+	if err := addCoinLotTx(tx, receiverID, database.CoinSourceTransfer, amount, r.coinLotExpiry(time.Now())); err != nil {
+		r.Logger.Error("failed to add coin lot", zap.Uint("recieverID", receiverID), zap.Error(err))
+		return TransferChange{}, WrapError(ErrTransferCoins.Error(), err)
+	}
+
+	// Создаем запись о переводе
+	if err := tx.Create(transaction).Error; err != nil {
+		r.Logger.Error("failed to create transaction", zap.Uint("senderID", senderID), zap.Uint("recieverID", receiverID), zap.Error(err))
+		return TransferChange{}, WrapError(ErrTransferCoins.Error(), err)
+	}
+
+	// Уведомление и событие создаются в той же транзакции, поэтому не появятся без самого перевода
+	senderName, err := usernameTx(tx, senderID)
+	if err != nil {
+		r.Logger.Error("failed to get sender username", zap.Uint("senderID", senderID), zap.Error(err))
+		return TransferChange{}, WrapError(ErrTransferCoins.Error(), err)
+	}
+
+	message := fmt.Sprintf("%s sent you %d coins", senderName, amount)
+	if transaction.ReversalOfID != nil {
+		message = fmt.Sprintf("%s returned %d coins: transfer #%d was reversed", senderName, amount, *transaction.ReversalOfID)
+	}
+
+	if err := createNotificationTx(tx, receiverID, database.NotificationTransfer, message); err != nil {
+		r.Logger.Error("failed to notify about transfer", zap.Uint("recieverID", receiverID), zap.Error(err))
+		return TransferChange{}, WrapError(ErrTransferCoins.Error(), err)
+	}
+
+	receiverName, err := usernameTx(tx, receiverID)
+	if err != nil {
+		r.Logger.Error("failed to get receiver username", zap.Uint("recieverID", receiverID), zap.Error(err))
+		return TransferChange{}, WrapError(ErrTransferCoins.Error(), err)
+	}
+
+	if err := enqueueEventTx(tx, database.EventTransferCreated, models.TransferCreatedEvent{
+		TransactionID: transaction.ID,
+		FromUser:      senderName,
+		ToUser:        receiverName,
+		Amount:        amount,
+	}); err != nil {
+		r.Logger.Error("failed to enqueue transfer event", zap.Uint("transactionID", transaction.ID), zap.Error(err))
+		return TransferChange{}, WrapError(ErrTransferCoins.Error(), err)
+	}
+
+	return TransferChange{
+		ToUserID: receiverID,
+		Event:    models.TransferEvent{FromUser: senderName, Amount: amount},
+	}, nil
+}
+
+// ReverseTransfer отменяет перевод transactionID встречным переводом от получателя отправителю на ту же сумму.
+// Возвращает ErrInsufficientFunds, если получатель уже потратил монеты.
+func (r *GormHolderRepository) ReverseTransfer(ctx context.Context, transactionID uint) (*database.Transaction, error) {
+	var (
+		reversal *database.Transaction
+		transfer TransferChange
+	)
+
+	err := r.DB(ctx).Transaction(func(tx *gorm.DB) (err error) {
+		var original database.Transaction
+		if err := tx.First(&original, transactionID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrTransactionNotFound
+			}
+
+			return err
 		}
 
-		return nil
+		if original.ReversalOfID != nil {
+			return ErrTransactionIsReversal
+		}
+
+		var reversals int64
+		if err := tx.Model(&database.Transaction{}).Where("reversal_of_id = ?", transactionID).Count(&reversals).Error; err != nil {
+			return err
+		}
+
+		if reversals > 0 {
+			return ErrTransactionReversed
+		}
+
+		// Конкурентную повторную отмену не пропустит уникальный индекс по reversal_of_id
+		reversal = &database.Transaction{
+			FromUserID:   original.ToUserID,
+			ToUserID:     original.FromUserID,
+			Amount:       original.Amount,
+			Kind:         database.TransactionKindReversal,
+			ReversalOfID: &original.ID,
+		}
+
+		transfer, err = r.createTransferTx(tx, reversal, 0)
+
+		return err
+	})
+
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrTransactionNotFound), errors.Is(err, ErrTransactionIsReversal),
+			errors.Is(err, ErrTransactionReversed), errors.Is(err, ErrInsufficientFunds), errors.Is(err, ErrUserNotFound):
+			return nil, err
+		}
+
+		r.Logger.Error("failed to reverse transfer", zap.Uint("transactionID", transactionID), zap.Error(err))
+
+		return nil, WrapError(ErrReverseTransfer.Error(), err)
+	}
+
+	return reversal, r.usersChanged(ctx, nil, UserChange{
+		UserIDs:   []uint{reversal.FromUserID, reversal.ToUserID},
+		Transfers: []TransferChange{transfer},
 	})
 }
 
+// AdjustCoins изменяет баланс пользователя на delta монет в обход переводов.
+// Положительная delta начисляется новой партией монет, отрицательная списывается из старых партий.
+// Если message не пуст, пользователь получает его в уведомлении.
+func (r *GormHolderRepository) AdjustCoins(ctx context.Context, userID uint, delta int, message string) error {
+	if delta == 0 {
+		return ErrInvalidAmount
+	}
+
+	err := r.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		if delta > 0 {
+			res := tx.Model(&database.User{}).
+				Where("id = ?", userID).
+				UpdateColumn("coins", gorm.Expr("coins + ?", delta))
+			if res.Error != nil {
+				return res.Error
+			}
+
+			if res.RowsAffected == 0 {
+				return ErrUserNotFound
+			}
+
+			if err := addCoinLotTx(tx, userID, database.CoinSourceAdmin, delta, r.coinLotExpiry(time.Now())); err != nil {
+				return err
+			}
+		} else {
+			res := tx.Model(&database.User{}).
+				Where("id = ? AND coins >= ?", userID, -delta).
+				UpdateColumn("coins", gorm.Expr("coins - ?", -delta))
+			if res.Error != nil {
+				return res.Error
+			}
+
+			if res.RowsAffected == 0 {
+				var count int64
+				if err := tx.Model(&database.User{}).Where("id = ?", userID).Count(&count).Error; err != nil {
+					return err
+				}
+
+				if count == 0 {
+					return ErrUserNotFound
+				}
+
+				return ErrInsufficientFunds
+			}
+
+			if err := consumeCoinLotsTx(tx, userID, -delta); err != nil {
+				return err
+			}
+		}
+
+		if message == "" {
+			return nil
+		}
+
+		return createNotificationTx(tx, userID, database.NotificationAdmin, message)
+	})
+
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) || errors.Is(err, ErrInsufficientFunds) {
+			return err
+		}
+
+		r.Logger.Error("failed to adjust coins", zap.Uint("userID", userID), zap.Int("delta", delta), zap.Error(err))
+
+		return WrapError(ErrAdjustCoins.Error(), err)
+	}
+
+	return r.usersChanged(ctx, nil, usersOnly(userID))
+}
+
 // BuyItem произовдит покупку товара пользователем.
-func (r *GormHolderRepository) BuyItem(ctx context.Context, buyerID, goodID uint, goodPrice int) error {
-	return r.DB(ctx).Transaction(func(tx *gorm.DB) error {
-		// Списываем деньги и на редкий случай в котором количество монет на балансе изменилось в промежуток времени между проверкой в сервисе
-		// и выполнением в этой транзакции выполняем проверку еще раз
-		res := tx.Model(&database.User{}).
-			Where("id = ? AND coins >= ?", buyerID, goodPrice).
-			UpdateColumn("coins", gorm.Expr("coins - ?", goodPrice)) // Вряд ли цена товара изменится, да и функционала такого в проекте нет, поэтому можно себе позволить использовать уже полученную в сервисе цену
+// Для товаров с вариантами variantID указывает на покупаемый вариант, иначе он равен nil.
+func (r *GormHolderRepository) BuyItem(ctx context.Context, buyerID, goodID uint, variantID *uint, goodPrice int) error {
+	var purchase PurchaseChange
+
+	err := r.DB(ctx).Transaction(func(tx *gorm.DB) (err error) {
+		purchase, err = r.buyItemTx(tx, buyerID, &database.Purchase{
+			UserID:    buyerID,
+			GoodID:    goodID,
+			VariantID: variantID,
+		}, goodPrice)
+
+		return err
+	})
+
+	return r.usersChanged(ctx, err, UserChange{UserIDs: []uint{buyerID}, Purchases: []PurchaseChange{purchase}})
+}
+
+// BuyItemWithPromo производит покупку товара со скидкой discount по промокоду promo.
+// Лимиты использования промокода проверяются в той же транзакции, что и списание монет.
+func (r *GormHolderRepository) BuyItemWithPromo(
+	ctx context.Context,
+	buyerID, goodID uint,
+	variantID *uint,
+	goodPrice int,
+	promo *database.PromoCode,
+	discount int,
+) error {
+	var change PurchaseChange
+
+	err := r.DB(ctx).Transaction(func(tx *gorm.DB) (err error) {
+		purchase := &database.Purchase{
+			UserID:      buyerID,
+			GoodID:      goodID,
+			VariantID:   variantID,
+			PromoCodeID: &promo.ID,
+			Discount:    discount,
+		}
+
+		if change, err = r.buyItemTx(tx, buyerID, purchase, goodPrice-discount); err != nil {
+			return err
+		}
+
+		if err := redeemPromoCodeTx(tx, promo, buyerID, purchase.ID); err != nil {
+			if errors.Is(err, ErrPromoCodeExhausted) || errors.Is(err, ErrPromoCodeUserLimit) {
+				return err
+			}
+
+			r.Logger.Error("failed to redeem promo code", zap.Uint("buyerID", buyerID), zap.Uint("promoCodeID", promo.ID), zap.Error(err))
+
+			return WrapError(ErrBuyItem.Error(), err)
+		}
+
+		return nil
+	})
+
+	return r.usersChanged(ctx, err, UserChange{UserIDs: []uint{buyerID}, Purchases: []PurchaseChange{change}})
+}
+
+// GiftItem производит покупку товара пользователем buyerID в подарок пользователю recipientID.
+func (r *GormHolderRepository) GiftItem(ctx context.Context, buyerID, recipientID, goodID uint, variantID *uint, goodPrice int) error {
+	var purchase PurchaseChange
+
+	err := r.DB(ctx).Transaction(func(tx *gorm.DB) (err error) {
+		if purchase, err = r.buyItemTx(tx, buyerID, &database.Purchase{
+			UserID:     recipientID,
+			GoodID:     goodID,
+			VariantID:  variantID,
+			GiftedByID: &buyerID,
+			GiftedToID: &recipientID,
+		}, goodPrice); err != nil {
+			return err
+		}
+
+		if err := notifyFromUserTx(tx, buyerID, recipientID, database.NotificationGift, "%s gifted you %s", purchase.Event.Item); err != nil {
+			r.Logger.Error("failed to notify about gift", zap.Uint("recipientID", recipientID), zap.Error(err))
+			return WrapError(ErrBuyItem.Error(), err)
+		}
+
+		return nil
+	})
+
+	return r.usersChanged(ctx, err, UserChange{UserIDs: []uint{buyerID, recipientID}, Purchases: []PurchaseChange{purchase}})
+}
+
+// buyItemTx списывает стоимость товара с покупателя, создаёт запись purchase в рамках транзакции tx
+// и возвращает покупку для хуков изменений. Если в purchase указан вариант товара, его остаток уменьшается на единицу.
+func (r *GormHolderRepository) buyItemTx(tx *gorm.DB, buyerID uint, purchase *database.Purchase, goodPrice int) (PurchaseChange, error) {
+	goodID := purchase.GoodID
+
+	// Списываем деньги и на редкий случай в котором количество монет на балансе изменилось в промежуток времени между проверкой в сервисе
+	// и выполнением в этой транзакции выполняем проверку еще раз
+	res := tx.Model(&database.User{}).
+		Where("id = ? AND coins >= ? AND locked = ?", buyerID, goodPrice, false).
+		UpdateColumn("coins", gorm.Expr("coins - ?", goodPrice)) // Цена определяется сервисом на момент покупки с учётом расписаний и скидок
+
+	if res.Error != nil {
+		r.Logger.Error("failed to buy item", zap.Uint("buyerID", buyerID), zap.Uint("goodID", goodID), zap.Error(res.Error))
+		return PurchaseChange{}, WrapError(ErrBuyItem.Error(), res.Error)
+	}
+
+	if res.RowsAffected == 0 {
+		return PurchaseChange{}, r.debitError(tx, buyerID, ErrBuyItem)
+	}
+
+	if err := consumeCoinLotsTx(tx, buyerID, goodPrice); err != nil {
+		r.Logger.Error("failed to consume coin lots", zap.Uint("buyerID", buyerID), zap.Error(err))
+		return PurchaseChange{}, WrapError(ErrBuyItem.Error(), err)
+	}
+
+	if purchase.VariantID != nil {
+		// Условие на остаток в самом UPDATE не даст продать больше единиц, чем есть на складе
+		res = tx.Model(&database.GoodVariant{}).
+			Where("id = ? AND stock > 0", *purchase.VariantID).
+			UpdateColumn("stock", gorm.Expr("stock - 1"))
 
 		if res.Error != nil {
-			r.logger.Error("failed to buy item", zap.Uint("buyerID", buyerID), zap.Uint("goodID", goodID), zap.Error(res.Error))
-			return WrapError(ErrBuyItem.Error(), res.Error)
+			r.Logger.Error("failed to reserve variant stock", zap.Uint("variantID", *purchase.VariantID), zap.Error(res.Error))
+			return PurchaseChange{}, WrapError(ErrBuyItem.Error(), res.Error)
 		}
 
 		if res.RowsAffected == 0 {
-			// При валидации jwt токена мы можем верить что он создан именно сервером и не может быть подделан,
-			// поэтому пользователь точно существует и проблема связана с недостатком средств
-			return ErrInsufficientFunds
+			return PurchaseChange{}, ErrOutOfStock
 		}
+	}
+
+	// Создаем запись о покупке
+	if err := tx.Create(purchase).Error; err != nil {
+		r.Logger.Error("failed to create purchase", zap.Uint("buyerID", buyerID), zap.Uint("goodID", goodID), zap.Error(err))
+		return PurchaseChange{}, WrapError(ErrBuyItem.Error(), err)
+	}
+
+	event, err := enqueuePurchaseEventTx(tx, buyerID, purchase, goodPrice)
+	if err != nil {
+		r.Logger.Error("failed to enqueue purchase event", zap.Uint("purchaseID", purchase.ID), zap.Error(err))
+		return PurchaseChange{}, WrapError(ErrBuyItem.Error(), err)
+	}
+
+	change := PurchaseChange{
+		BuyerID: buyerID,
+		Event:   models.PurchaseEvent{Item: event.Item, Variant: event.Variant, Price: goodPrice},
+	}
+
+	if event.GiftedBy != "" {
+		change.Event.ToUser = event.User
+	}
+
+	return change, nil
+}
+
+// TransferItems передаёт quantity единиц товара goodID варианта variantID из инвентаря одного пользователя в инвентарь другого.
+// Пустой variantID выбирает единицы без варианта.
+func (r *GormHolderRepository) TransferItems(ctx context.Context, senderID, receiverID, goodID uint, variantID *uint, quantity int) error {
+	err := r.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		return r.transferItemsTx(tx, senderID, receiverID, goodID, variantID, quantity)
+	})
 
-		// Создаем запись о покупке
-		if err := tx.Create(&database.Purchase{
-			UserID: buyerID,
-			GoodID: goodID,
+	return r.usersChanged(ctx, err, usersOnly(senderID, receiverID))
+}
+
+// transferItemsTx передаёт товары в рамках уже открытой транзакции tx.
+// Первыми передаются самые старые единицы товара, выставленные на продажу единицы не передаются.
+func (r *GormHolderRepository) transferItemsTx(tx *gorm.DB, senderID, receiverID, goodID uint, variantID *uint, quantity int) error {
+	oldest := whereVariant(tx.Model(&database.Purchase{}).
+		Select("id").
+		Where("user_id = ? AND good_id = ? AND listing_id IS NULL", senderID, goodID), variantID).
+		Order("created_at ASC, id ASC").
+		Limit(quantity)
+
+	// Повторная проверка владельца и резерва во внешнем условии не даст забрать единицы,
+	// которые конкурентная транзакция успела передать кому-то ещё или выставить на продажу
+	res := tx.Model(&database.Purchase{}).
+		Where("user_id = ? AND listing_id IS NULL AND id IN (?)", senderID, oldest).
+		UpdateColumn("user_id", receiverID)
+
+	if res.Error != nil {
+		r.Logger.Error("failed to transfer items", zap.Uint("senderID", senderID), zap.Uint("recieverID", receiverID), zap.Uint("goodID", goodID), zap.Error(res.Error))
+		return WrapError(ErrTransferItems.Error(), res.Error)
+	}
+
+	if res.RowsAffected < int64(quantity) {
+		// Транзакция будет откачена, поэтому частично переданные единицы вернутся отправителю
+		return ErrInsufficientItems
+	}
+
+	if err := tx.Create(&database.ItemTransfer{
+		FromUserID: &senderID,
+		ToUserID:   &receiverID,
+		GoodID:     goodID,
+		VariantID:  variantID,
+		Quantity:   quantity,
+	}).Error; err != nil {
+		r.Logger.Error("failed to create item transfer", zap.Uint("senderID", senderID), zap.Uint("recieverID", receiverID), zap.Error(err))
+		return WrapError(ErrTransferItems.Error(), err)
+	}
+
+	goodType, err := goodTypeTx(tx, goodID)
+	if err != nil {
+		r.Logger.Error("failed to get transferred good", zap.Uint("goodID", goodID), zap.Error(err))
+		return WrapError(ErrTransferItems.Error(), err)
+	}
+
+	if err := notifyFromUserTx(tx, senderID, receiverID, database.NotificationItems, "%s sent you %d x %s", quantity, goodType); err != nil {
+		r.Logger.Error("failed to notify about item transfer", zap.Uint("recieverID", receiverID), zap.Error(err))
+		return WrapError(ErrTransferItems.Error(), err)
+	}
+
+	return nil
+}
+
+// BuyListing покупает товар по объявлению: монеты переходят от покупателя продавцу,
+// а зарезервированная единица товара - от продавца покупателю.
+func (r *GormHolderRepository) BuyListing(ctx context.Context, listingID, buyerID uint) error {
+	var (
+		listing  database.Listing
+		transfer TransferChange
+		purchase = PurchaseChange{BuyerID: buyerID}
+	)
+
+	err := r.DB(ctx).Transaction(func(tx *gorm.DB) (err error) {
+		if err := tx.First(&listing, listingID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrListingNotFound
+			}
+
+			r.Logger.Error("failed to get listing", zap.Uint("listingID", listingID), zap.Error(err))
+
+			return WrapError(ErrBuyListing.Error(), err)
+		}
+
+		// Условие на статус в самом UPDATE не даст продать одно объявление дважды
+		res := tx.Model(&database.Listing{}).
+			Where("id = ? AND status = ?", listingID, database.ListingActive).
+			Updates(map[string]interface{}{
+				"status":    database.ListingSold,
+				"buyer_id":  buyerID,
+				"closed_at": time.Now(),
+			})
+
+		if res.Error != nil {
+			r.Logger.Error("failed to close listing", zap.Uint("listingID", listingID), zap.Uint("buyerID", buyerID), zap.Error(res.Error))
+			return WrapError(ErrBuyListing.Error(), res.Error)
+		}
+
+		if res.RowsAffected == 0 {
+			return ErrListingClosed
+		}
+
+		if transfer, err = r.transferCoinsTx(tx, buyerID, listing.SellerID, listing.Price, database.TransactionKindListing, 0); err != nil {
+			return err
+		}
+
+		res = tx.Model(&database.Purchase{}).
+			Where("listing_id = ? AND user_id = ?", listingID, listing.SellerID).
+			Updates(map[string]interface{}{
+				"user_id":    buyerID,
+				"listing_id": nil,
+			})
+
+		if res.Error != nil {
+			r.Logger.Error("failed to move listed item", zap.Uint("listingID", listingID), zap.Uint("buyerID", buyerID), zap.Error(res.Error))
+			return WrapError(ErrBuyListing.Error(), res.Error)
+		}
+
+		if res.RowsAffected != 1 {
+			r.Logger.Error("listed item is missing", zap.Uint("listingID", listingID), zap.Int64("rows", res.RowsAffected))
+			return ErrBuyListing
+		}
+
+		if err := tx.Create(&database.ItemTransfer{
+			FromUserID: &listing.SellerID,
+			ToUserID:   &buyerID,
+			GoodID:     listing.GoodID,
+			VariantID:  listing.VariantID,
+			Quantity:   1,
 		}).Error; err != nil {
-			r.logger.Error("failed to create purchase", zap.Uint("buyerID", buyerID), zap.Uint("goodID", goodID), zap.Error(err))
-			return WrapError(ErrBuyItem.Error(), err)
+			r.Logger.Error("failed to create item transfer", zap.Uint("listingID", listingID), zap.Error(err))
+			return WrapError(ErrBuyListing.Error(), err)
+		}
+
+		purchase.Event.Price = listing.Price
+
+		if purchase.Event.Item, err = goodTypeTx(tx, listing.GoodID); err != nil {
+			r.Logger.Error("failed to get listed good", zap.Uint("listingID", listingID), zap.Error(err))
+			return WrapError(ErrBuyListing.Error(), err)
+		}
+
+		if purchase.Event.Variant, err = variantNameTx(tx, listing.VariantID); err != nil {
+			r.Logger.Error("failed to get listed variant", zap.Uint("listingID", listingID), zap.Error(err))
+			return WrapError(ErrBuyListing.Error(), err)
 		}
 
 		return nil
 	})
+
+	return r.usersChanged(ctx, err, UserChange{
+		UserIDs:   []uint{buyerID, listing.SellerID},
+		Transfers: []TransferChange{transfer},
+		Purchases: []PurchaseChange{purchase},
+	})
+}
+
+// debitError объясняет, почему условное списание монет с пользователя userID не затронуло ни одной строки.
+// Блокировка проверяется в том же UPDATE, что и баланс, поэтому она действует и на уже выданные токены,
+// и на любой канал (REST, WebSocket, gRPC, GraphQL), через который пришла команда.
+func (r *GormHolderRepository) debitError(tx *gorm.DB, userID uint, wrap error) error {
+	var locked bool
+
+	// При валидации jwt токена мы можем верить что он создан именно сервером и не может быть подделан,
+	// поэтому пользователь точно существует и проблема связана с блокировкой или недостатком средств
+	if err := tx.Model(&database.User{}).Select("locked").Where("id = ?", userID).Scan(&locked).Error; err != nil {
+		r.Logger.Error("failed to check user lock", zap.Uint("userID", userID), zap.Error(err))
+		return WrapError(wrap.Error(), err)
+	}
+
+	if locked {
+		return ErrUserLocked
+	}
+
+	return ErrInsufficientFunds
+}
+
+// sentTodayTx возвращает сумму переводов и оплаченных запросов монет пользователя userID за последние сутки.
+// Оплаты объявлений и сторнирующие переводы в лимит не входят.
+func sentTodayTx(tx *gorm.DB, userID uint) (int, error) {
+	var sent int
+
+	err := tx.Model(&database.Transaction{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("from_user_id = ? AND kind IN ? AND created_at >= ?", userID,
+			[]string{database.TransactionKindTransfer, database.TransactionKindCoinRequest}, time.Now().Add(-24*time.Hour)).
+		Scan(&sent).Error
+
+	return sent, err
 }
 
 func (r *GormHolderRepository) User() UserRepository {
@@ -136,3 +823,47 @@ func (r *GormHolderRepository) Transaction() TransactionRepository {
 func (r *GormHolderRepository) Good() GoodRepository {
 	return r.good
 }
+
+func (r *GormHolderRepository) CoinRequest() CoinRequestRepository {
+	return r.coinRequest
+}
+
+func (r *GormHolderRepository) Grant() GrantRepository {
+	return r.grant
+}
+
+func (r *GormHolderRepository) CoinLot() CoinLotRepository {
+	return r.coinLot
+}
+
+func (r *GormHolderRepository) ItemTransfer() ItemTransferRepository {
+	return r.itemTransfer
+}
+
+func (r *GormHolderRepository) Listing() ListingRepository {
+	return r.listing
+}
+
+func (r *GormHolderRepository) PromoCode() PromoCodeRepository {
+	return r.promoCode
+}
+
+func (r *GormHolderRepository) PriceSchedule() PriceScheduleRepository {
+	return r.priceSchedule
+}
+
+func (r *GormHolderRepository) Variant() GoodVariantRepository {
+	return r.variant
+}
+
+func (r *GormHolderRepository) Wishlist() WishlistRepository {
+	return r.wishlist
+}
+
+func (r *GormHolderRepository) Notification() NotificationRepository {
+	return r.notification
+}
+
+func (r *GormHolderRepository) Webhook() WebhookRepository {
+	return r.webhook
+}
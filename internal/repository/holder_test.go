@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/internal/models"
 	"github.com/maksemen2/avito-shop/internal/repository"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
@@ -22,7 +23,7 @@ func setupTestHolderRepository(t *testing.T) (repository.HolderRepository, *gorm
 		t.Fatalf("failed to open in-memory database: %v", err)
 	}
 
-	if err := db.AutoMigrate(&database.User{}, &database.Purchase{}, &database.Transaction{}, &database.Good{}); err != nil {
+	if err := db.AutoMigrate(&database.User{}, &database.Purchase{}, &database.Transaction{}, &database.Good{}, &database.CoinRequest{}, &database.GrantProgram{}, &database.Grant{}, &database.CoinLot{}, &database.CoinExpiration{}, &database.ItemTransfer{}, &database.Listing{}, &database.PromoCode{}, &database.PromoRedemption{}, &database.PriceSchedule{}, &database.GoodVariant{}, &database.WishlistItem{}, &database.Notification{}, &database.OutboxEvent{}, &database.Webhook{}, &database.WebhookDelivery{}); err != nil {
 		t.Fatalf("failed to migrate database: %v", err)
 	}
 
@@ -43,7 +44,7 @@ func TestTransferCoins_Success(t *testing.T) {
 	assert.NoError(t, db.Create(&receiver).Error, "failed to create receiver")
 
 	transferAmount := 30
-	err := holderRepo.TransferCoins(ctx, sender.ID, receiver.ID, transferAmount)
+	err := holderRepo.TransferCoins(ctx, sender.ID, receiver.ID, transferAmount, 0)
 	assert.NoError(t, err, "expected successful transfer")
 
 	var updatedSender, updatedReceiver database.User
@@ -58,6 +59,35 @@ func TestTransferCoins_Success(t *testing.T) {
 	assert.NoError(t, err, "expected transaction record creation")
 }
 
+func TestTransferCoins_DailyLimit(t *testing.T) {
+	holderRepo, db := setupTestHolderRepository(t)
+	ctx := context.Background()
+
+	sender := database.User{Username: "test1", Coins: 1000}
+	receiver := database.User{Username: "test2", Coins: 1000}
+
+	assert.NoError(t, db.Create(&sender).Error, "failed to create sender")
+	assert.NoError(t, db.Create(&receiver).Error, "failed to create receiver")
+
+	request, err := holderRepo.CoinRequest().Create(ctx, receiver.ID, sender.ID, 60)
+	assert.NoError(t, err, "expected coin request creation")
+	assert.NoError(t, holderRepo.AcceptCoinRequest(ctx, request.ID, sender.ID, 100), "expected successful accept")
+
+	// Оплаченный запрос монет входит в лимит наравне с переводом
+	assert.ErrorIs(t, holderRepo.TransferCoins(ctx, sender.ID, receiver.ID, 50, 100), repository.ErrDailyLimit)
+	assert.NoError(t, holderRepo.TransferCoins(ctx, sender.ID, receiver.ID, 40, 100), "transfer within limit should succeed")
+
+	var updatedSender database.User
+
+	assert.NoError(t, db.First(&updatedSender, sender.ID).Error, "failed to fetch sender")
+	assert.Equal(t, 900, updatedSender.Coins, "rejected transfer should not be deducted")
+
+	var kinds []string
+
+	assert.NoError(t, db.Model(&database.Transaction{}).Order("id").Pluck("kind", &kinds).Error)
+	assert.Equal(t, []string{database.TransactionKindCoinRequest, database.TransactionKindTransfer}, kinds)
+}
+
 func TestTransferCoins_InsufficientFunds(t *testing.T) {
 	holderRepo, db := setupTestHolderRepository(t)
 	ctx := context.Background()
@@ -69,7 +99,7 @@ func TestTransferCoins_InsufficientFunds(t *testing.T) {
 	assert.NoError(t, db.Create(&receiver).Error, "failed to create receiver")
 
 	transferAmount := 20
-	err := holderRepo.TransferCoins(ctx, sender.ID, receiver.ID, transferAmount)
+	err := holderRepo.TransferCoins(ctx, sender.ID, receiver.ID, transferAmount, 0)
 	assert.True(t, errors.Is(err, repository.ErrInsufficientFunds), "expected ErrInsufficientFunds error")
 
 	var updatedSender database.User
@@ -92,7 +122,7 @@ func TestTransferCoins_ReceiverNotFound(t *testing.T) {
 
 	nonExistentReceiverID := uint(9999)
 	transferAmount := 20
-	err := holderRepo.TransferCoins(ctx, sender.ID, nonExistentReceiverID, transferAmount)
+	err := holderRepo.TransferCoins(ctx, sender.ID, nonExistentReceiverID, transferAmount, 0)
 	assert.True(t, errors.Is(err, repository.ErrUserNotFound), "expected ErrUserNotFound error")
 
 	var updatedSender database.User
@@ -100,3 +130,188 @@ func TestTransferCoins_ReceiverNotFound(t *testing.T) {
 	assert.NoError(t, db.First(&updatedSender, sender.ID).Error, "failed to fetch sender")
 	assert.Equal(t, sender.Coins, updatedSender.Coins, "sender's coins should remain unchanged")
 }
+
+func TestDebit_LockedUser(t *testing.T) {
+	holderRepo, db := setupTestHolderRepository(t)
+	ctx := context.Background()
+
+	sender := database.User{Username: "test1", Coins: 100}
+	receiver := database.User{Username: "test2", Coins: 50}
+	good := database.Good{Type: "cup", Price: 20}
+
+	assert.NoError(t, db.Create(&sender).Error, "failed to create sender")
+	assert.NoError(t, db.Create(&receiver).Error, "failed to create receiver")
+	assert.NoError(t, db.Create(&good).Error, "failed to create good")
+	assert.NoError(t, holderRepo.TransferCoins(ctx, receiver.ID, sender.ID, 30, 0), "expected successful transfer")
+	assert.NoError(t, holderRepo.User().SetLocked(ctx, sender.ID, true), "failed to lock sender")
+
+	err := holderRepo.TransferCoins(ctx, sender.ID, receiver.ID, 20, 0)
+	assert.ErrorIs(t, err, repository.ErrUserLocked, "locked user must not send coins")
+
+	err = holderRepo.BuyItem(ctx, sender.ID, good.ID, nil, good.Price)
+	assert.ErrorIs(t, err, repository.ErrUserLocked, "locked user must not buy items")
+
+	// Сторнирование выполняет администратор, блокировка получателя исходного перевода ему не мешает
+	var original database.Transaction
+	assert.NoError(t, db.First(&original).Error, "failed to fetch transfer")
+
+	_, err = holderRepo.ReverseTransfer(ctx, original.ID)
+	assert.NoError(t, err, "expected reversal from locked user")
+
+	var updatedSender database.User
+
+	assert.NoError(t, db.First(&updatedSender, sender.ID).Error, "failed to fetch sender")
+	assert.Equal(t, 100, updatedSender.Coins, "only the reversal should change locked user's coins")
+}
+
+func TestTransferItems_Success(t *testing.T) {
+	holderRepo, db := setupTestHolderRepository(t)
+	ctx := context.Background()
+
+	sender := database.User{Username: "test1", Coins: 100}
+	receiver := database.User{Username: "test2", Coins: 100}
+	good := database.Good{Type: "cup", Price: 20}
+
+	assert.NoError(t, db.Create(&sender).Error, "failed to create sender")
+	assert.NoError(t, db.Create(&receiver).Error, "failed to create receiver")
+	assert.NoError(t, db.Create(&good).Error, "failed to create good")
+
+	for range 3 {
+		assert.NoError(t, db.Create(&database.Purchase{UserID: sender.ID, GoodID: good.ID}).Error, "failed to create purchase")
+	}
+
+	err := holderRepo.TransferItems(ctx, sender.ID, receiver.ID, good.ID, nil, 2)
+	assert.NoError(t, err, "expected successful item transfer")
+
+	var senderItems, receiverItems int64
+
+	db.Model(&database.Purchase{}).Where("user_id = ?", sender.ID).Count(&senderItems)
+	db.Model(&database.Purchase{}).Where("user_id = ?", receiver.ID).Count(&receiverItems)
+	assert.Equal(t, int64(1), senderItems, "sender should keep one item")
+	assert.Equal(t, int64(2), receiverItems, "receiver should get two items")
+
+	var record database.ItemTransfer
+	err = db.Where("from_user_id = ? AND to_user_id = ? AND quantity = ?", sender.ID, receiver.ID, 2).First(&record).Error
+	assert.NoError(t, err, "expected item transfer record creation")
+}
+
+func TestTransferItems_InsufficientItems(t *testing.T) {
+	holderRepo, db := setupTestHolderRepository(t)
+	ctx := context.Background()
+
+	sender := database.User{Username: "test1", Coins: 100}
+	receiver := database.User{Username: "test2", Coins: 100}
+	good := database.Good{Type: "cup", Price: 20}
+
+	assert.NoError(t, db.Create(&sender).Error, "failed to create sender")
+	assert.NoError(t, db.Create(&receiver).Error, "failed to create receiver")
+	assert.NoError(t, db.Create(&good).Error, "failed to create good")
+	assert.NoError(t, db.Create(&database.Purchase{UserID: sender.ID, GoodID: good.ID}).Error, "failed to create purchase")
+
+	err := holderRepo.TransferItems(ctx, sender.ID, receiver.ID, good.ID, nil, 2)
+	assert.True(t, errors.Is(err, repository.ErrInsufficientItems), "expected ErrInsufficientItems")
+
+	var senderItems int64
+
+	db.Model(&database.Purchase{}).Where("user_id = ?", sender.ID).Count(&senderItems)
+	assert.Equal(t, int64(1), senderItems, "partial transfer should be rolled back")
+
+	var records int64
+
+	db.Model(&database.ItemTransfer{}).Count(&records)
+	assert.Equal(t, int64(0), records, "no item transfer record should be created")
+}
+
+func TestGiftHistory_AfterItemTransfer(t *testing.T) {
+	holderRepo, db := setupTestHolderRepository(t)
+	ctx := context.Background()
+
+	giver := database.User{Username: "giver", Coins: 100}
+	recipient := database.User{Username: "recipient", Coins: 100}
+	other := database.User{Username: "other", Coins: 100}
+	good := database.Good{Type: "cup", Price: 20}
+
+	for _, record := range []any{&giver, &recipient, &other, &good} {
+		assert.NoError(t, db.Create(record).Error, "failed to create %T", record)
+	}
+
+	assert.NoError(t, holderRepo.GiftItem(ctx, giver.ID, recipient.ID, good.ID, nil, good.Price), "expected successful gift")
+
+	// Подаренная единица уходит дальше, но в истории подарок остаётся за исходным получателем
+	assert.NoError(t, holderRepo.TransferItems(ctx, recipient.ID, other.ID, good.ID, nil, 1), "expected successful item transfer")
+
+	history, err := holderRepo.Purchase().GetGiftHistoryByUserID(ctx, recipient.ID)
+	assert.NoError(t, err, "failed to get recipient gift history")
+	assert.Equal(t, []models.ReceivedGift{{FromUser: "giver", Item: "cup"}}, history.Received)
+
+	history, err = holderRepo.Purchase().GetGiftHistoryByUserID(ctx, giver.ID)
+	assert.NoError(t, err, "failed to get giver gift history")
+	assert.Equal(t, []models.SentGift{{ToUser: "recipient", Item: "cup"}}, history.Sent)
+
+	history, err = holderRepo.Purchase().GetGiftHistoryByUserID(ctx, other.ID)
+	assert.NoError(t, err, "failed to get new owner gift history")
+	assert.Empty(t, history.Received, "transferred item is not a gift to its new owner")
+}
+
+func TestReverseTransfer(t *testing.T) {
+	holderRepo, db := setupTestHolderRepository(t)
+	ctx := context.Background()
+
+	sender := database.User{Username: "test1", Coins: 100}
+	receiver := database.User{Username: "test2", Coins: 50}
+
+	assert.NoError(t, db.Create(&sender).Error, "failed to create sender")
+	assert.NoError(t, db.Create(&receiver).Error, "failed to create receiver")
+	assert.NoError(t, holderRepo.TransferCoins(ctx, sender.ID, receiver.ID, 30, 0), "expected successful transfer")
+
+	var original database.Transaction
+	assert.NoError(t, db.First(&original).Error, "failed to fetch transfer")
+
+	reversal, err := holderRepo.ReverseTransfer(ctx, original.ID)
+	if err != nil {
+		t.Fatalf("failed to reverse transfer: %v", err)
+	}
+
+	assert.Equal(t, receiver.ID, reversal.FromUserID)
+	assert.Equal(t, sender.ID, reversal.ToUserID)
+	assert.Equal(t, original.ID, *reversal.ReversalOfID)
+
+	var updatedSender, updatedReceiver database.User
+
+	assert.NoError(t, db.First(&updatedSender, sender.ID).Error, "failed to fetch sender")
+	assert.NoError(t, db.First(&updatedReceiver, receiver.ID).Error, "failed to fetch receiver")
+	assert.Equal(t, 100, updatedSender.Coins, "sender's coins should be returned")
+	assert.Equal(t, 50, updatedReceiver.Coins, "receiver's coins should be taken back")
+
+	_, err = holderRepo.ReverseTransfer(ctx, original.ID)
+	assert.ErrorIs(t, err, repository.ErrTransactionReversed)
+
+	_, err = holderRepo.ReverseTransfer(ctx, reversal.ID)
+	assert.ErrorIs(t, err, repository.ErrTransactionIsReversal)
+
+	_, err = holderRepo.ReverseTransfer(ctx, 100)
+	assert.ErrorIs(t, err, repository.ErrTransactionNotFound)
+}
+
+func TestAdjustCoins(t *testing.T) {
+	holderRepo, db := setupTestHolderRepository(t)
+	ctx := context.Background()
+
+	user := database.User{Username: "test1", Coins: 100}
+	assert.NoError(t, db.Create(&user).Error, "failed to create user")
+
+	assert.NoError(t, holderRepo.AdjustCoins(ctx, user.ID, 50, "bonus"))
+	assert.NoError(t, holderRepo.AdjustCoins(ctx, user.ID, -120, ""))
+	assert.ErrorIs(t, holderRepo.AdjustCoins(ctx, user.ID, -31, ""), repository.ErrInsufficientFunds)
+	assert.ErrorIs(t, holderRepo.AdjustCoins(ctx, 100, 10, ""), repository.ErrUserNotFound)
+
+	var updated database.User
+
+	assert.NoError(t, db.First(&updated, user.ID).Error, "failed to fetch user")
+	assert.Equal(t, 30, updated.Coins)
+
+	var notifications int64
+
+	assert.NoError(t, db.Model(&database.Notification{}).Where("user_id = ? AND type = ?", user.ID, database.NotificationAdmin).Count(&notifications).Error)
+	assert.Equal(t, int64(1), notifications, "only the adjustment with a message should notify the user")
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ItemTransferRepository описывает операции для получения истории передачи товаров.
+type ItemTransferRepository interface {
+	GetHistoryByUserID(ctx context.Context, userID uint) (models.ItemHistory, error)
+}
+
+// GormItemTransferRepository реализует ItemTransferRepository.
+type GormItemTransferRepository struct {
+	BaseRepository
+}
+
+func NewItemTransferRepository(db *gorm.DB, logger *zap.Logger) ItemTransferRepository {
+	return &GormItemTransferRepository{
+		BaseRepository: BaseRepository{
+			db:     db,
+			Logger: logger,
+		},
+	}
+}
+
+func (r *GormItemTransferRepository) GetHistoryByUserID(ctx context.Context, userID uint) (models.ItemHistory, error) {
+	var received []models.ReceivedItems
+
+	var sent []models.SentItems
+
+	if err := r.ReadDB(ctx).Model(&database.ItemTransfer{}).
+		Select("users.username as from_user, goods.type as item, COALESCE(good_variants.name, '') as variant, item_transfers.quantity").
+		Joins("JOIN users ON item_transfers.from_user_id = users.id").
+		Joins("JOIN goods ON item_transfers.good_id = goods.id").
+		Joins("LEFT JOIN good_variants ON good_variants.id = item_transfers.variant_id").
+		Where("item_transfers.to_user_id = ?", userID).
+		Order("item_transfers.created_at DESC").
+		Scan(&received).Error; err != nil {
+		r.Logger.Error("failed to get received items", zap.Uint("userID", userID), zap.Error(err))
+		return models.ItemHistory{}, WrapError(ErrGetHistory.Error(), err)
+	}
+
+	if err := r.ReadDB(ctx).Model(&database.ItemTransfer{}).
+		Select("users.username as to_user, goods.type as item, COALESCE(good_variants.name, '') as variant, item_transfers.quantity").
+		Joins("JOIN users ON item_transfers.to_user_id = users.id").
+		Joins("JOIN goods ON item_transfers.good_id = goods.id").
+		Joins("LEFT JOIN good_variants ON good_variants.id = item_transfers.variant_id").
+		Where("item_transfers.from_user_id = ?", userID).
+		Order("item_transfers.created_at DESC").
+		Scan(&sent).Error; err != nil {
+		r.Logger.Error("failed to get sent items", zap.Uint("userID", userID), zap.Error(err))
+		return models.ItemHistory{}, WrapError(ErrGetHistory.Error(), err)
+	}
+
+	if received == nil {
+		received = make([]models.ReceivedItems, 0)
+	}
+
+	if sent == nil {
+		sent = make([]models.SentItems, 0)
+	}
+
+	return models.ItemHistory{
+		Received: received,
+		Sent:     sent,
+	}, nil
+}
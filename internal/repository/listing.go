@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ListingRepository описывает операции с объявлениями маркетплейса.
+type ListingRepository interface {
+	Create(ctx context.Context, sellerID, goodID uint, variantID *uint, price int) (*database.Listing, error)
+	GetByID(ctx context.Context, id uint) (*database.Listing, error)
+	Cancel(ctx context.Context, id, sellerID uint) error
+	GetActive(ctx context.Context, item, seller string) ([]models.Listing, error)
+}
+
+// GormListingRepository реализует ListingRepository.
+type GormListingRepository struct {
+	BaseRepository
+}
+
+func NewListingRepository(db *gorm.DB, logger *zap.Logger) ListingRepository {
+	return &GormListingRepository{
+		BaseRepository: BaseRepository{
+			db:     db,
+			Logger: logger,
+		},
+	}
+}
+
+// Create выставляет на продажу одну единицу товара goodID варианта variantID из инвентаря продавца.
+// Самая старая свободная единица резервируется за объявлением до его продажи или отмены.
+func (r *GormListingRepository) Create(ctx context.Context, sellerID, goodID uint, variantID *uint, price int) (*database.Listing, error) {
+	listing := &database.Listing{
+		SellerID:  sellerID,
+		GoodID:    goodID,
+		VariantID: variantID,
+		Price:     price,
+		Status:    database.ListingActive,
+	}
+
+	err := r.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(listing).Error; err != nil {
+			return err
+		}
+
+		oldest := whereVariant(tx.Model(&database.Purchase{}).
+			Select("id").
+			Where("user_id = ? AND good_id = ? AND listing_id IS NULL", sellerID, goodID), variantID).
+			Order("created_at ASC, id ASC").
+			Limit(1)
+
+		res := tx.Model(&database.Purchase{}).
+			Where("user_id = ? AND listing_id IS NULL AND id IN (?)", sellerID, oldest).
+			UpdateColumn("listing_id", listing.ID)
+
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return ErrInsufficientItems
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		if errors.Is(err, ErrInsufficientItems) {
+			return nil, err
+		}
+
+		r.Logger.Error("failed to create listing", zap.Uint("sellerID", sellerID), zap.Uint("goodID", goodID), zap.Error(err))
+
+		return nil, WrapError(ErrCreateListing.Error(), err)
+	}
+
+	return listing, r.usersChanged(ctx, nil, usersOnly(sellerID))
+}
+
+func (r *GormListingRepository) GetByID(ctx context.Context, id uint) (*database.Listing, error) {
+	var listing database.Listing
+	if err := r.DB(ctx).First(&listing, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrListingNotFound
+		}
+
+		r.Logger.Error("failed to get listing", zap.Uint("listingID", id), zap.Error(err))
+
+		return nil, WrapError(ErrGetListing.Error(), err)
+	}
+
+	return &listing, nil
+}
+
+// Cancel снимает активное объявление с продажи и возвращает зарезервированную единицу в свободный инвентарь продавца.
+func (r *GormListingRepository) Cancel(ctx context.Context, id, sellerID uint) error {
+	err := r.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&database.Listing{}).
+			Where("id = ? AND seller_id = ? AND status = ?", id, sellerID, database.ListingActive).
+			Updates(map[string]interface{}{
+				"status":    database.ListingCancelled,
+				"closed_at": time.Now(),
+			})
+
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			var listing database.Listing
+			if err := tx.Select("id", "seller_id").First(&listing, id).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return ErrListingNotFound
+				}
+
+				return err
+			}
+
+			// Чужие объявления нельзя отменить, поэтому для пользователя их не существует
+			if listing.SellerID != sellerID {
+				return ErrListingNotFound
+			}
+
+			return ErrListingClosed
+		}
+
+		return tx.Model(&database.Purchase{}).
+			Where("listing_id = ?", id).
+			UpdateColumn("listing_id", nil).Error
+	})
+
+	if err != nil {
+		if errors.Is(err, ErrListingNotFound) || errors.Is(err, ErrListingClosed) {
+			return err
+		}
+
+		r.Logger.Error("failed to cancel listing", zap.Uint("listingID", id), zap.Uint("sellerID", sellerID), zap.Error(err))
+
+		return WrapError(ErrCancelListing.Error(), err)
+	}
+
+	return r.usersChanged(ctx, nil, usersOnly(sellerID))
+}
+
+// GetActive возвращает активные объявления от новых к старым.
+// Непустые item и seller ограничивают выборку товаром и продавцом соответственно.
+func (r *GormListingRepository) GetActive(ctx context.Context, item, seller string) ([]models.Listing, error) {
+	var listings []models.Listing
+
+	query := r.DB(ctx).Table("listings").
+		Select("listings.id, users.username as seller, goods.type as item, COALESCE(good_variants.name, '') as variant, "+
+			"listings.price, listings.status, listings.created_at").
+		Joins("JOIN users ON listings.seller_id = users.id").
+		Joins("JOIN goods ON listings.good_id = goods.id").
+		Joins("LEFT JOIN good_variants ON good_variants.id = listings.variant_id").
+		Where("listings.status = ?", database.ListingActive).
+		Order("listings.created_at DESC, listings.id DESC")
+
+	if item != "" {
+		query = query.Where("goods.type = ?", item)
+	}
+
+	if seller != "" {
+		query = query.Where("users.username = ?", seller)
+	}
+
+	if err := query.Scan(&listings).Error; err != nil {
+		r.Logger.Error("failed to get listings", zap.String("item", item), zap.String("seller", seller), zap.Error(err))
+		return nil, WrapError(ErrGetListing.Error(), err)
+	}
+
+	if listings == nil {
+		return []models.Listing{}, nil
+	}
+
+	return listings, nil
+}
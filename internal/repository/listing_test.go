@@ -0,0 +1,172 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/internal/models"
+	"github.com/maksemen2/avito-shop/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupListingFixture(t *testing.T, db *gorm.DB) (database.User, database.User, database.Good) {
+	seller := database.User{Username: "seller", Coins: 100}
+	buyer := database.User{Username: "buyer", Coins: 100}
+	good := database.Good{Type: "cup", Price: 20}
+
+	assert.NoError(t, db.Create(&seller).Error, "failed to create seller")
+	assert.NoError(t, db.Create(&buyer).Error, "failed to create buyer")
+	assert.NoError(t, db.Create(&good).Error, "failed to create good")
+	assert.NoError(t, db.Create(&database.Purchase{UserID: seller.ID, GoodID: good.ID}).Error, "failed to create purchase")
+
+	return seller, buyer, good
+}
+
+func TestCreateListing_ReservesItem(t *testing.T) {
+	holderRepo, db := setupTestHolderRepository(t)
+	ctx := context.Background()
+
+	seller, buyer, good := setupListingFixture(t, db)
+
+	listing, err := holderRepo.Listing().Create(ctx, seller.ID, good.ID, nil, 50)
+	assert.NoError(t, err, "expected listing creation")
+	assert.Equal(t, database.ListingActive, listing.Status)
+
+	// Единственная единица зарезервирована, поэтому второе объявление и передача невозможны
+	_, err = holderRepo.Listing().Create(ctx, seller.ID, good.ID, nil, 50)
+	assert.True(t, errors.Is(err, repository.ErrInsufficientItems), "expected ErrInsufficientItems for second listing")
+
+	err = holderRepo.TransferItems(ctx, seller.ID, buyer.ID, good.ID, nil, 1)
+	assert.True(t, errors.Is(err, repository.ErrInsufficientItems), "expected reserved item not to be transferable")
+
+	var listingsCount int64
+
+	db.Model(&database.Listing{}).Count(&listingsCount)
+	assert.Equal(t, int64(1), listingsCount, "failed listing should be rolled back")
+}
+
+func TestCancelListing(t *testing.T) {
+	holderRepo, db := setupTestHolderRepository(t)
+	ctx := context.Background()
+
+	seller, buyer, good := setupListingFixture(t, db)
+
+	listing, err := holderRepo.Listing().Create(ctx, seller.ID, good.ID, nil, 50)
+	assert.NoError(t, err, "expected listing creation")
+
+	err = holderRepo.Listing().Cancel(ctx, listing.ID, buyer.ID)
+	assert.True(t, errors.Is(err, repository.ErrListingNotFound), "other user's listing should not be found")
+
+	assert.NoError(t, holderRepo.Listing().Cancel(ctx, listing.ID, seller.ID), "expected listing cancellation")
+
+	err = holderRepo.Listing().Cancel(ctx, listing.ID, seller.ID)
+	assert.True(t, errors.Is(err, repository.ErrListingClosed), "expected ErrListingClosed on second cancel")
+
+	// После отмены единица снова свободна
+	assert.NoError(t, holderRepo.TransferItems(ctx, seller.ID, buyer.ID, good.ID, nil, 1), "expected released item to be transferable")
+}
+
+func TestBuyListing(t *testing.T) {
+	holderRepo, db := setupTestHolderRepository(t)
+	ctx := context.Background()
+
+	seller, buyer, good := setupListingFixture(t, db)
+
+	listing, err := holderRepo.Listing().Create(ctx, seller.ID, good.ID, nil, 60)
+	assert.NoError(t, err, "expected listing creation")
+
+	assert.NoError(t, holderRepo.BuyListing(ctx, listing.ID, buyer.ID), "expected successful buy")
+
+	var updatedSeller, updatedBuyer database.User
+
+	assert.NoError(t, db.First(&updatedSeller, seller.ID).Error, "failed to fetch seller")
+	assert.NoError(t, db.First(&updatedBuyer, buyer.ID).Error, "failed to fetch buyer")
+	assert.Equal(t, 160, updatedSeller.Coins, "seller should receive the price")
+	assert.Equal(t, 40, updatedBuyer.Coins, "buyer should pay the price")
+
+	var purchase database.Purchase
+
+	assert.NoError(t, db.Where("good_id = ?", good.ID).First(&purchase).Error, "failed to fetch purchase")
+	assert.Equal(t, buyer.ID, purchase.UserID, "item should belong to buyer")
+	assert.Nil(t, purchase.ListingID, "item should not stay reserved")
+
+	var sold database.Listing
+
+	assert.NoError(t, db.First(&sold, listing.ID).Error, "failed to fetch listing")
+	assert.Equal(t, database.ListingSold, sold.Status)
+	assert.Equal(t, buyer.ID, *sold.BuyerID)
+
+	err = holderRepo.BuyListing(ctx, listing.ID, buyer.ID)
+	assert.True(t, errors.Is(err, repository.ErrListingClosed), "expected ErrListingClosed on second buy")
+}
+
+func TestBuyListing_InsufficientFunds(t *testing.T) {
+	holderRepo, db := setupTestHolderRepository(t)
+	ctx := context.Background()
+
+	seller, buyer, good := setupListingFixture(t, db)
+
+	listing, err := holderRepo.Listing().Create(ctx, seller.ID, good.ID, nil, 500)
+	assert.NoError(t, err, "expected listing creation")
+
+	err = holderRepo.BuyListing(ctx, listing.ID, buyer.ID)
+	assert.True(t, errors.Is(err, repository.ErrInsufficientFunds), "expected ErrInsufficientFunds")
+
+	var active database.Listing
+
+	assert.NoError(t, db.First(&active, listing.ID).Error, "failed to fetch listing")
+	assert.Equal(t, database.ListingActive, active.Status, "failed buy should keep listing active")
+}
+
+func TestListingAndTransfer_SelectVariant(t *testing.T) {
+	holderRepo, db := setupTestHolderRepository(t)
+	ctx := context.Background()
+
+	seller, buyer, good := setupListingFixture(t, db)
+
+	red := database.GoodVariant{GoodID: good.ID, Name: "red"}
+	blue := database.GoodVariant{GoodID: good.ID, Name: "blue"}
+
+	assert.NoError(t, db.Create(&red).Error, "failed to create red variant")
+	assert.NoError(t, db.Create(&blue).Error, "failed to create blue variant")
+
+	// Самая старая единица продавца без варианта, но выставляется и передаётся только запрошенный вариант
+	assert.NoError(t, db.Create(&database.Purchase{UserID: seller.ID, GoodID: good.ID, VariantID: &red.ID}).Error)
+	assert.NoError(t, db.Create(&database.Purchase{UserID: seller.ID, GoodID: good.ID, VariantID: &blue.ID}).Error)
+
+	listing, err := holderRepo.Listing().Create(ctx, seller.ID, good.ID, &red.ID, 50)
+	assert.NoError(t, err, "expected listing creation")
+
+	var reserved database.Purchase
+
+	assert.NoError(t, db.Where("listing_id = ?", listing.ID).First(&reserved).Error, "expected reserved item")
+	assert.Equal(t, &red.ID, reserved.VariantID, "listing should reserve the requested variant")
+
+	_, err = holderRepo.Listing().Create(ctx, seller.ID, good.ID, &red.ID, 50)
+	assert.ErrorIs(t, err, repository.ErrInsufficientItems, "only red item is already listed")
+
+	assert.NoError(t, holderRepo.TransferItems(ctx, seller.ID, buyer.ID, good.ID, &blue.ID, 1), "expected blue item transfer")
+
+	inventory, err := holderRepo.Purchase().GetInventoryByUserID(ctx, buyer.ID)
+	assert.NoError(t, err, "failed to get buyer inventory")
+	assert.Equal(t, []models.Item{{Type: "cup", Variant: "blue", Quantity: 1}}, inventory)
+
+	listings, err := holderRepo.Listing().GetActive(ctx, "", "")
+	assert.NoError(t, err, "failed to get listings")
+
+	if assert.Len(t, listings, 1) {
+		assert.Equal(t, "red", listings[0].Variant)
+	}
+
+	assert.NoError(t, holderRepo.BuyListing(ctx, listing.ID, buyer.ID), "expected red listing purchase")
+
+	history, err := holderRepo.ItemTransfer().GetHistoryByUserID(ctx, buyer.ID)
+	assert.NoError(t, err, "failed to get buyer item history")
+	assert.ElementsMatch(t, []models.ReceivedItems{
+		{FromUser: "seller", Item: "cup", Variant: "blue", Quantity: 1},
+		{FromUser: "seller", Item: "cup", Variant: "red", Quantity: 1},
+	}, history.Received, "item history should keep transferred variants")
+}
@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/maksemen2/avito-shop/internal/database"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// NotificationRepository описывает операции с уведомлениями пользователей.
+type NotificationRepository interface {
+	Create(ctx context.Context, userID uint, notificationType, message string) error
+	CreateForWishlisters(ctx context.Context, goodID uint, notificationType, message string) (int64, error)
+	CreateForAll(ctx context.Context, notificationType, message string) (int64, error)
+	GetByUserID(ctx context.Context, userID uint, unreadOnly bool) ([]database.Notification, error)
+	CountUnread(ctx context.Context, userID uint) (int64, error)
+	MarkRead(ctx context.Context, userID, notificationID uint) error
+	MarkAllRead(ctx context.Context, userID uint) (int64, error)
+}
+
+// GormNotificationRepository реализует NotificationRepository.
+type GormNotificationRepository struct {
+	BaseRepository
+}
+
+func NewNotificationRepository(db *gorm.DB, logger *zap.Logger) NotificationRepository {
+	return &GormNotificationRepository{
+		BaseRepository: BaseRepository{
+			db:     db,
+			Logger: logger,
+		},
+	}
+}
+
+func (r *GormNotificationRepository) Create(ctx context.Context, userID uint, notificationType, message string) error {
+	if err := createNotificationTx(r.DB(ctx), userID, notificationType, message); err != nil {
+		r.Logger.Error("failed to create notification", zap.Uint("userID", userID), zap.String("type", notificationType), zap.Error(err))
+		return WrapError(ErrCreateNotification.Error(), err)
+	}
+
+	return nil
+}
+
+// CreateForWishlisters создаёт уведомление каждому пользователю, у которого товар goodID в списке желаний.
+// Возвращает количество созданных уведомлений.
+func (r *GormNotificationRepository) CreateForWishlisters(ctx context.Context, goodID uint, notificationType, message string) (int64, error) {
+	created, err := notifyWishlistersTx(r.DB(ctx), goodID, notificationType, message)
+	if err != nil {
+		r.Logger.Error("failed to notify wishlisters", zap.Uint("goodID", goodID), zap.String("type", notificationType), zap.Error(err))
+		return 0, WrapError(ErrCreateNotification.Error(), err)
+	}
+
+	return created, nil
+}
+
+// CreateForAll создаёт уведомление каждому пользователю и возвращает количество созданных уведомлений.
+func (r *GormNotificationRepository) CreateForAll(ctx context.Context, notificationType, message string) (int64, error) {
+	res := r.DB(ctx).Exec(
+		"INSERT INTO notifications (user_id, type, message, created_at) SELECT id, ?, ?, CURRENT_TIMESTAMP FROM users",
+		notificationType, message,
+	)
+	if res.Error != nil {
+		r.Logger.Error("failed to notify all users", zap.String("type", notificationType), zap.Error(res.Error))
+		return 0, WrapError(ErrCreateNotification.Error(), res.Error)
+	}
+
+	return res.RowsAffected, nil
+}
+
+// GetByUserID возвращает уведомления пользователя от новых к старым.
+// При unreadOnly возвращаются только непрочитанные уведомления.
+func (r *GormNotificationRepository) GetByUserID(ctx context.Context, userID uint, unreadOnly bool) ([]database.Notification, error) {
+	query := r.DB(ctx).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var notifications []database.Notification
+	if err := query.Order("created_at DESC, id DESC").Find(&notifications).Error; err != nil {
+		r.Logger.Error("failed to get notifications", zap.Uint("userID", userID), zap.Error(err))
+		return nil, WrapError(ErrGetNotifications.Error(), err)
+	}
+
+	return notifications, nil
+}
+
+func (r *GormNotificationRepository) CountUnread(ctx context.Context, userID uint) (int64, error) {
+	var unread int64
+	if err := r.DB(ctx).Model(&database.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&unread).Error; err != nil {
+		r.Logger.Error("failed to count unread notifications", zap.Uint("userID", userID), zap.Error(err))
+		return 0, WrapError(ErrGetNotifications.Error(), err)
+	}
+
+	return unread, nil
+}
+
+// MarkRead отмечает уведомление пользователя прочитанным. Повторная отметка не меняет время прочтения.
+func (r *GormNotificationRepository) MarkRead(ctx context.Context, userID, notificationID uint) error {
+	res := r.DB(ctx).Model(&database.Notification{}).
+		Where("id = ? AND user_id = ? AND read_at IS NULL", notificationID, userID).
+		UpdateColumn("read_at", time.Now())
+
+	if res.Error != nil {
+		r.Logger.Error("failed to mark notification read", zap.Uint("userID", userID), zap.Uint("notificationID", notificationID), zap.Error(res.Error))
+		return WrapError(ErrUpdateNotification.Error(), res.Error)
+	}
+
+	if res.RowsAffected > 0 {
+		return nil
+	}
+
+	// Ничего не обновилось: уведомление либо уже прочитано, либо не принадлежит пользователю
+	var notification database.Notification
+	if err := r.DB(ctx).Select("id").Where("id = ? AND user_id = ?", notificationID, userID).First(&notification).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotificationNotFound
+		}
+
+		r.Logger.Error("failed to get notification", zap.Uint("notificationID", notificationID), zap.Error(err))
+
+		return WrapError(ErrUpdateNotification.Error(), err)
+	}
+
+	return nil
+}
+
+// MarkAllRead отмечает прочитанными все уведомления пользователя и возвращает количество отмеченных.
+func (r *GormNotificationRepository) MarkAllRead(ctx context.Context, userID uint) (int64, error) {
+	res := r.DB(ctx).Model(&database.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		UpdateColumn("read_at", time.Now())
+
+	if res.Error != nil {
+		r.Logger.Error("failed to mark notifications read", zap.Uint("userID", userID), zap.Error(res.Error))
+		return 0, WrapError(ErrUpdateNotification.Error(), res.Error)
+	}
+
+	return res.RowsAffected, nil
+}
+
+// createNotificationTx создаёт уведомление пользователя в рамках транзакции tx.
+func createNotificationTx(tx *gorm.DB, userID uint, notificationType, message string) error {
+	return tx.Create(&database.Notification{
+		UserID:  userID,
+		Type:    notificationType,
+		Message: message,
+	}).Error
+}
+
+// notifyFromUserTx создаёт получателю toUserID уведомление о действии пользователя fromUserID.
+// Первым аргументом format получает имя fromUserID, следующими - args.
+func notifyFromUserTx(tx *gorm.DB, fromUserID, toUserID uint, notificationType, format string, args ...any) error {
+	username, err := usernameTx(tx, fromUserID)
+	if err != nil {
+		return err
+	}
+
+	return createNotificationTx(tx, toUserID, notificationType, fmt.Sprintf(format, append([]any{username}, args...)...))
+}
+
+// notifyWishlistersTx одним запросом создаёт уведомления всем, у кого товар goodID в списке желаний.
+func notifyWishlistersTx(tx *gorm.DB, goodID uint, notificationType, message string) (int64, error) {
+	res := tx.Exec(
+		"INSERT INTO notifications (user_id, type, message, created_at) "+
+			"SELECT user_id, ?, ?, CURRENT_TIMESTAMP FROM wishlist_items WHERE good_id = ?",
+		notificationType, message, goodID,
+	)
+
+	return res.RowsAffected, res.Error
+}
+
+// usernameTx возвращает имя пользователя userID в рамках транзакции tx.
+func usernameTx(tx *gorm.DB, userID uint) (string, error) {
+	var username string
+	err := tx.Model(&database.User{}).Select("username").Where("id = ?", userID).Scan(&username).Error
+
+	return username, err
+}
+
+// goodTypeTx возвращает название товара goodID в рамках транзакции tx.
+func goodTypeTx(tx *gorm.DB, goodID uint) (string, error) {
+	var goodType string
+	err := tx.Model(&database.Good{}).Select("type").Where("id = ?", goodID).Scan(&goodType).Error
+
+	return goodType, err
+}
+
+// variantNameTx возвращает название варианта variantID в рамках транзакции tx или пустую строку для товара без варианта.
+func variantNameTx(tx *gorm.DB, variantID *uint) (string, error) {
+	if variantID == nil {
+		return "", nil
+	}
+
+	var name string
+	err := tx.Model(&database.GoodVariant{}).Select("name").Where("id = ?", *variantID).Scan(&name).Error
+
+	return name, err
+}
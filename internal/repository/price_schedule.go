@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/maksemen2/avito-shop/internal/database"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// PriceScheduleRepository описывает операции с расписаниями цен товаров.
+type PriceScheduleRepository interface {
+	Create(ctx context.Context, schedule *database.PriceSchedule) error
+	GetAll(ctx context.Context) ([]database.PriceSchedule, error)
+	GetActive(ctx context.Context, now time.Time) ([]database.PriceSchedule, error)
+	GetActiveByGoodID(ctx context.Context, goodID uint, now time.Time) ([]database.PriceSchedule, error)
+	Delete(ctx context.Context, id uint) error
+	GetStartedUnnotified(ctx context.Context, now time.Time) ([]database.PriceSchedule, error)
+	NotifyStarted(ctx context.Context, schedule database.PriceSchedule, message string) (int64, error)
+}
+
+// GormPriceScheduleRepository реализует PriceScheduleRepository.
+type GormPriceScheduleRepository struct {
+	BaseRepository
+}
+
+func NewPriceScheduleRepository(db *gorm.DB, logger *zap.Logger) PriceScheduleRepository {
+	return &GormPriceScheduleRepository{
+		BaseRepository: BaseRepository{
+			db:     db,
+			Logger: logger,
+		},
+	}
+}
+
+func (r *GormPriceScheduleRepository) Create(ctx context.Context, schedule *database.PriceSchedule) error {
+	if err := r.DB(ctx).Create(schedule).Error; err != nil {
+		r.Logger.Error("failed to create price schedule", zap.Uint("goodID", schedule.GoodID), zap.Error(err))
+		return WrapError(ErrCreatePriceSchedule.Error(), err)
+	}
+
+	return nil
+}
+
+// GetAll возвращает все расписания цен вместе с товарами от ближайших к дальним.
+func (r *GormPriceScheduleRepository) GetAll(ctx context.Context) ([]database.PriceSchedule, error) {
+	var schedules []database.PriceSchedule
+	if err := r.DB(ctx).Preload("Good").Order("starts_at ASC, id ASC").Find(&schedules).Error; err != nil {
+		r.Logger.Error("failed to get price schedules", zap.Error(err))
+		return nil, WrapError(ErrGetPriceSchedule.Error(), err)
+	}
+
+	return schedules, nil
+}
+
+// GetActive возвращает расписания всех товаров, действующие в момент now.
+func (r *GormPriceScheduleRepository) GetActive(ctx context.Context, now time.Time) ([]database.PriceSchedule, error) {
+	var schedules []database.PriceSchedule
+	if err := r.DB(ctx).
+		Where("starts_at <= ? AND ends_at > ?", now, now).
+		Find(&schedules).Error; err != nil {
+		r.Logger.Error("failed to get active price schedules", zap.Error(err))
+		return nil, WrapError(ErrGetPriceSchedule.Error(), err)
+	}
+
+	return schedules, nil
+}
+
+// GetActiveByGoodID возвращает расписания товара goodID, действующие в момент now.
+func (r *GormPriceScheduleRepository) GetActiveByGoodID(ctx context.Context, goodID uint, now time.Time) ([]database.PriceSchedule, error) {
+	var schedules []database.PriceSchedule
+	if err := r.DB(ctx).
+		Where("good_id = ? AND starts_at <= ? AND ends_at > ?", goodID, now, now).
+		Find(&schedules).Error; err != nil {
+		r.Logger.Error("failed to get active price schedules", zap.Uint("goodID", goodID), zap.Error(err))
+		return nil, WrapError(ErrGetPriceSchedule.Error(), err)
+	}
+
+	return schedules, nil
+}
+
+func (r *GormPriceScheduleRepository) Delete(ctx context.Context, id uint) error {
+	res := r.DB(ctx).Delete(&database.PriceSchedule{}, id)
+	if res.Error != nil {
+		r.Logger.Error("failed to delete price schedule", zap.Uint("scheduleID", id), zap.Error(res.Error))
+		return WrapError(ErrDeletePriceSchedule.Error(), res.Error)
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrPriceScheduleNotFound
+	}
+
+	return nil
+}
+
+// GetStartedUnnotified возвращает действующие в момент now расписания, о которых ещё не уведомляли, вместе с товарами.
+func (r *GormPriceScheduleRepository) GetStartedUnnotified(ctx context.Context, now time.Time) ([]database.PriceSchedule, error) {
+	var schedules []database.PriceSchedule
+	if err := r.DB(ctx).Preload("Good").
+		Where("notified = ? AND starts_at <= ? AND ends_at > ?", false, now, now).
+		Order("id ASC").
+		Find(&schedules).Error; err != nil {
+		r.Logger.Error("failed to get unnotified price schedules", zap.Error(err))
+		return nil, WrapError(ErrGetPriceSchedule.Error(), err)
+	}
+
+	return schedules, nil
+}
+
+// NotifyStarted отмечает расписание как объявленное и уведомляет о нём всех, у кого товар в списке желаний.
+// Условие на флаг в самом UPDATE гарантирует, что уведомления по одному расписанию создаются один раз.
+// С пустым message расписание только отмечается, без уведомлений. Возвращает количество созданных уведомлений.
+func (r *GormPriceScheduleRepository) NotifyStarted(ctx context.Context, schedule database.PriceSchedule, message string) (int64, error) {
+	var created int64
+
+	err := r.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&database.PriceSchedule{}).
+			Where("id = ? AND notified = ?", schedule.ID, false).
+			UpdateColumn("notified", true)
+
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+
+		if message == "" {
+			return nil
+		}
+
+		var err error
+		created, err = notifyWishlistersTx(tx, schedule.GoodID, database.NotificationSale, message)
+
+		return err
+	})
+
+	if err != nil {
+		r.Logger.Error("failed to notify about price schedule", zap.Uint("scheduleID", schedule.ID), zap.Error(err))
+		return 0, WrapError(ErrCreateNotification.Error(), err)
+	}
+
+	return created, nil
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/maksemen2/avito-shop/internal/database"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// PromoCodeRepository описывает операции для работы с промокодами.
+type PromoCodeRepository interface {
+	Create(ctx context.Context, promo *database.PromoCode) error
+	GetByCode(ctx context.Context, code string) (*database.PromoCode, error)
+	GetAll(ctx context.Context) ([]database.PromoCode, error)
+	SetActive(ctx context.Context, id uint, active bool) error
+}
+
+// GormPromoCodeRepository реализует PromoCodeRepository.
+type GormPromoCodeRepository struct {
+	BaseRepository
+}
+
+func NewPromoCodeRepository(db *gorm.DB, logger *zap.Logger) PromoCodeRepository {
+	return &GormPromoCodeRepository{
+		BaseRepository: BaseRepository{
+			db:     db,
+			Logger: logger,
+		},
+	}
+}
+
+func (r *GormPromoCodeRepository) Create(ctx context.Context, promo *database.PromoCode) error {
+	err := r.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&database.PromoCode{}).Where("code = ?", promo.Code).Count(&count).Error; err != nil {
+			return err
+		}
+
+		if count > 0 {
+			return ErrPromoCodeExists
+		}
+
+		return tx.Create(promo).Error
+	})
+
+	if err != nil {
+		if errors.Is(err, ErrPromoCodeExists) {
+			return err
+		}
+
+		r.Logger.Error("failed to create promo code", zap.String("code", promo.Code), zap.Error(err))
+
+		return WrapError(ErrCreatePromoCode.Error(), err)
+	}
+
+	return nil
+}
+
+func (r *GormPromoCodeRepository) GetByCode(ctx context.Context, code string) (*database.PromoCode, error) {
+	var promo database.PromoCode
+	if err := r.DB(ctx).Where("code = ?", code).First(&promo).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPromoCodeNotFound
+		}
+
+		r.Logger.Error("failed to get promo code", zap.String("code", code), zap.Error(err))
+
+		return nil, WrapError(ErrGetPromoCode.Error(), err)
+	}
+
+	return &promo, nil
+}
+
+// GetAll возвращает все промокоды вместе с товарами, на которые они действуют.
+func (r *GormPromoCodeRepository) GetAll(ctx context.Context) ([]database.PromoCode, error) {
+	var promos []database.PromoCode
+	if err := r.DB(ctx).Preload("Good").Order("id ASC").Find(&promos).Error; err != nil {
+		r.Logger.Error("failed to get promo codes", zap.Error(err))
+		return nil, WrapError(ErrGetPromoCode.Error(), err)
+	}
+
+	return promos, nil
+}
+
+func (r *GormPromoCodeRepository) SetActive(ctx context.Context, id uint, active bool) error {
+	res := r.DB(ctx).Model(&database.PromoCode{}).Where("id = ?", id).Update("active", active)
+	if res.Error != nil {
+		r.Logger.Error("failed to update promo code", zap.Uint("promoCodeID", id), zap.Error(res.Error))
+		return WrapError(ErrUpdatePromoCode.Error(), res.Error)
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrPromoCodeNotFound
+	}
+
+	return nil
+}
+
+// redeemPromoCodeTx учитывает применение промокода пользователем в рамках транзакции tx.
+// Должна вызываться после списания баланса покупателя: строка пользователя к этому моменту заблокирована,
+// поэтому его конкурентные покупки не обойдут лимит на пользователя.
+func redeemPromoCodeTx(tx *gorm.DB, promo *database.PromoCode, userID, purchaseID uint) error {
+	if promo.MaxUsesPerUser > 0 {
+		var used int64
+		if err := tx.Model(&database.PromoRedemption{}).
+			Where("promo_code_id = ? AND user_id = ?", promo.ID, userID).
+			Count(&used).Error; err != nil {
+			return err
+		}
+
+		if used >= int64(promo.MaxUsesPerUser) {
+			return ErrPromoCodeUserLimit
+		}
+	}
+
+	// Условие на счётчик в самом UPDATE не даст превысить общий лимит конкурентными покупками
+	res := tx.Model(&database.PromoCode{}).
+		Where("id = ? AND active = ? AND (max_uses = 0 OR used_count < max_uses)", promo.ID, true).
+		UpdateColumn("used_count", gorm.Expr("used_count + 1"))
+
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrPromoCodeExhausted
+	}
+
+	return tx.Create(&database.PromoRedemption{
+		PromoCodeID: promo.ID,
+		UserID:      userID,
+		PurchaseID:  purchaseID,
+	}).Error
+}
@@ -11,6 +11,7 @@ import (
 
 type PurchaseRepository interface {
 	GetInventoryByUserID(ctx context.Context, userID uint) ([]models.Item, error)
+	GetGiftHistoryByUserID(ctx context.Context, userID uint) (models.GiftHistory, error)
 }
 
 type GormPurchaseRepository struct {
@@ -26,15 +27,25 @@ func NewPurchaseRepository(db *gorm.DB, logger *zap.Logger) PurchaseRepository {
 	}
 }
 
+// whereVariant ограничивает выборку единиц товара вариантом variantID, пустой variantID выбирает единицы без варианта.
+func whereVariant(query *gorm.DB, variantID *uint) *gorm.DB {
+	if variantID == nil {
+		return query.Where("variant_id IS NULL")
+	}
+
+	return query.Where("variant_id = ?", *variantID)
+}
+
 func (r *GormPurchaseRepository) GetInventoryByUserID(ctx context.Context, userID uint) ([]models.Item, error) {
 	var items []models.Item
-	if err := r.DB(ctx).
+	if err := r.ReadDB(ctx).
 		Model(&database.Purchase{}).
-		Select("goods.type as type, COUNT(purchases.id) as quantity").
+		Select("goods.type as type, COALESCE(good_variants.name, '') as variant, COUNT(purchases.id) as quantity").
 		Joins("LEFT JOIN goods ON goods.id = purchases.good_id").
+		Joins("LEFT JOIN good_variants ON good_variants.id = purchases.variant_id").
 		Where("purchases.user_id = ?", userID).
-		Group("goods.type").
-		Order("goods.type ASC").
+		Group("goods.type, good_variants.name").
+		Order("goods.type ASC, good_variants.name ASC").
 		Scan(&items).Error; err != nil {
 		r.Logger.Error("failed to get inventory", zap.Uint("userID", userID), zap.Error(err))
 		return nil, WrapError(ErrGetInventory.Error(), err)
@@ -46,3 +57,46 @@ func (r *GormPurchaseRepository) GetInventoryByUserID(ctx context.Context, userI
 
 	return items, nil
 }
+
+// GetGiftHistoryByUserID возвращает подарки по дарителю и получателю, записанным при дарении,
+// поэтому история не меняется, когда подаренный товар передают или перепродают.
+func (r *GormPurchaseRepository) GetGiftHistoryByUserID(ctx context.Context, userID uint) (models.GiftHistory, error) {
+	var received []models.ReceivedGift
+
+	var sent []models.SentGift
+
+	if err := r.ReadDB(ctx).Model(&database.Purchase{}).
+		Select("users.username as from_user, goods.type as item").
+		Joins("JOIN users ON purchases.gifted_by_id = users.id").
+		Joins("JOIN goods ON purchases.good_id = goods.id").
+		Where("purchases.gifted_to_id = ?", userID).
+		Order("purchases.created_at DESC").
+		Scan(&received).Error; err != nil {
+		r.Logger.Error("failed to get received gifts", zap.Uint("userID", userID), zap.Error(err))
+		return models.GiftHistory{}, WrapError(ErrGetHistory.Error(), err)
+	}
+
+	if err := r.ReadDB(ctx).Model(&database.Purchase{}).
+		Select("users.username as to_user, goods.type as item").
+		Joins("JOIN users ON purchases.gifted_to_id = users.id").
+		Joins("JOIN goods ON purchases.good_id = goods.id").
+		Where("purchases.gifted_by_id = ?", userID).
+		Order("purchases.created_at DESC").
+		Scan(&sent).Error; err != nil {
+		r.Logger.Error("failed to get sent gifts", zap.Uint("userID", userID), zap.Error(err))
+		return models.GiftHistory{}, WrapError(ErrGetHistory.Error(), err)
+	}
+
+	if received == nil {
+		received = make([]models.ReceivedGift, 0)
+	}
+
+	if sent == nil {
+		sent = make([]models.SentGift, 0)
+	}
+
+	return models.GiftHistory{
+		Received: received,
+		Sent:     sent,
+	}, nil
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+type primaryReadsKey struct{}
+
+// WithPrimaryReads помечает контекст так, что чтения, обычно идущие в реплики, выполняются на основной базе.
+// Нужен сразу после записи, когда реплика могла ещё не получить изменения.
+func WithPrimaryReads(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryReadsKey{}, true)
+}
+
+// PrimaryReads сообщает, требует ли контекст чтения с основной базы.
+func PrimaryReads(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryReadsKey{}).(bool)
+	return primary
+}
+
+// readReplicas распределяет чтения по репликам по кругу.
+type readReplicas struct {
+	dbs  []*gorm.DB
+	next atomic.Uint64
+}
+
+func newReadReplicas(dbs []*gorm.DB) *readReplicas {
+	if len(dbs) == 0 {
+		return nil
+	}
+
+	return &readReplicas{dbs: dbs}
+}
+
+func (r *readReplicas) pick() *gorm.DB {
+	return r.dbs[(r.next.Add(1)-1)%uint64(len(r.dbs))]
+}
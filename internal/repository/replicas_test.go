@@ -0,0 +1,140 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+func openTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: gormLogger.Default.LogMode(gormLogger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open in-memory database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&database.User{}, &database.Purchase{}, &database.Transaction{}, &database.Good{}, &database.CoinRequest{}, &database.GrantProgram{}, &database.Grant{}, &database.CoinLot{}, &database.CoinExpiration{}, &database.ItemTransfer{}, &database.Listing{}, &database.PromoCode{}, &database.PromoRedemption{}, &database.PriceSchedule{}, &database.GoodVariant{}, &database.WishlistItem{}, &database.Notification{}, &database.OutboxEvent{}, &database.Webhook{}, &database.WebhookDelivery{}); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	return db
+}
+
+func TestReadReplicas(t *testing.T) {
+	primary := openTestDB(t)
+	replica := openTestDB(t)
+	ctx := context.Background()
+
+	// Реплика отстаёт: баланс и цена в ней ещё старые
+	assert.NoError(t, primary.Create(&database.User{Username: "test1", Coins: 70}).Error)
+	assert.NoError(t, replica.Create(&database.User{Username: "test1", Coins: 100}).Error)
+	assert.NoError(t, primary.Create(&database.Good{Type: "cup", Price: 30}).Error)
+	assert.NoError(t, replica.Create(&database.Good{Type: "cup", Price: 20}).Error)
+
+	holderRepo := repository.NewHolderRepository(primary, zap.NewNop(), repository.WithReadReplicas(replica))
+
+	balance, err := holderRepo.User().GetBalance(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 100, balance, "balance should be read from the replica")
+
+	good, err := holderRepo.Good().GetByName(ctx, "cup")
+	assert.NoError(t, err)
+	assert.Equal(t, 20, good.Price, "good should be read from the replica")
+
+	balance, err = holderRepo.User().GetBalance(repository.WithPrimaryReads(ctx), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 70, balance, "forced read should go to the primary")
+
+	// Остальные чтения и все записи идут в основную базу
+	user, err := holderRepo.User().GetByUsername(ctx, "test1")
+	assert.NoError(t, err)
+	assert.Equal(t, 70, user.Coins)
+
+	assert.NoError(t, holderRepo.AdjustCoins(ctx, 1, 5, ""))
+
+	var updated database.User
+
+	assert.NoError(t, replica.First(&updated, 1).Error)
+	assert.Equal(t, 100, updated.Coins, "writes must not reach the replica")
+}
+
+func TestReadReplicas_IsLocked(t *testing.T) {
+	primary := openTestDB(t)
+	replica := openTestDB(t)
+	ctx := context.Background()
+
+	assert.NoError(t, primary.Create(&database.User{Username: "test1"}).Error)
+	assert.NoError(t, replica.Create(&database.User{Username: "test1", Locked: true}).Error)
+	assert.NoError(t, primary.Create(&database.User{Username: "test2"}).Error)
+
+	holderRepo := repository.NewHolderRepository(primary, zap.NewNop(), repository.WithReadReplicas(replica))
+
+	locked, err := holderRepo.User().IsLocked(ctx, 1)
+	assert.NoError(t, err)
+	assert.True(t, locked, "lock flag should be read from the replica")
+
+	// Пользователя, которого реплика ещё не получила, ищем на основной базе
+	locked, err = holderRepo.User().IsLocked(ctx, 2)
+	assert.NoError(t, err)
+	assert.False(t, locked, "user missing on the replica should be read from the primary")
+
+	_, err = holderRepo.User().IsLocked(ctx, 3)
+	assert.ErrorIs(t, err, repository.ErrUserNotFound)
+}
+
+func TestReadReplicas_InfoHistories(t *testing.T) {
+	primary := openTestDB(t)
+	replica := openTestDB(t)
+	ctx := context.Background()
+
+	// Истории есть только на реплике, поэтому по ответу видно, откуда они прочитаны
+	for _, db := range []*gorm.DB{primary, replica} {
+		assert.NoError(t, db.Create(&database.User{Username: "test1"}).Error)
+		assert.NoError(t, db.Create(&database.User{Username: "test2"}).Error)
+		assert.NoError(t, db.Create(&database.Good{Type: "cup", Price: 20}).Error)
+	}
+
+	sender, receiver := uint(1), uint(2)
+	program := database.GrantProgram{Name: "monthly", Amount: 50, Period: "monthly"}
+
+	assert.NoError(t, replica.Create(&program).Error)
+	assert.NoError(t, replica.Create(&database.Grant{ProgramID: program.ID, UserID: receiver, Amount: 50, Period: "2026-10"}).Error)
+	assert.NoError(t, replica.Create(&database.Transaction{FromUserID: sender, ToUserID: receiver, Amount: 10, Kind: database.TransactionKindTransfer}).Error)
+	assert.NoError(t, replica.Create(&database.ItemTransfer{FromUserID: &sender, ToUserID: &receiver, GoodID: 1, Quantity: 1}).Error)
+	assert.NoError(t, replica.Create(&database.CoinExpiration{UserID: receiver, Amount: 5}).Error)
+
+	holderRepo := repository.NewHolderRepository(primary, zap.NewNop(), repository.WithReadReplicas(replica))
+
+	for _, tc := range []struct {
+		ctx      context.Context
+		expected int
+		msg      string
+	}{
+		{ctx, 1, "histories should be read from the replica"},
+		{repository.WithPrimaryReads(ctx), 0, "forced reads should go to the primary"},
+	} {
+		page, err := holderRepo.Transaction().GetTransfersPage(tc.ctx, receiver, 10, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(tc.expected), page.Total, tc.msg)
+
+		granted, err := holderRepo.Grant().GetHistoryByUserID(tc.ctx, receiver)
+		assert.NoError(t, err)
+		assert.Len(t, granted, tc.expected, tc.msg)
+
+		expired, err := holderRepo.CoinLot().GetExpirationHistory(tc.ctx, receiver)
+		assert.NoError(t, err)
+		assert.Len(t, expired, tc.expected, tc.msg)
+
+		items, err := holderRepo.ItemTransfer().GetHistoryByUserID(tc.ctx, receiver)
+		assert.NoError(t, err)
+		assert.Len(t, items.Received, tc.expected, tc.msg)
+	}
+}
@@ -2,7 +2,9 @@ package repository
 
 import (
 	"context"
+	"errors"
 
+	"github.com/maksemen2/avito-shop/internal/database"
 	"github.com/maksemen2/avito-shop/internal/models"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -10,7 +12,9 @@ import (
 
 // TransactionRepository описывает операции для получения истории транзакций.
 type TransactionRepository interface {
+	GetByID(ctx context.Context, id uint) (*database.Transaction, error)
 	GetHistoryByUserID(ctx context.Context, userID uint) (models.CoinHistory, error)
+	GetTransfersPage(ctx context.Context, userID uint, limit, offset int) (models.TransferHistoryPage, error)
 }
 
 // GormTransactionRepository реализует TransactionRepository.
@@ -27,12 +31,27 @@ func NewTransactionRepository(db *gorm.DB, logger *zap.Logger) TransactionReposi
 	}
 }
 
+func (r *GormTransactionRepository) GetByID(ctx context.Context, id uint) (*database.Transaction, error) {
+	var transaction database.Transaction
+	if err := r.DB(ctx).First(&transaction, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTransactionNotFound
+		}
+
+		r.Logger.Error("failed to get transaction", zap.Uint("transactionID", id), zap.Error(err))
+
+		return nil, WrapError(ErrGetTransaction.Error(), err)
+	}
+
+	return &transaction, nil
+}
+
 func (r *GormTransactionRepository) GetHistoryByUserID(ctx context.Context, userID uint) (models.CoinHistory, error) {
 	var received []models.ReceivedCoins
 
 	var sent []models.SentCoins
 
-	if err := r.DB(ctx).Table("transactions").
+	if err := r.ReadDB(ctx).Table("transactions").
 		Select("users.username as from_user, transactions.amount, transactions.created_at").
 		Joins("JOIN users ON transactions.from_user_id = users.id").
 		Where("transactions.to_user_id = ?", userID).
@@ -42,7 +61,7 @@ func (r *GormTransactionRepository) GetHistoryByUserID(ctx context.Context, user
 		return models.CoinHistory{}, WrapError(ErrGetHistory.Error(), err)
 	}
 
-	if err := r.DB(ctx).Table("transactions").
+	if err := r.ReadDB(ctx).Table("transactions").
 		Select("users.username as to_user, transactions.amount, transactions.created_at").
 		Joins("JOIN users ON transactions.to_user_id = users.id").
 		Where("transactions.from_user_id = ?", userID).
@@ -65,3 +84,36 @@ func (r *GormTransactionRepository) GetHistoryByUserID(ctx context.Context, user
 		Sent:     sent,
 	}, nil
 }
+
+// GetTransfersPage возвращает входящие и исходящие переводы пользователя от новых к старым
+// вместе с общим числом переводов для постраничной навигации.
+func (r *GormTransactionRepository) GetTransfersPage(ctx context.Context, userID uint, limit, offset int) (models.TransferHistoryPage, error) {
+	var total int64
+	if err := r.ReadDB(ctx).Model(&database.Transaction{}).
+		Where("from_user_id = ? OR to_user_id = ?", userID, userID).
+		Count(&total).Error; err != nil {
+		r.Logger.Error("failed to count transfers", zap.Uint("userID", userID), zap.Error(err))
+		return models.TransferHistoryPage{}, WrapError(ErrGetHistory.Error(), err)
+	}
+
+	transfers := make([]models.Transfer, 0, limit)
+
+	if err := r.ReadDB(ctx).Table("transactions").
+		Select("transactions.id, CASE WHEN transactions.to_user_id = ? THEN ? ELSE ? END AS direction, "+
+			"users.username AS counterparty, transactions.amount, transactions.created_at",
+			userID, models.TransferDirectionReceived, models.TransferDirectionSent).
+		Joins("JOIN users ON users.id = CASE WHEN transactions.to_user_id = ? THEN transactions.from_user_id ELSE transactions.to_user_id END", userID).
+		Where("transactions.from_user_id = ? OR transactions.to_user_id = ?", userID, userID).
+		Order("transactions.created_at DESC, transactions.id DESC").
+		Limit(limit).
+		Offset(offset).
+		Scan(&transfers).Error; err != nil {
+		r.Logger.Error("failed to get transfers page", zap.Uint("userID", userID), zap.Error(err))
+		return models.TransferHistoryPage{}, WrapError(ErrGetHistory.Error(), err)
+	}
+
+	return models.TransferHistoryPage{
+		Transfers: transfers,
+		Total:     total,
+	}, nil
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/internal/models"
 	"github.com/maksemen2/avito-shop/internal/repository"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
@@ -79,3 +80,41 @@ func TestGetHistoryByUserID_Success(t *testing.T) {
 	assert.Equal(t, bob.Username, sentTx.ToUser, "expected receiver username to match")
 	assert.Equal(t, tx2.Amount, sentTx.Amount, "expected amount to match")
 }
+
+func TestGetTransfersPage(t *testing.T) {
+	txRepo, db := setupTestTransactionRepository(t)
+	ctx := context.Background()
+
+	alice := database.User{Username: "alice", Coins: 100}
+	bob := database.User{Username: "bob", Coins: 50}
+	carol := database.User{Username: "carol", Coins: 50}
+
+	assert.NoError(t, db.Create(&alice).Error, "failed to create alice")
+	assert.NoError(t, db.Create(&bob).Error, "failed to create bob")
+	assert.NoError(t, db.Create(&carol).Error, "failed to create carol")
+
+	now := time.Now()
+	transactions := []database.Transaction{
+		{FromUserID: bob.ID, ToUserID: alice.ID, Amount: 10, CreatedAt: now.Add(-3 * time.Minute)},
+		{FromUserID: alice.ID, ToUserID: carol.ID, Amount: 20, CreatedAt: now.Add(-2 * time.Minute)},
+		{FromUserID: bob.ID, ToUserID: carol.ID, Amount: 30, CreatedAt: now.Add(-time.Minute)},
+		{FromUserID: carol.ID, ToUserID: alice.ID, Amount: 40, CreatedAt: now},
+	}
+	assert.NoError(t, db.Create(&transactions).Error, "failed to create transactions")
+
+	page, err := txRepo.GetTransfersPage(ctx, alice.ID, 2, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), page.Total, "transfers of other users should not be counted")
+	assert.Len(t, page.Transfers, 2)
+	assert.Equal(t, models.TransferDirectionReceived, page.Transfers[0].Direction)
+	assert.Equal(t, "carol", page.Transfers[0].Counterparty)
+	assert.Equal(t, 40, page.Transfers[0].Amount)
+	assert.Equal(t, models.TransferDirectionSent, page.Transfers[1].Direction)
+	assert.Equal(t, "carol", page.Transfers[1].Counterparty)
+
+	page, err = txRepo.GetTransfersPage(ctx, alice.ID, 2, 2)
+	assert.NoError(t, err)
+	assert.Len(t, page.Transfers, 1)
+	assert.Equal(t, "bob", page.Transfers[0].Counterparty)
+	assert.Equal(t, 10, page.Transfers[0].Amount)
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/maksemen2/avito-shop/internal/database"
 	"go.uber.org/zap"
@@ -16,6 +17,9 @@ type UserRepository interface {
 	GetByUsername(ctx context.Context, username string) (*database.User, error)
 	GetBalance(ctx context.Context, id uint) (int, error)
 	GetIDByUsername(ctx context.Context, username string) (uint, error)
+	IsAdmin(ctx context.Context, id uint) (bool, error)
+	IsLocked(ctx context.Context, id uint) (bool, error)
+	SetLocked(ctx context.Context, id uint, locked bool) error
 }
 
 // GormUserRepository – реализация UserRepository для GORM.
@@ -38,7 +42,16 @@ func (r *GormUserRepository) Create(ctx context.Context, username, passwordHash
 		PasswordHash: passwordHash,
 	}
 
-	if err := r.DB(ctx).Create(user).Error; err != nil {
+	err := r.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+
+		// Стартовые монеты - первая партия пользователя, у неё такой же срок жизни, как у остальных
+		return addCoinLotTx(tx, user.ID, database.CoinSourceInitial, user.Coins, r.coinLotExpiry(time.Now()))
+	})
+
+	if err != nil {
 		r.Logger.Error("failed to create user", zap.String("username", username), zap.Error(err))
 		return nil, WrapError(ErrCreateUser.Error(), err)
 	}
@@ -78,7 +91,7 @@ func (r *GormUserRepository) GetByUsername(ctx context.Context, username string)
 
 func (r *GormUserRepository) GetBalance(ctx context.Context, id uint) (int, error) {
 	var balance int
-	if err := r.DB(ctx).Model(&database.User{}).Select("coins").Where("id = ?", id).Scan(&balance).Error; err != nil {
+	if err := r.ReadDB(ctx).Model(&database.User{}).Select("coins").Where("id = ?", id).Scan(&balance).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return 0, ErrUserNotFound
 		}
@@ -109,3 +122,58 @@ func (r *GormUserRepository) GetIDByUsername(ctx context.Context, username strin
 
 	return user.ID, nil
 }
+
+func (r *GormUserRepository) IsAdmin(ctx context.Context, id uint) (bool, error) {
+	var user database.User
+	if err := r.DB(ctx).Select("is_admin").Where("id = ?", id).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, ErrUserNotFound
+		}
+
+		r.Logger.Error("failed to get user role", zap.Uint("userID", id), zap.Error(err))
+
+		return false, WrapError(ErrGetUser.Error(), err)
+	}
+
+	return user.IsAdmin, nil
+}
+
+// IsLocked читает флаг блокировки с реплики: проверка выполняется на каждый запрос и не должна нагружать основную базу.
+// Отставание реплики не ослабляет блокировку, так как списания монет проверяют её ещё раз в своей транзакции.
+// Только что созданного пользователя реплика может ещё не знать, поэтому при его отсутствии запрос повторяется на основной базе.
+func (r *GormUserRepository) IsLocked(ctx context.Context, id uint) (bool, error) {
+	var user database.User
+
+	err := r.ReadDB(ctx).Select("locked").Where("id = ?", id).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) && r.replicas != nil {
+		err = r.DB(ctx).Select("locked").Where("id = ?", id).First(&user).Error
+	}
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, ErrUserNotFound
+		}
+
+		r.Logger.Error("failed to get user lock", zap.Uint("userID", id), zap.Error(err))
+
+		return false, WrapError(ErrGetUser.Error(), err)
+	}
+
+	return user.Locked, nil
+}
+
+// SetLocked блокирует или разблокирует пользователя. Заблокированный пользователь не может получить новый токен,
+// уже выданные ему токены перестают приниматься, а списания монет с него отклоняются с ErrUserLocked.
+func (r *GormUserRepository) SetLocked(ctx context.Context, id uint, locked bool) error {
+	res := r.DB(ctx).Model(&database.User{}).Where("id = ?", id).UpdateColumn("locked", locked)
+	if res.Error != nil {
+		r.Logger.Error("failed to update user lock", zap.Uint("userID", id), zap.Error(res.Error))
+		return WrapError(ErrUpdateUser.Error(), res.Error)
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/maksemen2/avito-shop/internal/models"
+)
+
+// UserChange описывает операцию, изменившую баланс, инвентарь или историю пользователей UserIDs.
+// Transfers и Purchases перечисляют переводы монет и покупки товаров, совершённые операцией.
+type UserChange struct {
+	UserIDs   []uint
+	Transfers []TransferChange
+	Purchases []PurchaseChange
+}
+
+// TransferChange - перевод монет пользователю ToUserID.
+type TransferChange struct {
+	ToUserID uint
+	Event    models.TransferEvent
+}
+
+// PurchaseChange - покупка товара пользователем BuyerID, в том числе в подарок или по объявлению.
+type PurchaseChange struct {
+	BuyerID uint
+	Event   models.PurchaseEvent
+}
+
+// UserChangeHook вызывается после фиксации операции change.
+// Через holder хук может дочитать нужные ему данные, например актуальный баланс пользователей.
+type UserChangeHook func(ctx context.Context, holder HolderRepository, change UserChange)
+
+// usersChanged сообщает об изменении change, если операция завершилась без ошибки, и возвращает err.
+func (r *BaseRepository) usersChanged(ctx context.Context, err error, change UserChange) error {
+	if err == nil && r.onUserChange != nil && len(change.UserIDs) > 0 {
+		r.onUserChange(ctx, change)
+	}
+
+	return err
+}
+
+func (r *BaseRepository) setUserChangeHook(hook func(ctx context.Context, change UserChange)) {
+	r.onUserChange = hook
+}
+
+// usersOnly возвращает изменение пользователей userIDs без переводов и покупок.
+func usersOnly(userIDs ...uint) UserChange {
+	return UserChange{UserIDs: userIDs}
+}
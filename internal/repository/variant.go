@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/maksemen2/avito-shop/internal/database"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// GoodVariantRepository описывает операции с вариантами товаров.
+type GoodVariantRepository interface {
+	Create(ctx context.Context, variant *database.GoodVariant) error
+	GetByName(ctx context.Context, goodID uint, name string) (*database.GoodVariant, error)
+	GetByGoodID(ctx context.Context, goodID uint) ([]database.GoodVariant, error)
+	GetAll(ctx context.Context) ([]database.GoodVariant, error)
+	Restock(ctx context.Context, id uint, quantity int) (*database.GoodVariant, error)
+}
+
+// GormGoodVariantRepository реализует GoodVariantRepository.
+type GormGoodVariantRepository struct {
+	BaseRepository
+}
+
+func NewGoodVariantRepository(db *gorm.DB, logger *zap.Logger) GoodVariantRepository {
+	return &GormGoodVariantRepository{
+		BaseRepository: BaseRepository{
+			db:     db,
+			Logger: logger,
+		},
+	}
+}
+
+func (r *GormGoodVariantRepository) Create(ctx context.Context, variant *database.GoodVariant) error {
+	err := r.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&database.GoodVariant{}).
+			Where("good_id = ? AND name = ?", variant.GoodID, variant.Name).
+			Count(&count).Error; err != nil {
+			return err
+		}
+
+		if count > 0 {
+			return ErrVariantExists
+		}
+
+		return tx.Create(variant).Error
+	})
+
+	if err != nil {
+		if errors.Is(err, ErrVariantExists) {
+			return err
+		}
+
+		r.Logger.Error("failed to create good variant", zap.Uint("goodID", variant.GoodID), zap.String("name", variant.Name), zap.Error(err))
+
+		return WrapError(ErrCreateVariant.Error(), err)
+	}
+
+	return nil
+}
+
+func (r *GormGoodVariantRepository) GetByName(ctx context.Context, goodID uint, name string) (*database.GoodVariant, error) {
+	var variant database.GoodVariant
+	if err := r.DB(ctx).Where("good_id = ? AND name = ?", goodID, name).First(&variant).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrVariantNotFound
+		}
+
+		r.Logger.Error("failed to get good variant", zap.Uint("goodID", goodID), zap.String("name", name), zap.Error(err))
+
+		return nil, WrapError(ErrGetVariant.Error(), err)
+	}
+
+	return &variant, nil
+}
+
+func (r *GormGoodVariantRepository) GetByGoodID(ctx context.Context, goodID uint) ([]database.GoodVariant, error) {
+	var variants []database.GoodVariant
+	if err := r.DB(ctx).Where("good_id = ?", goodID).Order("id ASC").Find(&variants).Error; err != nil {
+		r.Logger.Error("failed to get good variants", zap.Uint("goodID", goodID), zap.Error(err))
+		return nil, WrapError(ErrGetVariant.Error(), err)
+	}
+
+	return variants, nil
+}
+
+func (r *GormGoodVariantRepository) GetAll(ctx context.Context) ([]database.GoodVariant, error) {
+	var variants []database.GoodVariant
+	if err := r.DB(ctx).Order("good_id ASC, id ASC").Find(&variants).Error; err != nil {
+		r.Logger.Error("failed to get good variants", zap.Error(err))
+		return nil, WrapError(ErrGetVariant.Error(), err)
+	}
+
+	return variants, nil
+}
+
+// Restock пополняет остаток варианта на quantity единиц и возвращает обновлённый вариант.
+func (r *GormGoodVariantRepository) Restock(ctx context.Context, id uint, quantity int) (*database.GoodVariant, error) {
+	var variant database.GoodVariant
+
+	err := r.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&database.GoodVariant{}).
+			Where("id = ?", id).
+			UpdateColumn("stock", gorm.Expr("stock + ?", quantity))
+
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return ErrVariantNotFound
+		}
+
+		return tx.First(&variant, id).Error
+	})
+
+	if err != nil {
+		if errors.Is(err, ErrVariantNotFound) {
+			return nil, err
+		}
+
+		r.Logger.Error("failed to restock good variant", zap.Uint("variantID", id), zap.Error(err))
+
+		return nil, WrapError(ErrUpdateVariant.Error(), err)
+	}
+
+	return &variant, nil
+}
@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// WebhookRepository описывает операции с вебхуками и доставками событий из outbox.
+type WebhookRepository interface {
+	Create(ctx context.Context, webhook *database.Webhook) error
+	GetAll(ctx context.Context) ([]database.Webhook, error)
+	SetActive(ctx context.Context, id uint, active bool) error
+	GetDeliveries(ctx context.Context, status string) ([]database.WebhookDelivery, error)
+	GetDueDeliveries(ctx context.Context, now time.Time, limit int) ([]database.WebhookDelivery, error)
+	Claim(ctx context.Context, delivery *database.WebhookDelivery, now, leaseUntil time.Time) (bool, error)
+	MarkDelivered(ctx context.Context, id uint, deliveredAt time.Time) error
+	MarkFailed(ctx context.Context, id uint, attempts int, status string, nextAttemptAt time.Time, lastError string) error
+	Retry(ctx context.Context, id uint, now time.Time) error
+}
+
+// GormWebhookRepository реализует WebhookRepository.
+type GormWebhookRepository struct {
+	BaseRepository
+}
+
+func NewWebhookRepository(db *gorm.DB, logger *zap.Logger) WebhookRepository {
+	return &GormWebhookRepository{
+		BaseRepository: BaseRepository{
+			db:     db,
+			Logger: logger,
+		},
+	}
+}
+
+func (r *GormWebhookRepository) Create(ctx context.Context, webhook *database.Webhook) error {
+	if err := r.DB(ctx).Create(webhook).Error; err != nil {
+		r.Logger.Error("failed to create webhook", zap.String("url", webhook.URL), zap.Error(err))
+		return WrapError(ErrCreateWebhook.Error(), err)
+	}
+
+	return nil
+}
+
+func (r *GormWebhookRepository) GetAll(ctx context.Context) ([]database.Webhook, error) {
+	var webhooks []database.Webhook
+	if err := r.DB(ctx).Order("id ASC").Find(&webhooks).Error; err != nil {
+		r.Logger.Error("failed to get webhooks", zap.Error(err))
+		return nil, WrapError(ErrGetWebhook.Error(), err)
+	}
+
+	return webhooks, nil
+}
+
+func (r *GormWebhookRepository) SetActive(ctx context.Context, id uint, active bool) error {
+	res := r.DB(ctx).Model(&database.Webhook{}).Where("id = ?", id).Update("active", active)
+	if res.Error != nil {
+		r.Logger.Error("failed to update webhook", zap.Uint("webhookID", id), zap.Error(res.Error))
+		return WrapError(ErrUpdateWebhook.Error(), res.Error)
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// GetDeliveries возвращает доставки со статусом status от новых к старым. Пустой status возвращает все доставки.
+func (r *GormWebhookRepository) GetDeliveries(ctx context.Context, status string) ([]database.WebhookDelivery, error) {
+	query := r.DB(ctx).Preload("Event")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var deliveries []database.WebhookDelivery
+	if err := query.Order("id DESC").Find(&deliveries).Error; err != nil {
+		r.Logger.Error("failed to get webhook deliveries", zap.String("status", status), zap.Error(err))
+		return nil, WrapError(ErrGetWebhookDelivery.Error(), err)
+	}
+
+	return deliveries, nil
+}
+
+// GetDueDeliveries возвращает не более limit ожидающих доставок активным вебхукам, время попытки которых наступило.
+// Доставки возвращаются вместе с вебхуком и событием, от старых к новым.
+func (r *GormWebhookRepository) GetDueDeliveries(ctx context.Context, now time.Time, limit int) ([]database.WebhookDelivery, error) {
+	var deliveries []database.WebhookDelivery
+	if err := r.DB(ctx).
+		Preload("Webhook").
+		Preload("Event").
+		Joins("JOIN webhooks ON webhooks.id = webhook_deliveries.webhook_id").
+		Where("webhook_deliveries.status = ? AND webhook_deliveries.next_attempt_at <= ? AND webhooks.active = ?", database.DeliveryPending, now, true).
+		Where("webhook_deliveries.lease_until IS NULL OR webhook_deliveries.lease_until <= ?", now).
+		Order("webhook_deliveries.next_attempt_at ASC, webhook_deliveries.id ASC").
+		Limit(limit).
+		Find(&deliveries).Error; err != nil {
+		r.Logger.Error("failed to get due webhook deliveries", zap.Error(err))
+		return nil, WrapError(ErrGetWebhookDelivery.Error(), err)
+	}
+
+	return deliveries, nil
+}
+
+// Claim резервирует доставку до leaseUntil, чтобы её не отправил параллельно другой экземпляр сервиса.
+// Возвращает false, если доставку уже зарезервировал или обработал кто-то другой.
+func (r *GormWebhookRepository) Claim(ctx context.Context, delivery *database.WebhookDelivery, now, leaseUntil time.Time) (bool, error) {
+	// Условие на истёкшую аренду в самом UPDATE пропустит только одного из конкурентов, а число попыток служит версией:
+	// доставку, попытку которой кто-то уже успел завершить после чтения, повторно не отправляем
+	res := r.DB(ctx).Model(&database.WebhookDelivery{}).
+		Where("id = ? AND status = ? AND attempts = ?", delivery.ID, database.DeliveryPending, delivery.Attempts).
+		Where("lease_until IS NULL OR lease_until <= ?", now).
+		UpdateColumn("lease_until", leaseUntil)
+
+	if res.Error != nil {
+		r.Logger.Error("failed to claim webhook delivery", zap.Uint("deliveryID", delivery.ID), zap.Error(res.Error))
+		return false, WrapError(ErrUpdateWebhookDelivery.Error(), res.Error)
+	}
+
+	return res.RowsAffected == 1, nil
+}
+
+func (r *GormWebhookRepository) MarkDelivered(ctx context.Context, id uint, deliveredAt time.Time) error {
+	if err := r.DB(ctx).Model(&database.WebhookDelivery{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       database.DeliveryDelivered,
+			"attempts":     gorm.Expr("attempts + 1"),
+			"delivered_at": deliveredAt,
+			"lease_until":  nil,
+			"last_error":   "",
+		}).Error; err != nil {
+		r.Logger.Error("failed to mark webhook delivery delivered", zap.Uint("deliveryID", id), zap.Error(err))
+		return WrapError(ErrUpdateWebhookDelivery.Error(), err)
+	}
+
+	return nil
+}
+
+// MarkFailed сохраняет неудачную попытку: число попыток, новый статус и время следующей попытки.
+func (r *GormWebhookRepository) MarkFailed(
+	ctx context.Context,
+	id uint,
+	attempts int,
+	status string,
+	nextAttemptAt time.Time,
+	lastError string,
+) error {
+	if err := r.DB(ctx).Model(&database.WebhookDelivery{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":          status,
+			"attempts":        attempts,
+			"next_attempt_at": nextAttemptAt,
+			"lease_until":     nil,
+			"last_error":      lastError,
+		}).Error; err != nil {
+		r.Logger.Error("failed to mark webhook delivery failed", zap.Uint("deliveryID", id), zap.Error(err))
+		return WrapError(ErrUpdateWebhookDelivery.Error(), err)
+	}
+
+	return nil
+}
+
+// Retry возвращает доставку из dead-letter в очередь с обнулённым счётчиком попыток.
+func (r *GormWebhookRepository) Retry(ctx context.Context, id uint, now time.Time) error {
+	res := r.DB(ctx).Model(&database.WebhookDelivery{}).
+		Where("id = ? AND status = ?", id, database.DeliveryDead).
+		Updates(map[string]interface{}{
+			"status":          database.DeliveryPending,
+			"attempts":        0,
+			"next_attempt_at": now,
+			"lease_until":     nil,
+		})
+
+	if res.Error != nil {
+		r.Logger.Error("failed to retry webhook delivery", zap.Uint("deliveryID", id), zap.Error(res.Error))
+		return WrapError(ErrUpdateWebhookDelivery.Error(), res.Error)
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrWebhookDeliveryNotFound
+	}
+
+	return nil
+}
+
+// enqueueEventTx записывает событие в outbox в рамках транзакции tx и ставит его в очередь доставки
+// каждому активному вебхуку. Событие появляется, только если транзакция будет зафиксирована.
+func enqueueEventTx(tx *gorm.DB, eventType string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	event := database.OutboxEvent{Type: eventType, Payload: string(data)}
+	if err := tx.Create(&event).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	return tx.Exec(
+		"INSERT INTO webhook_deliveries (webhook_id, event_id, status, attempts, next_attempt_at, last_error, created_at) "+
+			"SELECT id, ?, ?, 0, ?, '', ? FROM webhooks WHERE active = ?",
+		event.ID, database.DeliveryPending, now, now, true,
+	).Error
+}
+
+// enqueuePurchaseEventTx записывает в outbox событие purchase.created о покупке purchase по цене price и возвращает его.
+func enqueuePurchaseEventTx(tx *gorm.DB, buyerID uint, purchase *database.Purchase, price int) (models.PurchaseCreatedEvent, error) {
+	owner, err := usernameTx(tx, purchase.UserID)
+	if err != nil {
+		return models.PurchaseCreatedEvent{}, err
+	}
+
+	goodType, err := goodTypeTx(tx, purchase.GoodID)
+	if err != nil {
+		return models.PurchaseCreatedEvent{}, err
+	}
+
+	event := models.PurchaseCreatedEvent{
+		PurchaseID: purchase.ID,
+		User:       owner,
+		Item:       goodType,
+		Price:      price,
+	}
+
+	if event.Variant, err = variantNameTx(tx, purchase.VariantID); err != nil {
+		return models.PurchaseCreatedEvent{}, err
+	}
+
+	if buyerID != purchase.UserID {
+		if event.GiftedBy, err = usernameTx(tx, buyerID); err != nil {
+			return models.PurchaseCreatedEvent{}, err
+		}
+	}
+
+	return event, enqueueEventTx(tx, database.EventPurchaseCreated, event)
+}
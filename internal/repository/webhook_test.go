@@ -0,0 +1,74 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookClaim_Lease(t *testing.T) {
+	holderRepo, db := setupTestHolderRepository(t)
+	ctx := context.Background()
+	now := time.Now()
+	lease := time.Minute
+
+	webhook := database.Webhook{URL: "http://example.com/hook", Secret: "secret", Active: true}
+	event := database.OutboxEvent{Type: database.EventTransferCreated, Payload: "{}"}
+
+	assert.NoError(t, db.Create(&webhook).Error, "failed to create webhook")
+	assert.NoError(t, db.Create(&event).Error, "failed to create event")
+	assert.NoError(t, db.Create(&database.WebhookDelivery{
+		WebhookID:     webhook.ID,
+		EventID:       event.ID,
+		Status:        database.DeliveryPending,
+		NextAttemptAt: now,
+	}).Error, "failed to create delivery")
+
+	// Два экземпляра сервиса прочитали одну и ту же доставку
+	first, err := holderRepo.Webhook().GetDueDeliveries(ctx, now, 10)
+	assert.NoError(t, err)
+
+	second, err := holderRepo.Webhook().GetDueDeliveries(ctx, now, 10)
+	assert.NoError(t, err)
+
+	if !assert.Len(t, first, 1) || !assert.Len(t, second, 1) {
+		return
+	}
+
+	claimed, err := holderRepo.Webhook().Claim(ctx, &first[0], now, now.Add(lease))
+	assert.NoError(t, err)
+	assert.True(t, claimed, "expected first claim to succeed")
+
+	claimed, err = holderRepo.Webhook().Claim(ctx, &second[0], now, now.Add(lease))
+	assert.NoError(t, err)
+	assert.False(t, claimed, "leased delivery must not be claimed twice")
+
+	due, err := holderRepo.Webhook().GetDueDeliveries(ctx, now.Add(time.Second), 10)
+	assert.NoError(t, err)
+	assert.Empty(t, due, "leased delivery should not be due")
+
+	// Аренда упавшего экземпляра истекает, и доставку забирает другой
+	expired := now.Add(lease + time.Second)
+
+	claimed, err = holderRepo.Webhook().Claim(ctx, &second[0], expired, expired.Add(lease))
+	assert.NoError(t, err)
+	assert.True(t, claimed, "expected claim after the lease expired")
+
+	// Завершённая попытка меняет число попыток, и прочитанная до неё доставка уже не резервируется
+	assert.NoError(t, holderRepo.Webhook().MarkFailed(ctx, first[0].ID, 1, database.DeliveryPending, expired, "failed"))
+
+	claimed, err = holderRepo.Webhook().Claim(ctx, &first[0], expired, expired.Add(lease))
+	assert.NoError(t, err)
+	assert.False(t, claimed, "stale delivery must not be claimed after another attempt finished")
+
+	due, err = holderRepo.Webhook().GetDueDeliveries(ctx, expired, 10)
+	assert.NoError(t, err)
+
+	if assert.Len(t, due, 1, "failed attempt should release the lease") {
+		assert.Equal(t, 1, due[0].Attempts)
+		assert.Nil(t, due[0].LeaseUntil)
+	}
+}
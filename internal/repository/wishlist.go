@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/maksemen2/avito-shop/internal/database"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// WishlistRepository описывает операции со списками желаний пользователей.
+type WishlistRepository interface {
+	Add(ctx context.Context, userID, goodID uint) error
+	Remove(ctx context.Context, userID, goodID uint) error
+	GetByUserID(ctx context.Context, userID uint) ([]database.WishlistItem, error)
+}
+
+// GormWishlistRepository реализует WishlistRepository.
+type GormWishlistRepository struct {
+	BaseRepository
+}
+
+func NewWishlistRepository(db *gorm.DB, logger *zap.Logger) WishlistRepository {
+	return &GormWishlistRepository{
+		BaseRepository: BaseRepository{
+			db:     db,
+			Logger: logger,
+		},
+	}
+}
+
+func (r *GormWishlistRepository) Add(ctx context.Context, userID, goodID uint) error {
+	err := r.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&database.WishlistItem{}).
+			Where("user_id = ? AND good_id = ?", userID, goodID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+
+		if count > 0 {
+			return ErrAlreadyInWishlist
+		}
+
+		return tx.Create(&database.WishlistItem{UserID: userID, GoodID: goodID}).Error
+	})
+
+	if err != nil {
+		if errors.Is(err, ErrAlreadyInWishlist) {
+			return err
+		}
+
+		r.Logger.Error("failed to add wishlist item", zap.Uint("userID", userID), zap.Uint("goodID", goodID), zap.Error(err))
+
+		return WrapError(ErrUpdateWishlist.Error(), err)
+	}
+
+	return nil
+}
+
+func (r *GormWishlistRepository) Remove(ctx context.Context, userID, goodID uint) error {
+	res := r.DB(ctx).Where("user_id = ? AND good_id = ?", userID, goodID).Delete(&database.WishlistItem{})
+	if res.Error != nil {
+		r.Logger.Error("failed to remove wishlist item", zap.Uint("userID", userID), zap.Uint("goodID", goodID), zap.Error(res.Error))
+		return WrapError(ErrUpdateWishlist.Error(), res.Error)
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrNotInWishlist
+	}
+
+	return nil
+}
+
+// GetByUserID возвращает список желаний пользователя вместе с товарами в порядке добавления.
+func (r *GormWishlistRepository) GetByUserID(ctx context.Context, userID uint) ([]database.WishlistItem, error) {
+	var items []database.WishlistItem
+	if err := r.DB(ctx).Preload("Good").Where("user_id = ?", userID).Order("id ASC").Find(&items).Error; err != nil {
+		r.Logger.Error("failed to get wishlist", zap.Uint("userID", userID), zap.Error(err))
+		return nil, WrapError(ErrGetWishlist.Error(), err)
+	}
+
+	return items, nil
+}
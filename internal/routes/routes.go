@@ -19,18 +19,69 @@ func SetupRoutes(handler *handlers.RequestsHandler, logger *zap.Logger, corsConf
 	apiGroup.Use(middleware.JSONMiddleware())
 	apiGroup.Use(middleware.CorsMiddleware(corsConfig))
 	apiGroup.Use(middleware.LoggerMiddleware(logger))
+	apiGroup.Use(middleware.PrimaryReadsMiddleware())
+	apiGroup.Use(middleware.ValidationMiddleware(logger, handler.OpenAPIDocument))
 
 	apiGroup.Group("")
 	{
+		apiGroup.GET("/openapi.json", handler.GetOpenAPIDocument)
 		apiGroup.POST("/auth", handler.Authenticate)
 	}
 
 	protectedGroup := apiGroup.Group("")
-	protectedGroup.Use(middleware.AuthMiddleware(logger, handler.JWTManager))
+	protectedGroup.Use(middleware.AuthMiddleware(logger, handler.JWTManager, handler.AuthService))
 	{
 		protectedGroup.GET("/info", handler.GetInfo)
+		protectedGroup.GET("/goods", handler.GetCatalog)
 		protectedGroup.GET("/buy/:item", handler.BuyItem)
+		protectedGroup.POST("/gift", handler.GiftItem)
 		protectedGroup.POST("/sendCoin", handler.SendCoin)
+		protectedGroup.POST("/sendItem", handler.SendItem)
+		protectedGroup.GET("/coinRequests", handler.GetCoinRequests)
+		protectedGroup.POST("/coinRequests", handler.CreateCoinRequest)
+		protectedGroup.POST("/coinRequests/:id/accept", handler.AcceptCoinRequest)
+		protectedGroup.POST("/coinRequests/:id/decline", handler.DeclineCoinRequest)
+		protectedGroup.GET("/market", handler.GetListings)
+		protectedGroup.POST("/market", handler.CreateListing)
+		protectedGroup.POST("/market/:id/buy", handler.BuyListing)
+		protectedGroup.POST("/market/:id/cancel", handler.CancelListing)
+		protectedGroup.GET("/wishlist", handler.GetWishlist)
+		protectedGroup.POST("/wishlist", handler.AddToWishlist)
+		protectedGroup.DELETE("/wishlist/:item", handler.RemoveFromWishlist)
+		protectedGroup.GET("/notifications", handler.GetNotifications)
+		protectedGroup.POST("/notifications/read", handler.MarkAllNotificationsRead)
+		protectedGroup.POST("/notifications/:id/read", handler.MarkNotificationRead)
+		protectedGroup.GET("/events", handler.StreamEvents)
+		protectedGroup.GET("/ws", handler.WebSocket)
+		protectedGroup.POST("/graphql", handler.GraphQL)
+	}
+
+	adminGroup := protectedGroup.Group("/admin")
+	adminGroup.Use(middleware.AdminMiddleware(logger, handler.AdminService))
+	{
+		adminGroup.GET("/grants", handler.GetGrantPrograms)
+		adminGroup.POST("/grants", handler.CreateGrantProgram)
+		adminGroup.POST("/grants/apply", handler.ApplyGrants)
+		adminGroup.POST("/grants/:id/activate", handler.ActivateGrantProgram)
+		adminGroup.POST("/grants/:id/deactivate", handler.DeactivateGrantProgram)
+		adminGroup.GET("/promos", handler.GetPromoCodes)
+		adminGroup.POST("/promos", handler.CreatePromoCode)
+		adminGroup.POST("/promos/:id/activate", handler.ActivatePromoCode)
+		adminGroup.POST("/promos/:id/deactivate", handler.DeactivatePromoCode)
+		adminGroup.GET("/prices", handler.GetPriceSchedules)
+		adminGroup.POST("/prices", handler.CreatePriceSchedule)
+		adminGroup.DELETE("/prices/:id", handler.DeletePriceSchedule)
+		adminGroup.GET("/goods/:item/variants", handler.GetVariants)
+		adminGroup.POST("/goods/:item/variants", handler.CreateVariant)
+		adminGroup.POST("/variants/:id/restock", handler.RestockVariant)
+		adminGroup.POST("/notifications", handler.SendNotification)
+		adminGroup.GET("/webhooks", handler.GetWebhooks)
+		adminGroup.POST("/webhooks", handler.CreateWebhook)
+		adminGroup.POST("/webhooks/:id/activate", handler.ActivateWebhook)
+		adminGroup.POST("/webhooks/:id/deactivate", handler.DeactivateWebhook)
+		adminGroup.GET("/deliveries", handler.GetWebhookDeliveries)
+		adminGroup.POST("/deliveries/:id/retry", handler.RetryWebhookDelivery)
+		adminGroup.GET("/cache", handler.GetCacheStats)
 	}
 
 	return router
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/maksemen2/avito-shop/internal/models"
+	"github.com/maksemen2/avito-shop/internal/repository"
+	"go.uber.org/zap"
+)
+
+type AdminService interface {
+	IsAdmin(ctx context.Context, userID uint) (bool, error)
+	CacheStats() models.CacheStatsResponse
+}
+
+type adminServiceImpl struct {
+	repository repository.HolderRepository
+	infoCache  InfoCache
+	logger     *zap.Logger
+}
+
+func NewAdminService(repository repository.HolderRepository, infoCache InfoCache, logger *zap.Logger) AdminService {
+	return &adminServiceImpl{repository: repository, infoCache: infoCache, logger: logger}
+}
+
+// IsAdmin проверяет права администратора по базе, а не по токену,
+// чтобы выдача и отзыв прав действовали сразу, без перевыпуска токена.
+func (s *adminServiceImpl) IsAdmin(ctx context.Context, userID uint) (bool, error) {
+	isAdmin, err := s.repository.User().IsAdmin(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return false, nil
+		}
+
+		return false, ErrInternal
+	}
+
+	return isAdmin, nil
+}
+
+// CacheStats возвращает счётчики кешей. Кеш товаров включён, если репозиторий создан с WithGoodsCache.
+// Счётчики кеша сводок есть только у бэкендов, которые их ведут.
+func (s *adminServiceImpl) CacheStats() models.CacheStatsResponse {
+	var resp models.CacheStatsResponse
+
+	if cached, ok := s.repository.Good().(cacheWithStats); ok {
+		resp.Goods = newCacheStats(cached.Stats())
+	}
+
+	if cached, ok := s.infoCache.(cacheWithStats); ok {
+		resp.Info = newCacheStats(cached.Stats())
+	}
+
+	return resp
+}
+
+type cacheWithStats interface {
+	Stats() repository.CacheStats
+}
+
+func newCacheStats(stats repository.CacheStats) models.CacheStats {
+	resp := models.CacheStats{
+		Enabled: true,
+		Hits:    stats.Hits,
+		Misses:  stats.Misses,
+		Entries: stats.Entries,
+	}
+
+	if total := stats.Hits + stats.Misses; total > 0 {
+		resp.HitRate = float64(stats.Hits) / float64(total)
+	}
+
+	return resp
+}
@@ -15,6 +15,7 @@ import (
 
 type AuthService interface {
 	Authenticate(ctx context.Context, req models.AuthRequest) (models.AuthResponse, error)
+	IsLocked(ctx context.Context, userID uint) (bool, error)
 }
 
 type authServiceImpl struct {
@@ -67,6 +68,12 @@ func (s *authServiceImpl) Authenticate(ctx context.Context, req models.AuthReque
 		}
 	}
 
+	// Блокировка проверяется только после пароля, чтобы по ответу нельзя было узнать о ней, не зная пароля.
+	// Уже выданные токены отклоняет проверка IsLocked при каждом запросе, а списания монет - проверка в их транзакции
+	if user.Locked {
+		return models.AuthResponse{}, ErrUserLocked
+	}
+
 	token, err := s.jwtManager.GenerateToken(user.ID, user.Username)
 	if err != nil {
 		s.logger.Error("Token generation failed", zap.Uint("userID", user.ID), zap.Error(err))
@@ -75,3 +82,18 @@ func (s *authServiceImpl) Authenticate(ctx context.Context, req models.AuthReque
 
 	return models.AuthResponse{Token: token}, nil
 }
+
+// IsLocked проверяет блокировку по базе, а не по токену, чтобы она действовала и на уже выданные токены.
+// Токен пользователя, которого нет в базе, тоже не должен приниматься, поэтому такой пользователь считается заблокированным.
+func (s *authServiceImpl) IsLocked(ctx context.Context, userID uint) (bool, error) {
+	locked, err := s.repository.User().IsLocked(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return true, nil
+		}
+
+		return false, ErrInternal
+	}
+
+	return locked, nil
+}
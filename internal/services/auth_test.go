@@ -27,7 +27,7 @@ func getMockAuthService(
 		t.Fatalf("failed to open in-memory database: %v", err)
 	}
 
-	db.AutoMigrate(&database.User{}, &database.Purchase{}, &database.Transaction{}, &database.Good{})
+	db.AutoMigrate(&database.User{}, &database.Purchase{}, &database.Transaction{}, &database.Good{}, &database.CoinRequest{}, &database.GrantProgram{}, &database.Grant{}, &database.CoinLot{}, &database.CoinExpiration{}, &database.ItemTransfer{}, &database.Listing{}, &database.PromoCode{}, &database.PromoRedemption{}, &database.PriceSchedule{}, &database.GoodVariant{}, &database.WishlistItem{}, &database.Notification{}, &database.OutboxEvent{}, &database.Webhook{}, &database.WebhookDelivery{})
 
 	logger := zap.NewNop()
 	holderRepo := repository.NewHolderRepository(db, logger)
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/maksemen2/avito-shop/config"
+	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/internal/models"
+	"github.com/maksemen2/avito-shop/internal/repository"
+	"go.uber.org/zap"
+)
+
+type CoinRequestService interface {
+	CreateRequest(ctx context.Context, requesterID uint, requesterUsername string, req models.CreateCoinRequestRequest) (models.CoinRequest, error)
+	AcceptRequest(ctx context.Context, payerID, requestID uint) error
+	DeclineRequest(ctx context.Context, payerID, requestID uint) error
+	ListRequests(ctx context.Context, userID uint, status string) (models.CoinRequestsResponse, error)
+}
+
+type coinRequestServiceImpl struct {
+	repository repository.HolderRepository
+	policy     *TransferPolicy
+	logger     *zap.Logger
+}
+
+func NewCoinRequestService(repository repository.HolderRepository, transferConfig config.TransferConfig, logger *zap.Logger) CoinRequestService {
+	return &coinRequestServiceImpl{
+		repository: repository,
+		policy:     NewTransferPolicy(transferConfig, repository, logger),
+		logger:     logger,
+	}
+}
+
+func (s *coinRequestServiceImpl) CreateRequest(
+	ctx context.Context,
+	requesterID uint,
+	requesterUsername string,
+	req models.CreateCoinRequestRequest,
+) (models.CoinRequest, error) {
+	if req.FromUser == "" {
+		return models.CoinRequest{}, ErrFromUserRequired
+	}
+
+	if req.Amount <= 0 {
+		return models.CoinRequest{}, ErrAmountBelowZero
+	}
+
+	if requesterUsername == req.FromUser {
+		return models.CoinRequest{}, ErrCantSelfRequest
+	}
+
+	payerID, err := s.repository.User().GetIDByUsername(ctx, req.FromUser)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return models.CoinRequest{}, ErrPayerNotFound
+		}
+
+		return models.CoinRequest{}, ErrInternal
+	}
+
+	request, err := s.repository.CoinRequest().Create(ctx, requesterID, payerID, req.Amount)
+	if err != nil {
+		return models.CoinRequest{}, ErrInternal
+	}
+
+	return models.CoinRequest{
+		ID:        request.ID,
+		Requester: requesterUsername,
+		Payer:     req.FromUser,
+		Amount:    request.Amount,
+		Status:    request.Status,
+		CreatedAt: request.CreatedAt,
+	}, nil
+}
+
+func (s *coinRequestServiceImpl) AcceptRequest(ctx context.Context, payerID, requestID uint) error {
+	request, err := s.repository.CoinRequest().GetByID(ctx, requestID)
+	if err != nil {
+		return mapCoinRequestError(err)
+	}
+
+	// Чужие запросы для пользователя не существуют
+	if request.PayerID != payerID {
+		return ErrCoinRequestNotFound
+	}
+
+	// Принятие запроса - такой же исходящий перевод, поэтому на него действуют те же ограничения
+	if request.Status == database.CoinRequestPending {
+		if err := s.policy.Check(ctx, payerID, request.Amount); err != nil {
+			return err
+		}
+	}
+
+	if err := s.repository.AcceptCoinRequest(ctx, requestID, payerID, s.policy.DailyLimit()); err != nil {
+		return mapCoinRequestError(err)
+	}
+
+	return nil
+}
+
+func (s *coinRequestServiceImpl) DeclineRequest(ctx context.Context, payerID, requestID uint) error {
+	if err := s.repository.CoinRequest().Decline(ctx, requestID, payerID); err != nil {
+		return mapCoinRequestError(err)
+	}
+
+	return nil
+}
+
+func (s *coinRequestServiceImpl) ListRequests(ctx context.Context, userID uint, status string) (models.CoinRequestsResponse, error) {
+	switch status {
+	case "", database.CoinRequestPending, database.CoinRequestAccepted, database.CoinRequestDeclined:
+	default:
+		return models.CoinRequestsResponse{}, ErrInvalidStatus
+	}
+
+	requests, err := s.repository.CoinRequest().GetByUserID(ctx, userID, status)
+	if err != nil {
+		return models.CoinRequestsResponse{}, ErrInternal
+	}
+
+	return requests, nil
+}
+
+// mapCoinRequestError переводит ошибки репозитория при обработке запроса монет в ошибки сервиса.
+func mapCoinRequestError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrCoinRequestNotFound):
+		return ErrCoinRequestNotFound
+	case errors.Is(err, repository.ErrCoinRequestResolved):
+		return ErrCoinRequestResolved
+	case errors.Is(err, repository.ErrInsufficientFunds):
+		return ErrInsufficientFunds
+	case errors.Is(err, repository.ErrDailyLimit):
+		return ErrDailyLimitExceeded
+	case errors.Is(err, repository.ErrUserLocked):
+		return ErrUserLocked
+	case errors.Is(err, repository.ErrUserNotFound):
+		return ErrRecieverNotFound
+	default:
+		return ErrInternal
+	}
+}
@@ -0,0 +1,86 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/maksemen2/avito-shop/config"
+	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/internal/models"
+	"github.com/maksemen2/avito-shop/internal/repository"
+	"github.com/maksemen2/avito-shop/internal/services"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+func getMockCoinRequestService(t *testing.T) (services.CoinRequestService, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormLogger.Default.LogMode(gormLogger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to open in-memory database: %v", err)
+	}
+
+	db.AutoMigrate(&database.User{}, &database.Purchase{}, &database.Transaction{}, &database.Good{}, &database.CoinRequest{}, &database.GrantProgram{}, &database.Grant{}, &database.CoinLot{}, &database.CoinExpiration{}, &database.ItemTransfer{}, &database.Listing{}, &database.PromoCode{}, &database.PromoRedemption{}, &database.PriceSchedule{}, &database.GoodVariant{}, &database.WishlistItem{}, &database.Notification{}, &database.OutboxEvent{}, &database.Webhook{}, &database.WebhookDelivery{})
+
+	logger := zap.NewNop()
+	holderRepo := repository.NewHolderRepository(db, logger)
+
+	return services.NewCoinRequestService(holderRepo, config.TransferConfig{}, logger), db
+}
+
+func TestCoinRequest_CreateAndAccept(t *testing.T) {
+	srv, db := getMockCoinRequestService(t)
+	ctx := context.Background()
+
+	requester := database.User{Username: "requester", PasswordHash: "test"}
+	payer := database.User{Username: "payer", PasswordHash: "test"}
+
+	assert.NoError(t, db.Create(&requester).Error, "failed to create requester")
+	assert.NoError(t, db.Create(&payer).Error, "failed to create payer")
+
+	request, err := srv.CreateRequest(ctx, requester.ID, requester.Username, models.CreateCoinRequestRequest{
+		FromUser: payer.Username,
+		Amount:   200,
+	})
+	assert.NoError(t, err, "failed to create coin request")
+	assert.Equal(t, "payer", request.Payer, "unexpected payer")
+	assert.Equal(t, database.CoinRequestPending, request.Status, "unexpected status")
+
+	assert.NoError(t, srv.AcceptRequest(ctx, payer.ID, request.ID), "failed to accept coin request")
+
+	assert.NoError(t, db.First(&requester, requester.ID).Error, "failed to get requester")
+	assert.NoError(t, db.First(&payer, payer.ID).Error, "failed to get payer")
+	assert.Equal(t, 1200, requester.Coins, "unexpected requester coins count")
+	assert.Equal(t, 800, payer.Coins, "unexpected payer coins count")
+
+	err = srv.DeclineRequest(ctx, payer.ID, request.ID)
+	assert.ErrorIs(t, err, services.ErrCoinRequestResolved, "unexpected error")
+}
+
+func TestCoinRequest_Validation(t *testing.T) {
+	srv, db := getMockCoinRequestService(t)
+	ctx := context.Background()
+
+	requester := database.User{Username: "requester", PasswordHash: "test"}
+	assert.NoError(t, db.Create(&requester).Error, "failed to create requester")
+
+	_, err := srv.CreateRequest(ctx, requester.ID, requester.Username, models.CreateCoinRequestRequest{Amount: 10})
+	assert.ErrorIs(t, err, services.ErrFromUserRequired, "unexpected error")
+
+	_, err = srv.CreateRequest(ctx, requester.ID, requester.Username, models.CreateCoinRequestRequest{FromUser: "payer", Amount: 0})
+	assert.ErrorIs(t, err, services.ErrAmountBelowZero, "unexpected error")
+
+	_, err = srv.CreateRequest(ctx, requester.ID, requester.Username, models.CreateCoinRequestRequest{FromUser: "requester", Amount: 10})
+	assert.ErrorIs(t, err, services.ErrCantSelfRequest, "unexpected error")
+
+	_, err = srv.CreateRequest(ctx, requester.ID, requester.Username, models.CreateCoinRequestRequest{FromUser: "payer", Amount: 10})
+	assert.ErrorIs(t, err, services.ErrPayerNotFound, "unexpected error")
+
+	_, err = srv.ListRequests(ctx, requester.ID, "unknown")
+	assert.ErrorIs(t, err, services.ErrInvalidStatus, "unexpected error")
+
+	err = srv.AcceptRequest(ctx, requester.ID, 9999)
+	assert.ErrorIs(t, err, services.ErrCoinRequestNotFound, "unexpected error")
+}
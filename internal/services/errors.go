@@ -11,6 +11,71 @@ var (
 	ErrInsufficientFunds = errors.New("insufficient funds")
 	ErrUserPassRequired  = errors.New("username and password required")
 	ErrAuthFailed        = errors.New("authentication failed")
+	ErrUserLocked        = errors.New("user is locked")
 	ErrItemTypeRequired  = errors.New("item type is required")
 	ErrItemNotFound      = errors.New("item not found")
+
+	ErrFromUserRequired    = errors.New("fromUser is required")
+	ErrCantSelfRequest     = errors.New("can't request coins from yourself")
+	ErrPayerNotFound       = errors.New("payer not found")
+	ErrInvalidStatus       = errors.New("invalid status")
+	ErrCoinRequestNotFound = errors.New("coin request not found")
+	ErrCoinRequestResolved = errors.New("coin request already resolved")
+
+	ErrTransferAmountTooLarge = errors.New("transfer amount exceeds the per-transfer limit")
+	ErrDailyLimitExceeded     = errors.New("daily transfer limit exceeded")
+	ErrAccountTooNew          = errors.New("account is too new to send coins")
+
+	ErrGrantNameRequired    = errors.New("grant program name is required")
+	ErrInvalidGrantPeriod   = errors.New("grant period must be one of daily, weekly, monthly")
+	ErrGrantProgramExists   = errors.New("grant program already exists")
+	ErrGrantProgramNotFound = errors.New("grant program not found")
+
+	ErrCantSelfGift = errors.New("can't gift to yourself, buy the item instead")
+
+	ErrQuantityBelowZero = errors.New("quantity must be greater than zero")
+	ErrCantSelfSendItem  = errors.New("can't send items to yourself")
+	ErrInsufficientItems = errors.New("not enough items in inventory")
+
+	ErrPriceBelowZero    = errors.New("price must be greater than zero")
+	ErrListingNotFound   = errors.New("listing not found")
+	ErrListingClosed     = errors.New("listing is no longer active")
+	ErrCantBuyOwnListing = errors.New("can't buy your own listing")
+
+	ErrPromoCodeRequired      = errors.New("promo code is required")
+	ErrInvalidDiscountType    = errors.New("discount type must be one of percent, fixed")
+	ErrInvalidDiscountValue   = errors.New("discount value must be positive and at most 100 for percent discounts")
+	ErrInvalidPromoLimits     = errors.New("promo code usage limits can't be negative")
+	ErrInvalidValidityWindow  = errors.New("validUntil must be after validFrom")
+	ErrPromoCodeExists        = errors.New("promo code already exists")
+	ErrPromoCodeNotFound      = errors.New("promo code not found")
+	ErrPromoCodeInactive      = errors.New("promo code is not active")
+	ErrPromoCodeNotApplicable = errors.New("promo code is not applicable to this item")
+	ErrPromoCodeExhausted     = errors.New("promo code usage limit reached")
+	ErrPromoCodeUserLimit     = errors.New("promo code already used the maximum number of times")
+
+	ErrInvalidSchedulePrice  = errors.New("exactly one of price or discountPercent (below 100) must be set")
+	ErrInvalidScheduleWindow = errors.New("startsAt is required and endsAt must be after startsAt")
+	ErrPriceScheduleNotFound = errors.New("price schedule not found")
+
+	ErrVariantNameRequired = errors.New("variant name is required")
+	ErrVariantRequired     = errors.New("item has variants, variant is required")
+	ErrVariantNotFound     = errors.New("variant not found")
+	ErrVariantExists       = errors.New("variant already exists")
+	ErrOutOfStock          = errors.New("variant is out of stock")
+	ErrInvalidStock        = errors.New("stock can't be negative")
+
+	ErrAlreadyInWishlist = errors.New("item already in wishlist")
+	ErrNotInWishlist     = errors.New("item not in wishlist")
+
+	ErrNotificationNotFound = errors.New("notification not found")
+	ErrMessageRequired      = errors.New("message is required")
+	ErrMessageTooLong       = errors.New("message is too long")
+
+	ErrInvalidWebhookURL       = errors.New("webhook url must be an absolute http or https url")
+	ErrWebhookNotFound         = errors.New("webhook not found")
+	ErrInvalidDeliveryStatus   = errors.New("status must be one of pending, delivered, dead")
+	ErrWebhookDeliveryNotFound = errors.New("dead webhook delivery not found")
+
+	ErrInvalidPage = errors.New("limit must be between 1 and 100 and offset can't be negative")
 )
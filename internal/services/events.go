@@ -0,0 +1,53 @@
+package services
+
+import (
+	"context"
+
+	"github.com/maksemen2/avito-shop/internal/events"
+	"github.com/maksemen2/avito-shop/internal/models"
+	"github.com/maksemen2/avito-shop/internal/repository"
+	"go.uber.org/zap"
+)
+
+// NewEventsHook возвращает хук репозитория, который публикует в publisher события об операциях пользователей:
+// переводы получателям, покупки покупателям и новый баланс всем затронутым пользователям.
+func NewEventsHook(publisher events.Publisher, logger *zap.Logger) repository.UserChangeHook {
+	return func(ctx context.Context, holder repository.HolderRepository, change repository.UserChange) {
+		for _, transfer := range change.Transfers {
+			publisher.Publish(transfer.ToUserID, events.Event{Type: events.TypeTransfer, Data: transfer.Event})
+		}
+
+		for _, purchase := range change.Purchases {
+			publisher.Publish(purchase.BuyerID, events.Event{Type: events.TypePurchase, Data: purchase.Event})
+		}
+
+		publishBalance(ctx, holder, publisher, logger, change.UserIDs...)
+	}
+}
+
+// publishBalance публикует актуальный баланс тем из пользователей userIDs, у кого есть подписчики.
+// Операция к этому моменту уже выполнена, поэтому ошибка получения баланса только логируется.
+func publishBalance(
+	ctx context.Context,
+	holder repository.HolderRepository,
+	publisher events.Publisher,
+	logger *zap.Logger,
+	userIDs ...uint,
+) {
+	// Баланс читается сразу после записи, реплика могла ещё не получить изменения
+	ctx = repository.WithPrimaryReads(ctx)
+
+	for _, userID := range userIDs {
+		if !publisher.Subscribed(userID) {
+			continue
+		}
+
+		coins, err := holder.User().GetBalance(ctx, userID)
+		if err != nil {
+			logger.Warn("failed to get balance for event", zap.Uint("userID", userID), zap.Error(err))
+			continue
+		}
+
+		publisher.Publish(userID, events.Event{Type: events.TypeBalance, Data: models.BalanceEvent{Coins: coins}})
+	}
+}
@@ -0,0 +1,71 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/internal/events"
+	"github.com/maksemen2/avito-shop/internal/models"
+	"github.com/maksemen2/avito-shop/internal/repository"
+	"github.com/maksemen2/avito-shop/internal/services"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+func TestEventsHook_PublishesRepositoryOperations(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormLogger.Default.LogMode(gormLogger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to open in-memory database: %v", err)
+	}
+
+	db.AutoMigrate(&database.User{}, &database.Purchase{}, &database.Transaction{}, &database.Good{}, &database.CoinRequest{}, &database.GrantProgram{}, &database.Grant{}, &database.CoinLot{}, &database.CoinExpiration{}, &database.ItemTransfer{}, &database.Listing{}, &database.PromoCode{}, &database.PromoRedemption{}, &database.PriceSchedule{}, &database.GoodVariant{}, &database.WishlistItem{}, &database.Notification{}, &database.OutboxEvent{}, &database.Webhook{}, &database.WebhookDelivery{})
+
+	logger := zap.NewNop()
+	broker := events.NewBroker(logger)
+	holderRepo := repository.NewHolderRepository(db, logger, repository.WithUserChangeHook(services.NewEventsHook(broker, logger)))
+	ctx := context.Background()
+
+	buyer, seller := createTransferUsers(t, db)
+
+	good := database.Good{Type: "cup", Price: 20}
+	if err := db.Create(&good).Error; err != nil {
+		t.Fatalf("failed to create good: %v", err)
+	}
+
+	if err := db.Create(&database.Purchase{UserID: seller.ID, GoodID: good.ID}).Error; err != nil {
+		t.Fatalf("failed to create purchase: %v", err)
+	}
+
+	listing, err := holderRepo.Listing().Create(ctx, seller.ID, good.ID, nil, 100)
+	if err != nil {
+		t.Fatalf("failed to create listing: %v", err)
+	}
+
+	sellerEvents, unsubscribeSeller := broker.Subscribe(seller.ID)
+	defer unsubscribeSeller()
+
+	buyerEvents, unsubscribeBuyer := broker.Subscribe(buyer.ID)
+	defer unsubscribeBuyer()
+
+	assert.NoError(t, holderRepo.BuyListing(ctx, listing.ID, buyer.ID))
+
+	assert.Equal(t, events.Event{Type: events.TypePurchase, Data: models.PurchaseEvent{Item: "cup", Price: 100}}, <-buyerEvents)
+	assert.Equal(t, events.Event{Type: events.TypeBalance, Data: models.BalanceEvent{Coins: 900}}, <-buyerEvents)
+	assert.Equal(t, events.Event{Type: events.TypeTransfer, Data: models.TransferEvent{FromUser: "sender", Amount: 100}}, <-sellerEvents)
+	assert.Equal(t, events.Event{Type: events.TypeBalance, Data: models.BalanceEvent{Coins: 1100}}, <-sellerEvents)
+
+	program, err := holderRepo.Grant().CreateProgram(ctx, "monthly", 50, "monthly")
+	if err != nil {
+		t.Fatalf("failed to create grant program: %v", err)
+	}
+
+	_, err = holderRepo.Grant().ApplyProgram(ctx, *program, "2026-10")
+	assert.NoError(t, err)
+
+	assert.Equal(t, events.Event{Type: events.TypeBalance, Data: models.BalanceEvent{Coins: 950}}, <-buyerEvents, "expected grant to publish balance")
+	assert.Equal(t, events.Event{Type: events.TypeBalance, Data: models.BalanceEvent{Coins: 1150}}, <-sellerEvents, "expected grant to publish balance")
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/internal/models"
+	"github.com/maksemen2/avito-shop/internal/repository"
+	"go.uber.org/zap"
+)
+
+type ExpiryService interface {
+	ExpireCoins(ctx context.Context, now time.Time) (int, error)
+	UpcomingExpirations(ctx context.Context, userID uint) ([]models.CoinsExpiration, error)
+}
+
+// expiryServiceImpl сжигает монеты по сроку, записанному в партию при её создании.
+// Сам срок жизни задаётся репозиторию через repository.WithCoinLifetime.
+type expiryServiceImpl struct {
+	repository repository.HolderRepository
+	logger     *zap.Logger
+}
+
+func NewExpiryService(repository repository.HolderRepository, logger *zap.Logger) ExpiryService {
+	return &expiryServiceImpl{
+		repository: repository,
+		logger:     logger,
+	}
+}
+
+// ExpireCoins сжигает монеты из партий, срок жизни которых истёк к моменту now.
+func (s *expiryServiceImpl) ExpireCoins(ctx context.Context, now time.Time) (int, error) {
+	expired, err := s.repository.CoinLot().ExpireLots(ctx, now)
+	if err != nil {
+		return expired, ErrInternal
+	}
+
+	if expired > 0 {
+		s.logger.Info("coins expired", zap.Int("users", expired))
+	}
+
+	return expired, nil
+}
+
+// UpcomingExpirations возвращает непотраченные монеты пользователя, сгруппированные по дню сгорания, от ближайшего дня.
+func (s *expiryServiceImpl) UpcomingExpirations(ctx context.Context, userID uint) ([]models.CoinsExpiration, error) {
+	lots, err := s.repository.CoinLot().GetActiveLots(ctx, userID)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	return groupExpirations(lots), nil
+}
+
+// groupExpirations суммирует остатки партий по дню сгорания от ближайшего дня, несгораемые партии пропускает.
+// Партии, созданные с разным сроком жизни, могут сгорать не в порядке создания, поэтому дни сортируются отдельно.
+func groupExpirations(lots []database.CoinLot) []models.CoinsExpiration {
+	byDay := make(map[time.Time]int)
+
+	for _, lot := range lots {
+		if lot.ExpiresAt == nil {
+			continue
+		}
+
+		expiresAt := lot.ExpiresAt.UTC()
+		byDay[time.Date(expiresAt.Year(), expiresAt.Month(), expiresAt.Day(), 0, 0, 0, 0, time.UTC)] += lot.Remaining
+	}
+
+	expirations := make([]models.CoinsExpiration, 0, len(byDay))
+	for day, amount := range byDay {
+		expirations = append(expirations, models.CoinsExpiration{Amount: amount, ExpiresAt: day})
+	}
+
+	sort.Slice(expirations, func(i, j int) bool {
+		return expirations[i].ExpiresAt.Before(expirations[j].ExpiresAt)
+	})
+
+	return expirations
+}
@@ -0,0 +1,60 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/internal/repository"
+	"github.com/maksemen2/avito-shop/internal/services"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+func getMockExpiryService(t *testing.T) (services.ExpiryService, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormLogger.Default.LogMode(gormLogger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to open in-memory database: %v", err)
+	}
+
+	db.AutoMigrate(&database.User{}, &database.CoinLot{}, &database.CoinExpiration{})
+
+	logger := zap.NewNop()
+	holderRepo := repository.NewHolderRepository(db, logger, repository.WithCoinLifetime(365*24*time.Hour))
+
+	return services.NewExpiryService(holderRepo, logger), db
+}
+
+func TestExpireCoins(t *testing.T) {
+	srv, db := getMockExpiryService(t)
+	ctx := context.Background()
+
+	user := database.User{Username: "test", PasswordHash: "test", Coins: 300}
+	assert.NoError(t, db.Create(&user).Error, "failed to create user")
+
+	now := time.Now()
+	yesterday := now.Add(-24 * time.Hour)
+	nextYear := now.AddDate(1, 0, 0)
+
+	// Срок сгорания берётся из самой партии, а не из её возраста: партия без срока не сгорает, даже если создана давно
+	assert.NoError(t, db.Create(&database.CoinLot{UserID: user.ID, Source: database.CoinSourceInitial, Amount: 100, Remaining: 100, ExpiresAt: &yesterday, CreatedAt: now.Add(-48 * time.Hour)}).Error)
+	assert.NoError(t, db.Create(&database.CoinLot{UserID: user.ID, Source: database.CoinSourceGrant, Amount: 150, Remaining: 150, ExpiresAt: &nextYear, CreatedAt: now}).Error)
+	assert.NoError(t, db.Create(&database.CoinLot{UserID: user.ID, Source: database.CoinSourceAdmin, Amount: 50, Remaining: 50, CreatedAt: now.AddDate(-2, 0, 0)}).Error)
+
+	expired, err := srv.ExpireCoins(ctx, now)
+	assert.NoError(t, err, "failed to expire coins")
+	assert.Equal(t, 1, expired, "expected one user with expired coins")
+
+	assert.NoError(t, db.First(&user, user.ID).Error, "failed to fetch user")
+	assert.Equal(t, 200, user.Coins, "expected only the lot past its expiry to expire")
+
+	upcoming, err := srv.UpcomingExpirations(ctx, user.ID)
+	assert.NoError(t, err, "failed to get upcoming expirations")
+	assert.Len(t, upcoming, 1, "expected one upcoming expiration")
+	assert.Equal(t, 150, upcoming[0].Amount, "unexpected upcoming expiration amount")
+	assert.True(t, upcoming[0].ExpiresAt.After(now.AddDate(0, 11, 0)), "expected expiration in about a year")
+}
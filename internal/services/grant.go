@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/internal/models"
+	"github.com/maksemen2/avito-shop/internal/repository"
+	"go.uber.org/zap"
+)
+
+type GrantService interface {
+	CreateProgram(ctx context.Context, req models.CreateGrantProgramRequest) (models.GrantProgram, error)
+	ListPrograms(ctx context.Context) ([]models.GrantProgram, error)
+	SetProgramActive(ctx context.Context, programID uint, active bool) error
+	ApplyGrants(ctx context.Context, now time.Time) (int64, error)
+}
+
+type grantServiceImpl struct {
+	repository repository.HolderRepository
+	logger     *zap.Logger
+}
+
+func NewGrantService(repository repository.HolderRepository, logger *zap.Logger) GrantService {
+	return &grantServiceImpl{repository: repository, logger: logger}
+}
+
+func (s *grantServiceImpl) CreateProgram(ctx context.Context, req models.CreateGrantProgramRequest) (models.GrantProgram, error) {
+	if req.Name == "" {
+		return models.GrantProgram{}, ErrGrantNameRequired
+	}
+
+	if req.Amount <= 0 {
+		return models.GrantProgram{}, ErrAmountBelowZero
+	}
+
+	if _, err := GrantPeriodKey(req.Period, time.Now()); err != nil {
+		return models.GrantProgram{}, err
+	}
+
+	program, err := s.repository.Grant().CreateProgram(ctx, req.Name, req.Amount, req.Period)
+	if err != nil {
+		if errors.Is(err, repository.ErrGrantProgramExists) {
+			return models.GrantProgram{}, ErrGrantProgramExists
+		}
+
+		return models.GrantProgram{}, ErrInternal
+	}
+
+	return toGrantProgramModel(*program), nil
+}
+
+func (s *grantServiceImpl) ListPrograms(ctx context.Context) ([]models.GrantProgram, error) {
+	programs, err := s.repository.Grant().GetPrograms(ctx)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	result := make([]models.GrantProgram, 0, len(programs))
+	for _, program := range programs {
+		result = append(result, toGrantProgramModel(program))
+	}
+
+	return result, nil
+}
+
+func (s *grantServiceImpl) SetProgramActive(ctx context.Context, programID uint, active bool) error {
+	if err := s.repository.Grant().SetProgramActive(ctx, programID, active); err != nil {
+		if errors.Is(err, repository.ErrGrantProgramNotFound) {
+			return ErrGrantProgramNotFound
+		}
+
+		return ErrInternal
+	}
+
+	return nil
+}
+
+// ApplyGrants начисляет пособия по всем активным программам за период, в который попадает now.
+// Вызов идемпотентен в пределах периода, поэтому фоновая задача может запускаться сколь угодно часто.
+// Ошибка одной программы не мешает обработать остальные.
+func (s *grantServiceImpl) ApplyGrants(ctx context.Context, now time.Time) (int64, error) {
+	programs, err := s.repository.Grant().GetActivePrograms(ctx)
+	if err != nil {
+		return 0, ErrInternal
+	}
+
+	var (
+		total  int64
+		failed bool
+	)
+
+	for _, program := range programs {
+		period, err := GrantPeriodKey(program.Period, now)
+		if err != nil {
+			s.logger.Error("grant program has invalid period", zap.Uint("programID", program.ID), zap.String("period", program.Period))
+			failed = true
+
+			continue
+		}
+
+		granted, err := s.repository.Grant().ApplyProgram(ctx, program, period)
+		if err != nil {
+			failed = true
+			continue
+		}
+
+		if granted > 0 {
+			s.logger.Info("grant program applied",
+				zap.String("program", program.Name),
+				zap.String("period", period),
+				zap.Int64("users", granted))
+		}
+
+		total += granted
+	}
+
+	if failed {
+		return total, ErrInternal
+	}
+
+	return total, nil
+}
+
+// GrantPeriodKey возвращает идентификатор периода начисления, в который попадает момент t.
+func GrantPeriodKey(period string, t time.Time) (string, error) {
+	t = t.UTC()
+
+	switch period {
+	case database.GrantPeriodDaily:
+		return t.Format("2006-01-02"), nil
+	case database.GrantPeriodWeekly:
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week), nil
+	case database.GrantPeriodMonthly:
+		return t.Format("2006-01"), nil
+	default:
+		return "", ErrInvalidGrantPeriod
+	}
+}
+
+func toGrantProgramModel(program database.GrantProgram) models.GrantProgram {
+	return models.GrantProgram{
+		ID:        program.ID,
+		Name:      program.Name,
+		Amount:    program.Amount,
+		Period:    program.Period,
+		Active:    program.Active,
+		CreatedAt: program.CreatedAt,
+	}
+}
@@ -0,0 +1,88 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/internal/models"
+	"github.com/maksemen2/avito-shop/internal/repository"
+	"github.com/maksemen2/avito-shop/internal/services"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+func getMockGrantService(t *testing.T) (services.GrantService, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormLogger.Default.LogMode(gormLogger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to open in-memory database: %v", err)
+	}
+
+	db.AutoMigrate(&database.User{}, &database.GrantProgram{}, &database.Grant{}, &database.CoinLot{}, &database.Notification{}, &database.OutboxEvent{}, &database.Webhook{}, &database.WebhookDelivery{})
+
+	logger := zap.NewNop()
+	holderRepo := repository.NewHolderRepository(db, logger)
+
+	return services.NewGrantService(holderRepo, logger), db
+}
+
+func TestApplyGrants_ActiveProgramsOnly(t *testing.T) {
+	srv, db := getMockGrantService(t)
+	ctx := context.Background()
+
+	user := database.User{Username: "test", PasswordHash: "test"}
+	assert.NoError(t, db.Create(&user).Error, "failed to create user")
+
+	monthly, err := srv.CreateProgram(ctx, models.CreateGrantProgramRequest{Name: "monthly", Amount: 200, Period: "monthly"})
+	assert.NoError(t, err, "failed to create monthly program")
+
+	daily, err := srv.CreateProgram(ctx, models.CreateGrantProgramRequest{Name: "daily", Amount: 5, Period: "daily"})
+	assert.NoError(t, err, "failed to create daily program")
+
+	assert.NoError(t, srv.SetProgramActive(ctx, daily.ID, false), "failed to deactivate daily program")
+
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+
+	granted, err := srv.ApplyGrants(ctx, now)
+	assert.NoError(t, err, "failed to apply grants")
+	assert.Equal(t, int64(1), granted, "expected one grant")
+
+	granted, err = srv.ApplyGrants(ctx, now.Add(24*time.Hour))
+	assert.NoError(t, err, "failed to apply grants")
+	assert.Equal(t, int64(0), granted, "expected no grants within the same month")
+
+	assert.NoError(t, db.First(&user, user.ID).Error, "failed to fetch user")
+	assert.Equal(t, 1000+monthly.Amount, user.Coins, "unexpected user coins count")
+}
+
+func TestCreateProgram_Validation(t *testing.T) {
+	srv, _ := getMockGrantService(t)
+	ctx := context.Background()
+
+	_, err := srv.CreateProgram(ctx, models.CreateGrantProgramRequest{Amount: 10, Period: "daily"})
+	assert.ErrorIs(t, err, services.ErrGrantNameRequired, "unexpected error")
+
+	_, err = srv.CreateProgram(ctx, models.CreateGrantProgramRequest{Name: "test", Amount: 10, Period: "yearly"})
+	assert.ErrorIs(t, err, services.ErrInvalidGrantPeriod, "unexpected error")
+
+	_, err = srv.CreateProgram(ctx, models.CreateGrantProgramRequest{Name: "test", Amount: 0, Period: "daily"})
+	assert.ErrorIs(t, err, services.ErrAmountBelowZero, "unexpected error")
+}
+
+func TestGrantPeriodKey(t *testing.T) {
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+
+	for period, expected := range map[string]string{
+		"daily":   "2026-10-19",
+		"weekly":  "2026-W43",
+		"monthly": "2026-10",
+	} {
+		key, err := services.GrantPeriodKey(period, now)
+		assert.NoError(t, err, "unexpected error for period %s", period)
+		assert.Equal(t, expected, key, "unexpected key for period %s", period)
+	}
+}
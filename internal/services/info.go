@@ -8,17 +8,30 @@ import (
 	"go.uber.org/zap"
 )
 
+// maxHistoryPageSize - наибольший размер страницы истории переводов.
+const maxHistoryPageSize = 100
+
+// InfoService отдаёт сводку по пользователю целиком (GetInfo) или по частям,
+// чтобы клиентам, которым нужен только баланс, не приходилось загружать всю историю.
 type InfoService interface {
 	GetInfo(ctx context.Context, userID uint) (models.InfoResponse, error)
+	GetBalance(ctx context.Context, userID uint) (int, error)
+	GetInventory(ctx context.Context, userID uint) ([]models.Item, error)
+	GetTransfers(ctx context.Context, userID uint, limit, offset int) (models.TransferHistoryPage, error)
 }
 
 type infoServiceImpl struct {
 	repository repository.HolderRepository
+	expiry     ExpiryService
 	logger     *zap.Logger
 }
 
 func NewInfoService(repository repository.HolderRepository, logger *zap.Logger) InfoService {
-	return &infoServiceImpl{repository: repository, logger: logger}
+	return &infoServiceImpl{
+		repository: repository,
+		expiry:     NewExpiryService(repository, logger),
+		logger:     logger,
+	}
 }
 
 func (s *infoServiceImpl) GetInfo(ctx context.Context, userID uint) (models.InfoResponse, error) {
@@ -41,9 +54,74 @@ func (s *infoServiceImpl) GetInfo(ctx context.Context, userID uint) (models.Info
 		return models.InfoResponse{}, ErrInternal
 	}
 
+	coinHistory.Granted, err = s.repository.Grant().GetHistoryByUserID(ctx, userID)
+
+	if err != nil {
+		return models.InfoResponse{}, ErrInternal
+	}
+
+	coinHistory.Expired, err = s.repository.CoinLot().GetExpirationHistory(ctx, userID)
+
+	if err != nil {
+		return models.InfoResponse{}, ErrInternal
+	}
+
+	giftHistory, err := s.repository.Purchase().GetGiftHistoryByUserID(ctx, userID)
+
+	if err != nil {
+		return models.InfoResponse{}, ErrInternal
+	}
+
+	itemHistory, err := s.repository.ItemTransfer().GetHistoryByUserID(ctx, userID)
+
+	if err != nil {
+		return models.InfoResponse{}, ErrInternal
+	}
+
+	upcomingExpirations, err := s.expiry.UpcomingExpirations(ctx, userID)
+
+	if err != nil {
+		return models.InfoResponse{}, err
+	}
+
 	return models.InfoResponse{
-		Coins:       balance,
-		Inventory:   inventory,
-		CoinHistory: coinHistory,
+		Coins:               balance,
+		Inventory:           inventory,
+		CoinHistory:         coinHistory,
+		GiftHistory:         giftHistory,
+		ItemHistory:         itemHistory,
+		UpcomingExpirations: upcomingExpirations,
 	}, nil
 }
+
+func (s *infoServiceImpl) GetBalance(ctx context.Context, userID uint) (int, error) {
+	balance, err := s.repository.User().GetBalance(ctx, userID)
+	if err != nil {
+		s.logger.Error("user not exists but token is valid", zap.Error(err), zap.Uint("userID", userID))
+		return 0, ErrInternal
+	}
+
+	return balance, nil
+}
+
+func (s *infoServiceImpl) GetInventory(ctx context.Context, userID uint) ([]models.Item, error) {
+	inventory, err := s.repository.Purchase().GetInventoryByUserID(ctx, userID)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	return inventory, nil
+}
+
+func (s *infoServiceImpl) GetTransfers(ctx context.Context, userID uint, limit, offset int) (models.TransferHistoryPage, error) {
+	if limit <= 0 || limit > maxHistoryPageSize || offset < 0 {
+		return models.TransferHistoryPage{}, ErrInvalidPage
+	}
+
+	page, err := s.repository.Transaction().GetTransfersPage(ctx, userID, limit, offset)
+	if err != nil {
+		return models.TransferHistoryPage{}, ErrInternal
+	}
+
+	return page, nil
+}
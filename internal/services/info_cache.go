@@ -0,0 +1,222 @@
+package services
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/maksemen2/avito-shop/config"
+	"github.com/maksemen2/avito-shop/internal/models"
+	"github.com/maksemen2/avito-shop/internal/repository"
+)
+
+// InfoCache хранит сводки /api/info по пользователям.
+// Set получает момент loadedAt, когда началось чтение info из базы, и не должен сохранять её,
+// если после этого момента пользователь был сброшен через Invalidate: такая сводка может не содержать изменения.
+type InfoCache interface {
+	Get(ctx context.Context, userID uint) (models.InfoResponse, bool)
+	Set(ctx context.Context, userID uint, info models.InfoResponse, loadedAt time.Time)
+	Invalidate(ctx context.Context, userIDs ...uint)
+}
+
+// NewInfoCache создаёт кеш сводок по конфигурации или возвращает nil, если кеш отключён.
+func NewInfoCache(cfg config.CacheConfig) InfoCache {
+	if cfg.InfoSize <= 0 || cfg.InfoTTLSeconds <= 0 {
+		return nil
+	}
+
+	return NewLRUInfoCache(cfg.InfoSize, time.Duration(cfg.InfoTTLSeconds)*time.Second)
+}
+
+type infoCacheEntry struct {
+	userID    uint
+	info      models.InfoResponse
+	expiresAt time.Time
+}
+
+type infoInvalidation struct {
+	userID uint
+	at     time.Time
+}
+
+// LRUInfoCache - InfoCache в памяти процесса не больше чем на size пользователей.
+// Записи появляются только через Set, поэтому промахи по неизвестным пользователям не вытесняют сохранённые сводки.
+// Время сброса через Invalidate хранится отдельно от записей в течение ttl, чтобы Set мог отбросить сводку,
+// чтение которой началось до сброса, даже если записи пользователя в кеше не было.
+type LRUInfoCache struct {
+	size    int
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[uint]*list.Element
+	order   *list.List
+
+	invalidations     map[uint]*list.Element
+	invalidationOrder *list.List
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+func NewLRUInfoCache(size int, ttl time.Duration) *LRUInfoCache {
+	return &LRUInfoCache{
+		size:              size,
+		ttl:               ttl,
+		entries:           make(map[uint]*list.Element),
+		order:             list.New(),
+		invalidations:     make(map[uint]*list.Element),
+		invalidationOrder: list.New(),
+	}
+}
+
+func (c *LRUInfoCache) Get(_ context.Context, userID uint) (models.InfoResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[userID]; ok {
+		entry := element.Value.(*infoCacheEntry)
+		if time.Now().Before(entry.expiresAt) {
+			c.order.MoveToFront(element)
+			c.hits.Add(1)
+
+			return entry.info, true
+		}
+
+		c.remove(element)
+	}
+
+	c.misses.Add(1)
+
+	return models.InfoResponse{}, false
+}
+
+// Set не сохраняет сводку, если пользователь сбрасывался не раньше loadedAt.
+func (c *LRUInfoCache) Set(_ context.Context, userID uint, info models.InfoResponse, loadedAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	c.pruneInvalidations(now)
+
+	if element, ok := c.invalidations[userID]; ok && !element.Value.(*infoInvalidation).at.Before(loadedAt) {
+		return
+	}
+
+	entry := &infoCacheEntry{userID: userID, info: info, expiresAt: now.Add(c.ttl)}
+
+	if element, ok := c.entries[userID]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+
+		return
+	}
+
+	c.entries[userID] = c.order.PushFront(entry)
+	c.evict()
+}
+
+func (c *LRUInfoCache) Invalidate(_ context.Context, userIDs ...uint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+
+	for _, userID := range userIDs {
+		if element, ok := c.entries[userID]; ok {
+			c.remove(element)
+		}
+
+		if element, ok := c.invalidations[userID]; ok {
+			element.Value.(*infoInvalidation).at = now
+			c.invalidationOrder.MoveToBack(element)
+		} else {
+			c.invalidations[userID] = c.invalidationOrder.PushBack(&infoInvalidation{userID: userID, at: now})
+		}
+	}
+
+	c.pruneInvalidations(now)
+}
+
+// Stats возвращает счётчики кеша с момента запуска.
+func (c *LRUInfoCache) Stats() repository.CacheStats {
+	c.mu.Lock()
+	entries := c.order.Len()
+	c.mu.Unlock()
+
+	return repository.CacheStats{
+		Hits:    c.hits.Load(),
+		Misses:  c.misses.Load(),
+		Entries: entries,
+	}
+}
+
+// evict вытесняет давно не использованные записи сверх size.
+func (c *LRUInfoCache) evict() {
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *LRUInfoCache) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*infoCacheEntry).userID)
+}
+
+// pruneInvalidations забывает сбросы старше ttl. Чтение сводки занимает намного меньше ttl,
+// поэтому такие сбросы уже не могут отбросить ни одну сводку.
+func (c *LRUInfoCache) pruneInvalidations(now time.Time) {
+	for oldest := c.invalidationOrder.Front(); oldest != nil; oldest = c.invalidationOrder.Front() {
+		invalidation := oldest.Value.(*infoInvalidation)
+		if now.Sub(invalidation.at) < c.ttl {
+			return
+		}
+
+		c.invalidationOrder.Remove(oldest)
+		delete(c.invalidations, invalidation.userID)
+	}
+}
+
+// cachedInfoService отдаёт GetInfo из кеша. Остальные методы дешёвые и всегда читают базу.
+type cachedInfoService struct {
+	InfoService
+	cache InfoCache
+}
+
+// NewCachedInfoService кеширует сводки, которые отдаёт inner, или возвращает inner, если cache равен nil.
+// Сбрасывать кеш при изменениях должен тот, кто их вносит, обычно через repository.WithUserChangeHook(InvalidationHook(cache)).
+func NewCachedInfoService(inner InfoService, cache InfoCache) InfoService {
+	if cache == nil {
+		return inner
+	}
+
+	return &cachedInfoService{
+		InfoService: inner,
+		cache:       cache,
+	}
+}
+
+func (s *cachedInfoService) GetInfo(ctx context.Context, userID uint) (models.InfoResponse, error) {
+	if info, ok := s.cache.Get(ctx, userID); ok {
+		return info, nil
+	}
+
+	loadedAt := time.Now()
+
+	// Отстающая реплика вернула бы данные до последнего изменения, и они провисели бы в кеше весь TTL
+	info, err := s.InfoService.GetInfo(repository.WithPrimaryReads(ctx), userID)
+	if err != nil {
+		return models.InfoResponse{}, err
+	}
+
+	s.cache.Set(ctx, userID, info, loadedAt)
+
+	return info, nil
+}
+
+// InvalidationHook возвращает хук репозитория, который сбрасывает в cache сводки затронутых операцией пользователей.
+func InvalidationHook(cache InfoCache) repository.UserChangeHook {
+	return func(ctx context.Context, _ repository.HolderRepository, change repository.UserChange) {
+		cache.Invalidate(ctx, change.UserIDs...)
+	}
+}
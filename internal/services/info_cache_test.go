@@ -0,0 +1,196 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/internal/models"
+	"github.com/maksemen2/avito-shop/internal/repository"
+	"github.com/maksemen2/avito-shop/internal/services"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+func TestLRUInfoCache(t *testing.T) {
+	cache := services.NewLRUInfoCache(2, time.Minute)
+	ctx := context.Background()
+
+	_, ok := cache.Get(ctx, 1)
+	assert.False(t, ok)
+
+	cache.Set(ctx, 1, models.InfoResponse{Coins: 10}, time.Now())
+
+	info, ok := cache.Get(ctx, 1)
+	assert.True(t, ok)
+	assert.Equal(t, 10, info.Coins)
+
+	// Сводка, чтение которой началось до сброса, не должна вернуться в кеш
+	loadedAt := time.Now()
+	cache.Invalidate(ctx, 1)
+
+	_, ok = cache.Get(ctx, 1)
+	assert.False(t, ok)
+
+	cache.Set(ctx, 1, models.InfoResponse{Coins: 10}, loadedAt)
+
+	_, ok = cache.Get(ctx, 1)
+	assert.False(t, ok, "stale info must be dropped")
+
+	cache.Set(ctx, 1, models.InfoResponse{Coins: 5}, time.Now())
+
+	info, ok = cache.Get(ctx, 1)
+	assert.True(t, ok)
+	assert.Equal(t, 5, info.Coins)
+
+	// Третий пользователь вытесняет давно не запрашивавшегося
+	for _, userID := range []uint{2, 3} {
+		cache.Get(ctx, userID)
+		cache.Set(ctx, userID, models.InfoResponse{Coins: int(userID)}, time.Now())
+	}
+
+	_, ok = cache.Get(ctx, 1)
+	assert.False(t, ok, "least recently used entry should be evicted")
+
+	// Промахи по неизвестным пользователям не вытесняют сохранённые сводки
+	for userID := uint(10); userID < 20; userID++ {
+		cache.Get(ctx, userID)
+	}
+
+	info, ok = cache.Get(ctx, 3)
+	assert.True(t, ok, "misses must not evict cached entries")
+	assert.Equal(t, 3, info.Coins)
+
+	// Сброс учитывается и для пользователя, которого нет в кеше
+	loadedAt = time.Now()
+	cache.Invalidate(ctx, 4)
+	cache.Set(ctx, 4, models.InfoResponse{Coins: 4}, loadedAt)
+
+	_, ok = cache.Get(ctx, 4)
+	assert.False(t, ok, "stale info must be dropped for uncached user")
+
+	assert.Equal(t, repository.CacheStats{Hits: 3, Misses: 17, Entries: 2}, cache.Stats())
+}
+
+func openInfoCacheTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormLogger.Default.LogMode(gormLogger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to open in-memory database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&database.User{}, &database.Purchase{}, &database.Transaction{}, &database.Good{}, &database.CoinRequest{}, &database.GrantProgram{}, &database.Grant{}, &database.CoinLot{}, &database.CoinExpiration{}, &database.ItemTransfer{}, &database.Listing{}, &database.PromoCode{}, &database.PromoRedemption{}, &database.PriceSchedule{}, &database.GoodVariant{}, &database.WishlistItem{}, &database.Notification{}, &database.OutboxEvent{}, &database.Webhook{}, &database.WebhookDelivery{}); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	return db
+}
+
+func TestCachedInfoService_Invalidation(t *testing.T) {
+	db := openInfoCacheTestDB(t)
+
+	logger := zap.NewNop()
+	cache := services.NewLRUInfoCache(10, time.Minute)
+	holderRepo := repository.NewHolderRepository(db, logger, repository.WithUserChangeHook(services.InvalidationHook(cache)))
+	infoService := services.NewCachedInfoService(services.NewInfoService(holderRepo, logger), cache)
+	ctx := context.Background()
+
+	sender := database.User{Username: "sender", PasswordHash: "pass"}
+	receiver := database.User{Username: "receiver", PasswordHash: "pass"}
+	good := database.Good{Type: "cup", Price: 20}
+
+	for _, record := range []any{&sender, &receiver, &good} {
+		if err := db.Create(record).Error; err != nil {
+			t.Fatalf("failed to create %T: %v", record, err)
+		}
+	}
+
+	for _, userID := range []uint{sender.ID, receiver.ID} {
+		_, err := infoService.GetInfo(ctx, userID)
+		assert.NoError(t, err)
+	}
+
+	// Изменение в обход репозитория не сбрасывает кеш
+	assert.NoError(t, db.Model(&database.User{}).Where("id = ?", sender.ID).UpdateColumn("coins", 900).Error)
+
+	info, err := infoService.GetInfo(ctx, sender.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1000, info.Coins, "info should be served from the cache")
+
+	assert.NoError(t, holderRepo.TransferCoins(ctx, sender.ID, receiver.ID, 100, 0))
+
+	info, err = infoService.GetInfo(ctx, sender.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 800, info.Coins, "transfer should invalidate the sender")
+	assert.Len(t, info.CoinHistory.Sent, 1)
+
+	info, err = infoService.GetInfo(ctx, receiver.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1100, info.Coins, "transfer should invalidate the receiver")
+
+	assert.NoError(t, holderRepo.BuyItem(ctx, receiver.ID, good.ID, nil, good.Price))
+
+	info, err = infoService.GetInfo(ctx, receiver.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1080, info.Coins, "purchase should invalidate the buyer")
+	assert.Equal(t, []models.Item{{Type: "cup", Quantity: 1}}, info.Inventory)
+
+	// Неудачная операция кеш не сбрасывает
+	assert.ErrorIs(t, holderRepo.TransferCoins(ctx, sender.ID, receiver.ID, 5000, 0), repository.ErrInsufficientFunds)
+
+	_, err = infoService.GetInfo(ctx, sender.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), cache.Stats().Hits)
+
+	program, err := holderRepo.Grant().CreateProgram(ctx, "monthly", 50, "monthly")
+	if err != nil {
+		t.Fatalf("failed to create grant program: %v", err)
+	}
+
+	_, err = holderRepo.Grant().ApplyProgram(ctx, *program, "2026-10")
+	assert.NoError(t, err)
+
+	info, err = infoService.GetInfo(ctx, sender.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 850, info.Coins, "grant should invalidate its recipients")
+}
+
+func TestCachedInfoService_LaggingReplica(t *testing.T) {
+	primary := openInfoCacheTestDB(t)
+	replica := openInfoCacheTestDB(t)
+
+	logger := zap.NewNop()
+	cache := services.NewLRUInfoCache(10, time.Minute)
+	holderRepo := repository.NewHolderRepository(primary, logger,
+		repository.WithReadReplicas(replica),
+		repository.WithUserChangeHook(services.InvalidationHook(cache)))
+	infoService := services.NewCachedInfoService(services.NewInfoService(holderRepo, logger), cache)
+	ctx := context.Background()
+
+	// Реплика получила пользователей, но не получит перевод
+	for _, db := range []*gorm.DB{primary, replica} {
+		for _, username := range []string{"sender", "receiver"} {
+			if err := db.Create(&database.User{Username: username, PasswordHash: "pass"}).Error; err != nil {
+				t.Fatalf("failed to create user: %v", err)
+			}
+		}
+	}
+
+	info, err := infoService.GetInfo(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1000, info.Coins)
+
+	assert.NoError(t, holderRepo.TransferCoins(ctx, 1, 2, 100, 0))
+
+	for range 2 {
+		info, err = infoService.GetInfo(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, 900, info.Coins, "cache miss must not store the lagging replica's info")
+		assert.Len(t, info.CoinHistory.Sent, 1)
+	}
+
+	assert.Equal(t, uint64(1), cache.Stats().Hits)
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/maksemen2/avito-shop/internal/models"
+	"github.com/maksemen2/avito-shop/internal/repository"
+	"go.uber.org/zap"
+)
+
+type ItemTransferService interface {
+	SendItems(ctx context.Context, senderID uint, senderUsername string, req models.SendItemRequest) error
+}
+
+type itemTransferServiceImpl struct {
+	repository repository.HolderRepository
+	logger     *zap.Logger
+}
+
+func NewItemTransferService(repository repository.HolderRepository, logger *zap.Logger) ItemTransferService {
+	return &itemTransferServiceImpl{repository: repository, logger: logger}
+}
+
+// SendItems передаёт req.Quantity единиц товара варианта req.Variant из инвентаря отправителя в инвентарь req.ToUser.
+func (s *itemTransferServiceImpl) SendItems(ctx context.Context, senderID uint, senderUsername string, req models.SendItemRequest) error {
+	if req.ToUser == "" {
+		return ErrToUserRequired
+	}
+
+	if req.Item == "" {
+		return ErrItemTypeRequired
+	}
+
+	if req.Quantity <= 0 {
+		return ErrQuantityBelowZero
+	}
+
+	if senderUsername == req.ToUser {
+		return ErrCantSelfSendItem
+	}
+
+	receiverID, err := s.repository.User().GetIDByUsername(ctx, req.ToUser)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return ErrRecieverNotFound
+		}
+
+		return ErrInternal
+	}
+
+	good, err := s.repository.Good().GetByName(ctx, req.Item)
+	if err != nil {
+		if errors.Is(err, repository.ErrGoodNotFound) {
+			return ErrItemNotFound
+		}
+
+		return ErrInternal
+	}
+
+	variant, err := resolveVariant(ctx, s.repository, good, req.Variant)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repository.TransferItems(ctx, senderID, receiverID, good.ID, variantID(variant), req.Quantity); err != nil {
+		if errors.Is(err, repository.ErrInsufficientItems) {
+			return ErrInsufficientItems
+		}
+
+		return ErrInternal
+	}
+
+	return nil
+}
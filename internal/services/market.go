@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/maksemen2/avito-shop/config"
+	"github.com/maksemen2/avito-shop/internal/models"
+	"github.com/maksemen2/avito-shop/internal/repository"
+	"go.uber.org/zap"
+)
+
+type MarketService interface {
+	CreateListing(ctx context.Context, sellerID uint, sellerUsername string, req models.CreateListingRequest) (models.Listing, error)
+	CancelListing(ctx context.Context, sellerID, listingID uint) error
+	BuyListing(ctx context.Context, buyerID, listingID uint) error
+	ListListings(ctx context.Context, item, seller string) ([]models.Listing, error)
+}
+
+type marketServiceImpl struct {
+	repository repository.HolderRepository
+	policy     *TransferPolicy
+	logger     *zap.Logger
+}
+
+func NewMarketService(repository repository.HolderRepository, transferConfig config.TransferConfig, logger *zap.Logger) MarketService {
+	return &marketServiceImpl{
+		repository: repository,
+		policy:     NewTransferPolicy(transferConfig, repository, logger),
+		logger:     logger,
+	}
+}
+
+func (s *marketServiceImpl) CreateListing(
+	ctx context.Context,
+	sellerID uint,
+	sellerUsername string,
+	req models.CreateListingRequest,
+) (models.Listing, error) {
+	if req.Item == "" {
+		return models.Listing{}, ErrItemTypeRequired
+	}
+
+	if req.Price <= 0 {
+		return models.Listing{}, ErrPriceBelowZero
+	}
+
+	good, err := s.repository.Good().GetByName(ctx, req.Item)
+	if err != nil {
+		if errors.Is(err, repository.ErrGoodNotFound) {
+			return models.Listing{}, ErrItemNotFound
+		}
+
+		return models.Listing{}, ErrInternal
+	}
+
+	variant, err := resolveVariant(ctx, s.repository, good, req.Variant)
+	if err != nil {
+		return models.Listing{}, err
+	}
+
+	listing, err := s.repository.Listing().Create(ctx, sellerID, good.ID, variantID(variant), req.Price)
+	if err != nil {
+		if errors.Is(err, repository.ErrInsufficientItems) {
+			return models.Listing{}, ErrInsufficientItems
+		}
+
+		return models.Listing{}, ErrInternal
+	}
+
+	return models.Listing{
+		ID:        listing.ID,
+		Seller:    sellerUsername,
+		Item:      good.Type,
+		Variant:   req.Variant,
+		Price:     listing.Price,
+		Status:    listing.Status,
+		CreatedAt: listing.CreatedAt,
+	}, nil
+}
+
+func (s *marketServiceImpl) CancelListing(ctx context.Context, sellerID, listingID uint) error {
+	if err := s.repository.Listing().Cancel(ctx, listingID, sellerID); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrListingNotFound):
+			return ErrListingNotFound
+		case errors.Is(err, repository.ErrListingClosed):
+			return ErrListingClosed
+		default:
+			return ErrInternal
+		}
+	}
+
+	return nil
+}
+
+// BuyListing покупает товар по объявлению. Оплата продавцу - это перевод монет между пользователями,
+// поэтому на неё распространяются ограничения на сумму перевода и возраст аккаунта, но не суточный лимит.
+func (s *marketServiceImpl) BuyListing(ctx context.Context, buyerID, listingID uint) error {
+	listing, err := s.repository.Listing().GetByID(ctx, listingID)
+	if err != nil {
+		if errors.Is(err, repository.ErrListingNotFound) {
+			return ErrListingNotFound
+		}
+
+		return ErrInternal
+	}
+
+	if listing.SellerID == buyerID {
+		return ErrCantBuyOwnListing
+	}
+
+	if err := s.policy.Check(ctx, buyerID, listing.Price); err != nil {
+		return err
+	}
+
+	if err := s.repository.BuyListing(ctx, listingID, buyerID); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrListingNotFound):
+			return ErrListingNotFound
+		case errors.Is(err, repository.ErrListingClosed):
+			return ErrListingClosed
+		case errors.Is(err, repository.ErrInsufficientFunds):
+			return ErrInsufficientFunds
+		case errors.Is(err, repository.ErrUserLocked):
+			return ErrUserLocked
+		default:
+			return ErrInternal
+		}
+	}
+
+	return nil
+}
+
+func (s *marketServiceImpl) ListListings(ctx context.Context, item, seller string) ([]models.Listing, error) {
+	listings, err := s.repository.Listing().GetActive(ctx, item, seller)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	return listings, nil
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/internal/models"
+	"github.com/maksemen2/avito-shop/internal/repository"
+	"go.uber.org/zap"
+)
+
+// Максимальная длина текста уведомления, совпадает с размером колонки notifications.message
+const maxNotificationLength = 512
+
+type NotificationService interface {
+	List(ctx context.Context, userID uint, unreadOnly bool) (models.NotificationsResponse, error)
+	MarkRead(ctx context.Context, userID, notificationID uint) error
+	MarkAllRead(ctx context.Context, userID uint) (int64, error)
+	Send(ctx context.Context, req models.AdminNotificationRequest) (int64, error)
+}
+
+type notificationServiceImpl struct {
+	repository repository.HolderRepository
+	logger     *zap.Logger
+}
+
+func NewNotificationService(repository repository.HolderRepository, logger *zap.Logger) NotificationService {
+	return &notificationServiceImpl{repository: repository, logger: logger}
+}
+
+func (s *notificationServiceImpl) List(ctx context.Context, userID uint, unreadOnly bool) (models.NotificationsResponse, error) {
+	notifications, err := s.repository.Notification().GetByUserID(ctx, userID, unreadOnly)
+	if err != nil {
+		return models.NotificationsResponse{}, ErrInternal
+	}
+
+	unread, err := s.repository.Notification().CountUnread(ctx, userID)
+	if err != nil {
+		return models.NotificationsResponse{}, ErrInternal
+	}
+
+	resp := models.NotificationsResponse{
+		Unread:        unread,
+		Notifications: make([]models.Notification, 0, len(notifications)),
+	}
+
+	for _, notification := range notifications {
+		resp.Notifications = append(resp.Notifications, models.Notification{
+			ID:        notification.ID,
+			Type:      notification.Type,
+			Message:   notification.Message,
+			Read:      notification.ReadAt != nil,
+			ReadAt:    notification.ReadAt,
+			CreatedAt: notification.CreatedAt,
+		})
+	}
+
+	return resp, nil
+}
+
+func (s *notificationServiceImpl) MarkRead(ctx context.Context, userID, notificationID uint) error {
+	if err := s.repository.Notification().MarkRead(ctx, userID, notificationID); err != nil {
+		if errors.Is(err, repository.ErrNotificationNotFound) {
+			return ErrNotificationNotFound
+		}
+
+		return ErrInternal
+	}
+
+	return nil
+}
+
+func (s *notificationServiceImpl) MarkAllRead(ctx context.Context, userID uint) (int64, error) {
+	marked, err := s.repository.Notification().MarkAllRead(ctx, userID)
+	if err != nil {
+		return 0, ErrInternal
+	}
+
+	return marked, nil
+}
+
+// Send отправляет уведомление от администратора пользователю req.ToUser или всем пользователям, если он не указан.
+// Возвращает количество отправленных уведомлений.
+func (s *notificationServiceImpl) Send(ctx context.Context, req models.AdminNotificationRequest) (int64, error) {
+	message := strings.TrimSpace(req.Message)
+	if message == "" {
+		return 0, ErrMessageRequired
+	}
+
+	if len(message) > maxNotificationLength {
+		return 0, ErrMessageTooLong
+	}
+
+	if req.ToUser == "" {
+		sent, err := s.repository.Notification().CreateForAll(ctx, database.NotificationAdmin, message)
+		if err != nil {
+			return 0, ErrInternal
+		}
+
+		return sent, nil
+	}
+
+	userID, err := s.repository.User().GetIDByUsername(ctx, req.ToUser)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return 0, ErrRecieverNotFound
+		}
+
+		return 0, ErrInternal
+	}
+
+	if err := s.repository.Notification().Create(ctx, userID, database.NotificationAdmin, message); err != nil {
+		return 0, ErrInternal
+	}
+
+	return 1, nil
+}
@@ -0,0 +1,246 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/internal/models"
+	"github.com/maksemen2/avito-shop/internal/repository"
+	"go.uber.org/zap"
+)
+
+type PricingService interface {
+	CreateSchedule(ctx context.Context, req models.CreatePriceScheduleRequest) (models.PriceSchedule, error)
+	ListSchedules(ctx context.Context) ([]models.PriceSchedule, error)
+	DeleteSchedule(ctx context.Context, scheduleID uint) error
+	Catalog(ctx context.Context, now time.Time) ([]models.CatalogItem, error)
+	NotifySales(ctx context.Context, now time.Time) (int64, error)
+}
+
+type pricingServiceImpl struct {
+	repository repository.HolderRepository
+	logger     *zap.Logger
+}
+
+func NewPricingService(repository repository.HolderRepository, logger *zap.Logger) PricingService {
+	return &pricingServiceImpl{repository: repository, logger: logger}
+}
+
+func (s *pricingServiceImpl) CreateSchedule(ctx context.Context, req models.CreatePriceScheduleRequest) (models.PriceSchedule, error) {
+	if req.Item == "" {
+		return models.PriceSchedule{}, ErrItemTypeRequired
+	}
+
+	// Расписание задаётся ровно одним способом: новой ценой или скидкой в процентах
+	if (req.Price > 0) == (req.DiscountPercent > 0) || req.Price < 0 || req.DiscountPercent < 0 || req.DiscountPercent >= 100 {
+		return models.PriceSchedule{}, ErrInvalidSchedulePrice
+	}
+
+	if req.StartsAt.IsZero() || !req.EndsAt.After(req.StartsAt) {
+		return models.PriceSchedule{}, ErrInvalidScheduleWindow
+	}
+
+	good, err := s.repository.Good().GetByName(ctx, req.Item)
+	if err != nil {
+		if errors.Is(err, repository.ErrGoodNotFound) {
+			return models.PriceSchedule{}, ErrItemNotFound
+		}
+
+		return models.PriceSchedule{}, ErrInternal
+	}
+
+	schedule := &database.PriceSchedule{
+		GoodID:          good.ID,
+		Good:            good,
+		Price:           req.Price,
+		DiscountPercent: req.DiscountPercent,
+		StartsAt:        req.StartsAt,
+		EndsAt:          req.EndsAt,
+	}
+
+	if err := s.repository.PriceSchedule().Create(ctx, schedule); err != nil {
+		return models.PriceSchedule{}, ErrInternal
+	}
+
+	// Об уже начавшейся распродаже уведомляем сразу, не дожидаясь фоновой задачи.
+	// Расписание уже создано, поэтому ошибка уведомления не должна превращаться в ошибку запроса
+	if !schedule.StartsAt.After(time.Now()) {
+		if _, err := s.NotifySales(ctx, time.Now()); err != nil {
+			s.logger.Warn("failed to notify about started sales", zap.Error(err))
+		}
+	}
+
+	return toPriceScheduleModel(*schedule), nil
+}
+
+func (s *pricingServiceImpl) ListSchedules(ctx context.Context) ([]models.PriceSchedule, error) {
+	schedules, err := s.repository.PriceSchedule().GetAll(ctx)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	result := make([]models.PriceSchedule, 0, len(schedules))
+	for _, schedule := range schedules {
+		result = append(result, toPriceScheduleModel(schedule))
+	}
+
+	return result, nil
+}
+
+func (s *pricingServiceImpl) DeleteSchedule(ctx context.Context, scheduleID uint) error {
+	if err := s.repository.PriceSchedule().Delete(ctx, scheduleID); err != nil {
+		if errors.Is(err, repository.ErrPriceScheduleNotFound) {
+			return ErrPriceScheduleNotFound
+		}
+
+		return ErrInternal
+	}
+
+	return nil
+}
+
+// Catalog возвращает все товары с ценами, действующими в момент now.
+func (s *pricingServiceImpl) Catalog(ctx context.Context, now time.Time) ([]models.CatalogItem, error) {
+	goods, err := s.repository.Good().GetAll(ctx)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	schedules, err := s.repository.PriceSchedule().GetActive(ctx, now)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	schedulesByGood := make(map[uint][]database.PriceSchedule)
+	for _, schedule := range schedules {
+		schedulesByGood[schedule.GoodID] = append(schedulesByGood[schedule.GoodID], schedule)
+	}
+
+	variants, err := s.repository.Variant().GetAll(ctx)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	variantsByGood := make(map[uint][]database.GoodVariant)
+	for _, variant := range variants {
+		variantsByGood[variant.GoodID] = append(variantsByGood[variant.GoodID], variant)
+	}
+
+	catalog := make([]models.CatalogItem, 0, len(goods))
+
+	for _, good := range goods {
+		item := models.CatalogItem{Type: good.Type, Price: good.Price}
+
+		if price, schedule := scheduledPrice(good.Price, schedulesByGood[good.ID]); schedule != nil {
+			originalPrice := good.Price
+			item.Price = price
+			item.OriginalPrice = &originalPrice
+			item.SaleEndsAt = &schedule.EndsAt
+		}
+
+		for _, variant := range variantsByGood[good.ID] {
+			item.Variants = append(item.Variants, models.CatalogVariant{
+				Name:  variant.Name,
+				Price: max(item.Price+variant.PriceDelta, 0),
+				Stock: variant.Stock,
+			})
+		}
+
+		catalog = append(catalog, item)
+	}
+
+	return catalog, nil
+}
+
+// NotifySales уведомляет пользователей о начавшихся к моменту now распродажах товаров из их списков желаний.
+// Каждое расписание объявляется один раз, расписания, повышающие цену, отмечаются без уведомлений.
+// Возвращает количество созданных уведомлений.
+func (s *pricingServiceImpl) NotifySales(ctx context.Context, now time.Time) (int64, error) {
+	schedules, err := s.repository.PriceSchedule().GetStartedUnnotified(ctx, now)
+	if err != nil {
+		return 0, ErrInternal
+	}
+
+	var (
+		total  int64
+		failed bool
+	)
+
+	for _, schedule := range schedules {
+		message := ""
+
+		if price, _ := scheduledPrice(schedule.Good.Price, []database.PriceSchedule{schedule}); price < schedule.Good.Price {
+			message = fmt.Sprintf("%s is on sale for %d coins instead of %d until %s",
+				schedule.Good.Type, price, schedule.Good.Price, schedule.EndsAt.UTC().Format(time.RFC3339))
+		}
+
+		created, err := s.repository.PriceSchedule().NotifyStarted(ctx, schedule, message)
+		if err != nil {
+			failed = true
+			continue
+		}
+
+		total += created
+	}
+
+	if failed {
+		return total, ErrInternal
+	}
+
+	return total, nil
+}
+
+// currentPrice возвращает цену товара good в момент now с учётом действующих расписаний.
+func currentPrice(ctx context.Context, repo repository.HolderRepository, good *database.Good, now time.Time) (int, error) {
+	schedules, err := repo.PriceSchedule().GetActiveByGoodID(ctx, good.ID, now)
+	if err != nil {
+		return 0, ErrInternal
+	}
+
+	price, _ := scheduledPrice(good.Price, schedules)
+
+	return price, nil
+}
+
+// scheduledPrice применяет к базовой цене действующие расписания и возвращает итоговую цену
+// вместе с расписанием, которое её задаёт. Если расписания пересекаются, действует самая низкая цена.
+// Без действующих расписаний возвращается базовая цена и nil.
+func scheduledPrice(basePrice int, schedules []database.PriceSchedule) (int, *database.PriceSchedule) {
+	price := basePrice
+
+	var applied *database.PriceSchedule
+
+	for i := range schedules {
+		schedulePrice := schedules[i].Price
+		if schedules[i].DiscountPercent > 0 {
+			schedulePrice = basePrice * (100 - schedules[i].DiscountPercent) / 100
+		}
+
+		if applied == nil || schedulePrice < price {
+			price = schedulePrice
+			applied = &schedules[i]
+		}
+	}
+
+	return price, applied
+}
+
+func toPriceScheduleModel(schedule database.PriceSchedule) models.PriceSchedule {
+	result := models.PriceSchedule{
+		ID:              schedule.ID,
+		Price:           schedule.Price,
+		DiscountPercent: schedule.DiscountPercent,
+		StartsAt:        schedule.StartsAt,
+		EndsAt:          schedule.EndsAt,
+		CreatedAt:       schedule.CreatedAt,
+	}
+
+	if schedule.Good != nil {
+		result.Item = schedule.Good.Type
+	}
+
+	return result
+}
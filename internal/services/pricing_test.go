@@ -0,0 +1,101 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/internal/models"
+	"github.com/maksemen2/avito-shop/internal/repository"
+	"github.com/maksemen2/avito-shop/internal/services"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func getMockPricingServices(t *testing.T) (services.PricingService, services.PurchaseService, *gorm.DB) {
+	purchaseService, db := getMockPurchaseService(t)
+
+	logger := zap.NewNop()
+	pricingService := services.NewPricingService(repository.NewHolderRepository(db, logger), logger)
+
+	return pricingService, purchaseService, db
+}
+
+func TestPriceSchedule_AppliedAtPurchase(t *testing.T) {
+	pricingSrv, purchaseSrv, db := getMockPricingServices(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	_, err := pricingSrv.CreateSchedule(ctx, models.CreatePriceScheduleRequest{
+		Item:            "hoody",
+		DiscountPercent: 20,
+		StartsAt:        now.Add(-time.Hour),
+		EndsAt:          now.Add(7 * 24 * time.Hour),
+	})
+	assert.NoError(t, err, "failed to create schedule")
+
+	// Пересекающееся расписание с более высокой ценой не должно применяться
+	_, err = pricingSrv.CreateSchedule(ctx, models.CreatePriceScheduleRequest{
+		Item:     "hoody",
+		Price:    250,
+		StartsAt: now.Add(-time.Hour),
+		EndsAt:   now.Add(time.Hour),
+	})
+	assert.NoError(t, err, "failed to create schedule")
+
+	// Завершившееся расписание не должно применяться
+	_, err = pricingSrv.CreateSchedule(ctx, models.CreatePriceScheduleRequest{
+		Item:     "cup",
+		Price:    1,
+		StartsAt: now.Add(-2 * time.Hour),
+		EndsAt:   now.Add(-time.Hour),
+	})
+	assert.NoError(t, err, "failed to create schedule")
+
+	user := database.User{Username: "buyer", PasswordHash: "test"}
+	assert.NoError(t, db.Create(&user).Error, "failed to create user")
+
+	assert.NoError(t, purchaseSrv.BuyGood(ctx, user.ID, "hoody", models.BuyOptions{}))
+	assert.NoError(t, purchaseSrv.BuyGood(ctx, user.ID, "cup", models.BuyOptions{}))
+
+	assert.NoError(t, db.First(&user, user.ID).Error, "failed to fetch user")
+	assert.Equal(t, 1000-240-20, user.Coins, "expected scheduled price for hoody and base price for cup")
+
+	catalog, err := pricingSrv.Catalog(ctx, now)
+	assert.NoError(t, err)
+
+	for _, item := range catalog {
+		switch item.Type {
+		case "hoody":
+			assert.Equal(t, 240, item.Price)
+			assert.NotNil(t, item.OriginalPrice)
+			assert.Equal(t, 300, *item.OriginalPrice)
+			assert.NotNil(t, item.SaleEndsAt)
+		case "cup":
+			assert.Equal(t, 20, item.Price)
+			assert.Nil(t, item.OriginalPrice)
+		}
+	}
+}
+
+func TestCreatePriceSchedule_Validation(t *testing.T) {
+	pricingSrv, _, _ := getMockPricingServices(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	_, err := pricingSrv.CreateSchedule(ctx, models.CreatePriceScheduleRequest{Item: "hoody", StartsAt: now, EndsAt: now.Add(time.Hour)})
+	assert.Equal(t, services.ErrInvalidSchedulePrice, err)
+
+	_, err = pricingSrv.CreateSchedule(ctx, models.CreatePriceScheduleRequest{Item: "hoody", Price: 100, DiscountPercent: 10, StartsAt: now, EndsAt: now.Add(time.Hour)})
+	assert.Equal(t, services.ErrInvalidSchedulePrice, err)
+
+	_, err = pricingSrv.CreateSchedule(ctx, models.CreatePriceScheduleRequest{Item: "hoody", Price: 100, StartsAt: now, EndsAt: now})
+	assert.Equal(t, services.ErrInvalidScheduleWindow, err)
+
+	_, err = pricingSrv.CreateSchedule(ctx, models.CreatePriceScheduleRequest{Item: "car", Price: 100, StartsAt: now, EndsAt: now.Add(time.Hour)})
+	assert.Equal(t, services.ErrItemNotFound, err)
+
+	assert.Equal(t, services.ErrPriceScheduleNotFound, pricingSrv.DeleteSchedule(ctx, 42))
+}
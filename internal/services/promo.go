@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/internal/models"
+	"github.com/maksemen2/avito-shop/internal/repository"
+	"go.uber.org/zap"
+)
+
+type PromoService interface {
+	CreatePromoCode(ctx context.Context, req models.CreatePromoCodeRequest) (models.PromoCode, error)
+	ListPromoCodes(ctx context.Context) ([]models.PromoCode, error)
+	SetPromoCodeActive(ctx context.Context, promoCodeID uint, active bool) error
+}
+
+type promoServiceImpl struct {
+	repository repository.HolderRepository
+	logger     *zap.Logger
+}
+
+func NewPromoService(repository repository.HolderRepository, logger *zap.Logger) PromoService {
+	return &promoServiceImpl{repository: repository, logger: logger}
+}
+
+func (s *promoServiceImpl) CreatePromoCode(ctx context.Context, req models.CreatePromoCodeRequest) (models.PromoCode, error) {
+	code := normalizePromoCode(req.Code)
+	if code == "" {
+		return models.PromoCode{}, ErrPromoCodeRequired
+	}
+
+	switch req.DiscountType {
+	case database.DiscountPercent:
+		if req.DiscountValue <= 0 || req.DiscountValue > 100 {
+			return models.PromoCode{}, ErrInvalidDiscountValue
+		}
+	case database.DiscountFixed:
+		if req.DiscountValue <= 0 {
+			return models.PromoCode{}, ErrInvalidDiscountValue
+		}
+	default:
+		return models.PromoCode{}, ErrInvalidDiscountType
+	}
+
+	if req.MaxUses < 0 || req.MaxUsesPerUser < 0 {
+		return models.PromoCode{}, ErrInvalidPromoLimits
+	}
+
+	if req.ValidFrom != nil && req.ValidUntil != nil && !req.ValidUntil.After(*req.ValidFrom) {
+		return models.PromoCode{}, ErrInvalidValidityWindow
+	}
+
+	promo := &database.PromoCode{
+		Code:           code,
+		DiscountType:   req.DiscountType,
+		DiscountValue:  req.DiscountValue,
+		MaxUses:        req.MaxUses,
+		MaxUsesPerUser: req.MaxUsesPerUser,
+		ValidFrom:      req.ValidFrom,
+		ValidUntil:     req.ValidUntil,
+		Active:         true,
+	}
+
+	if req.Item != "" {
+		good, err := s.repository.Good().GetByName(ctx, req.Item)
+		if err != nil {
+			if errors.Is(err, repository.ErrGoodNotFound) {
+				return models.PromoCode{}, ErrItemNotFound
+			}
+
+			return models.PromoCode{}, ErrInternal
+		}
+
+		promo.GoodID = &good.ID
+		promo.Good = good
+	}
+
+	if err := s.repository.PromoCode().Create(ctx, promo); err != nil {
+		if errors.Is(err, repository.ErrPromoCodeExists) {
+			return models.PromoCode{}, ErrPromoCodeExists
+		}
+
+		return models.PromoCode{}, ErrInternal
+	}
+
+	return toPromoCodeModel(*promo), nil
+}
+
+func (s *promoServiceImpl) ListPromoCodes(ctx context.Context) ([]models.PromoCode, error) {
+	promos, err := s.repository.PromoCode().GetAll(ctx)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	result := make([]models.PromoCode, 0, len(promos))
+	for _, promo := range promos {
+		result = append(result, toPromoCodeModel(promo))
+	}
+
+	return result, nil
+}
+
+func (s *promoServiceImpl) SetPromoCodeActive(ctx context.Context, promoCodeID uint, active bool) error {
+	if err := s.repository.PromoCode().SetActive(ctx, promoCodeID, active); err != nil {
+		if errors.Is(err, repository.ErrPromoCodeNotFound) {
+			return ErrPromoCodeNotFound
+		}
+
+		return ErrInternal
+	}
+
+	return nil
+}
+
+// resolvePromoCode находит промокод code и проверяет, что в момент now его можно применить к товару goodID.
+// Возвращает промокод и размер скидки в монетах от цены price. Лимиты использования проверяются при самой покупке.
+func resolvePromoCode(
+	ctx context.Context,
+	repo repository.HolderRepository,
+	code string,
+	goodID uint,
+	price int,
+	now time.Time,
+) (*database.PromoCode, int, error) {
+	promo, err := repo.PromoCode().GetByCode(ctx, normalizePromoCode(code))
+	if err != nil {
+		if errors.Is(err, repository.ErrPromoCodeNotFound) {
+			return nil, 0, ErrPromoCodeNotFound
+		}
+
+		return nil, 0, ErrInternal
+	}
+
+	if !promo.Active || (promo.ValidFrom != nil && now.Before(*promo.ValidFrom)) || (promo.ValidUntil != nil && !now.Before(*promo.ValidUntil)) {
+		return nil, 0, ErrPromoCodeInactive
+	}
+
+	if promo.GoodID != nil && *promo.GoodID != goodID {
+		return nil, 0, ErrPromoCodeNotApplicable
+	}
+
+	return promo, promoDiscount(promo, price), nil
+}
+
+// promoDiscount считает скидку по промокоду для цены price. Скидка не может превышать саму цену.
+func promoDiscount(promo *database.PromoCode, price int) int {
+	var discount int
+
+	switch promo.DiscountType {
+	case database.DiscountPercent:
+		discount = price * promo.DiscountValue / 100
+	case database.DiscountFixed:
+		discount = promo.DiscountValue
+	}
+
+	return min(discount, price)
+}
+
+// normalizePromoCode приводит промокод к каноничному виду, чтобы он не зависел от регистра при вводе.
+func normalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func toPromoCodeModel(promo database.PromoCode) models.PromoCode {
+	result := models.PromoCode{
+		ID:             promo.ID,
+		Code:           promo.Code,
+		DiscountType:   promo.DiscountType,
+		DiscountValue:  promo.DiscountValue,
+		MaxUses:        promo.MaxUses,
+		MaxUsesPerUser: promo.MaxUsesPerUser,
+		UsedCount:      promo.UsedCount,
+		ValidFrom:      promo.ValidFrom,
+		ValidUntil:     promo.ValidUntil,
+		Active:         promo.Active,
+		CreatedAt:      promo.CreatedAt,
+	}
+
+	if promo.Good != nil {
+		result.Item = promo.Good.Type
+	}
+
+	return result
+}
@@ -0,0 +1,150 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/internal/models"
+	"github.com/maksemen2/avito-shop/internal/repository"
+	"github.com/maksemen2/avito-shop/internal/services"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func getMockPromoServices(t *testing.T) (services.PromoService, services.PurchaseService, *gorm.DB) {
+	purchaseService, db := getMockPurchaseService(t)
+
+	logger := zap.NewNop()
+	promoService := services.NewPromoService(repository.NewHolderRepository(db, logger), logger)
+
+	return promoService, purchaseService, db
+}
+
+func createPromoTestUser(t *testing.T, db *gorm.DB, username string) database.User {
+	user := database.User{Username: username, PasswordHash: "test"}
+	assert.NoError(t, db.Create(&user).Error, "failed to create user")
+
+	return user
+}
+
+func TestBuyGood_PercentPromo(t *testing.T) {
+	promoSrv, purchaseSrv, db := getMockPromoServices(t)
+	ctx := context.Background()
+
+	_, err := promoSrv.CreatePromoCode(ctx, models.CreatePromoCodeRequest{
+		Code:          "hoody20",
+		DiscountType:  database.DiscountPercent,
+		DiscountValue: 20,
+		Item:          "hoody",
+	})
+	assert.NoError(t, err, "failed to create promo code")
+
+	user := createPromoTestUser(t, db, "buyer")
+
+	assert.Equal(t, services.ErrPromoCodeNotApplicable, purchaseSrv.BuyGood(ctx, user.ID, "cup", models.BuyOptions{Promo: "HOODY20"}))
+	assert.NoError(t, purchaseSrv.BuyGood(ctx, user.ID, "hoody", models.BuyOptions{Promo: " Hoody20 "}), "expected purchase with promo code")
+
+	assert.NoError(t, db.First(&user, user.ID).Error, "failed to fetch user")
+	assert.Equal(t, 760, user.Coins, "expected discounted price to be charged")
+
+	var purchase database.Purchase
+
+	assert.NoError(t, db.Where("user_id = ?", user.ID).First(&purchase).Error, "failed to fetch purchase")
+	assert.NotNil(t, purchase.PromoCodeID, "expected promo code to be recorded on purchase")
+	assert.Equal(t, 60, purchase.Discount, "expected discount to be recorded on purchase")
+
+	promos, err := promoSrv.ListPromoCodes(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, promos, 1)
+	assert.Equal(t, "HOODY20", promos[0].Code)
+	assert.Equal(t, "hoody", promos[0].Item)
+	assert.Equal(t, 1, promos[0].UsedCount)
+}
+
+func TestBuyGood_PromoLimits(t *testing.T) {
+	promoSrv, purchaseSrv, db := getMockPromoServices(t)
+	ctx := context.Background()
+
+	_, err := promoSrv.CreatePromoCode(ctx, models.CreatePromoCodeRequest{
+		Code:           "MINUS5",
+		DiscountType:   database.DiscountFixed,
+		DiscountValue:  5,
+		MaxUses:        2,
+		MaxUsesPerUser: 1,
+	})
+	assert.NoError(t, err, "failed to create promo code")
+
+	first := createPromoTestUser(t, db, "first")
+	second := createPromoTestUser(t, db, "second")
+	third := createPromoTestUser(t, db, "third")
+
+	assert.NoError(t, purchaseSrv.BuyGood(ctx, first.ID, "cup", models.BuyOptions{Promo: "MINUS5"}))
+	assert.Equal(t, services.ErrPromoCodeUserLimit, purchaseSrv.BuyGood(ctx, first.ID, "cup", models.BuyOptions{Promo: "MINUS5"}))
+	assert.NoError(t, purchaseSrv.BuyGood(ctx, second.ID, "socks", models.BuyOptions{Promo: "MINUS5"}))
+	assert.Equal(t, services.ErrPromoCodeExhausted, purchaseSrv.BuyGood(ctx, third.ID, "cup", models.BuyOptions{Promo: "MINUS5"}))
+
+	assert.NoError(t, db.First(&first, first.ID).Error, "failed to fetch user")
+	assert.Equal(t, 985, first.Coins, "failed purchase with promo code should be rolled back")
+
+	var purchases int64
+
+	db.Model(&database.Purchase{}).Where("user_id = ?", third.ID).Count(&purchases)
+	assert.Equal(t, int64(0), purchases, "exhausted promo code should not create a purchase")
+}
+
+func TestBuyGood_PromoValidity(t *testing.T) {
+	promoSrv, purchaseSrv, db := getMockPromoServices(t)
+	ctx := context.Background()
+
+	past := time.Now().Add(-48 * time.Hour)
+	yesterday := time.Now().Add(-24 * time.Hour)
+
+	_, err := promoSrv.CreatePromoCode(ctx, models.CreatePromoCodeRequest{
+		Code:          "OLD",
+		DiscountType:  database.DiscountFixed,
+		DiscountValue: 5,
+		ValidFrom:     &past,
+		ValidUntil:    &yesterday,
+	})
+	assert.NoError(t, err, "failed to create promo code")
+
+	promo, err := promoSrv.CreatePromoCode(ctx, models.CreatePromoCodeRequest{
+		Code:          "OFF",
+		DiscountType:  database.DiscountFixed,
+		DiscountValue: 5,
+	})
+	assert.NoError(t, err, "failed to create promo code")
+	assert.NoError(t, promoSrv.SetPromoCodeActive(ctx, promo.ID, false))
+
+	user := createPromoTestUser(t, db, "buyer")
+
+	assert.Equal(t, services.ErrPromoCodeInactive, purchaseSrv.BuyGood(ctx, user.ID, "cup", models.BuyOptions{Promo: "OLD"}))
+	assert.Equal(t, services.ErrPromoCodeInactive, purchaseSrv.BuyGood(ctx, user.ID, "cup", models.BuyOptions{Promo: "OFF"}))
+	assert.Equal(t, services.ErrPromoCodeNotFound, purchaseSrv.BuyGood(ctx, user.ID, "cup", models.BuyOptions{Promo: "NOPE"}))
+}
+
+func TestCreatePromoCode_Validation(t *testing.T) {
+	promoSrv, _, _ := getMockPromoServices(t)
+	ctx := context.Background()
+
+	_, err := promoSrv.CreatePromoCode(ctx, models.CreatePromoCodeRequest{DiscountType: database.DiscountFixed, DiscountValue: 5})
+	assert.Equal(t, services.ErrPromoCodeRequired, err)
+
+	_, err = promoSrv.CreatePromoCode(ctx, models.CreatePromoCodeRequest{Code: "X", DiscountType: "gift", DiscountValue: 5})
+	assert.Equal(t, services.ErrInvalidDiscountType, err)
+
+	_, err = promoSrv.CreatePromoCode(ctx, models.CreatePromoCodeRequest{Code: "X", DiscountType: database.DiscountPercent, DiscountValue: 150})
+	assert.Equal(t, services.ErrInvalidDiscountValue, err)
+
+	_, err = promoSrv.CreatePromoCode(ctx, models.CreatePromoCodeRequest{Code: "X", DiscountType: database.DiscountFixed, DiscountValue: 5, Item: "car"})
+	assert.Equal(t, services.ErrItemNotFound, err)
+
+	_, err = promoSrv.CreatePromoCode(ctx, models.CreatePromoCodeRequest{Code: "X", DiscountType: database.DiscountFixed, DiscountValue: 5})
+	assert.NoError(t, err)
+
+	_, err = promoSrv.CreatePromoCode(ctx, models.CreatePromoCodeRequest{Code: "x", DiscountType: database.DiscountFixed, DiscountValue: 5})
+	assert.Equal(t, services.ErrPromoCodeExists, err)
+}
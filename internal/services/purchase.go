@@ -3,13 +3,17 @@ package services
 import (
 	"context"
 	"errors"
+	"time"
 
+	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/internal/models"
 	"github.com/maksemen2/avito-shop/internal/repository"
 	"go.uber.org/zap"
 )
 
 type PurchaseService interface {
-	BuyGood(ctx context.Context, userID uint, goodName string) error
+	BuyGood(ctx context.Context, userID uint, goodName string, opts models.BuyOptions) error
+	GiftGood(ctx context.Context, buyerID uint, buyerUsername string, req models.GiftRequest) error
 }
 
 type purchaseServiceImpl struct {
@@ -21,28 +25,116 @@ func NewPurchaseService(repository repository.HolderRepository, logger *zap.Logg
 	return &purchaseServiceImpl{repository: repository, logger: logger}
 }
 
-func (s *purchaseServiceImpl) BuyGood(ctx context.Context, userID uint, itemType string) error {
+// BuyGood покупает товар itemType по цене, действующей на момент покупки.
+// opts.Variant выбирает вариант товара, а непустой opts.Promo применяет к цене скидку по промокоду.
+func (s *purchaseServiceImpl) BuyGood(ctx context.Context, userID uint, itemType string, opts models.BuyOptions) error {
 	if itemType == "" {
 		return ErrItemTypeRequired
 	}
 
-	good, err := s.repository.Good().GetByName(ctx, itemType)
+	now := time.Now()
 
+	good, variantID, price, err := s.resolveGood(ctx, itemType, opts.Variant, now)
 	if err != nil {
-		if errors.Is(err, repository.ErrGoodNotFound) {
-			return ErrItemNotFound
-		} else {
-			return ErrInternal
+		return err
+	}
+
+	if opts.Promo != "" {
+		promo, discount, err := resolvePromoCode(ctx, s.repository, opts.Promo, good.ID, price, now)
+		if err != nil {
+			return err
 		}
+
+		return purchaseError(s.repository.BuyItemWithPromo(ctx, userID, good.ID, variantID, price, promo, discount))
 	}
 
-	if err := s.repository.BuyItem(ctx, userID, good.ID, good.Price); err != nil {
-		if errors.Is(err, repository.ErrInsufficientFunds) {
-			return ErrInsufficientFunds
-		} else {
-			return ErrInternal
+	return purchaseError(s.repository.BuyItem(ctx, userID, good.ID, variantID, price))
+}
+
+// GiftGood покупает товар за счёт buyerID и кладёт его в инвентарь получателя.
+func (s *purchaseServiceImpl) GiftGood(ctx context.Context, buyerID uint, buyerUsername string, req models.GiftRequest) error {
+	if req.ToUser == "" {
+		return ErrToUserRequired
+	}
+
+	if req.Item == "" {
+		return ErrItemTypeRequired
+	}
+
+	if buyerUsername == req.ToUser {
+		return ErrCantSelfGift
+	}
+
+	recipientID, err := s.repository.User().GetIDByUsername(ctx, req.ToUser)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return ErrRecieverNotFound
 		}
+
+		return ErrInternal
 	}
 
-	return nil
+	good, variantID, price, err := s.resolveGood(ctx, req.Item, req.Variant, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return purchaseError(s.repository.GiftItem(ctx, buyerID, recipientID, good.ID, variantID, price))
+}
+
+// resolveGood находит товар itemType и его вариант variantName и возвращает цену, действующую в момент now.
+func (s *purchaseServiceImpl) resolveGood(
+	ctx context.Context,
+	itemType, variantName string,
+	now time.Time,
+) (*database.Good, *uint, int, error) {
+	good, err := s.repository.Good().GetByName(ctx, itemType)
+	if err != nil {
+		if errors.Is(err, repository.ErrGoodNotFound) {
+			return nil, nil, 0, ErrItemNotFound
+		}
+
+		return nil, nil, 0, ErrInternal
+	}
+
+	price, err := currentPrice(ctx, s.repository, good, now)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	variant, err := resolveVariant(ctx, s.repository, good, variantName)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	if variant == nil {
+		return good, nil, price, nil
+	}
+
+	// Остаток ещё раз проверяется при самой покупке, здесь лишь не даём начать заведомо неуспешную
+	if variant.Stock <= 0 {
+		return nil, nil, 0, ErrOutOfStock
+	}
+
+	return good, &variant.ID, max(price+variant.PriceDelta, 0), nil
+}
+
+// purchaseError переводит ошибку покупки из репозитория в ошибку сервиса.
+func purchaseError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, repository.ErrInsufficientFunds):
+		return ErrInsufficientFunds
+	case errors.Is(err, repository.ErrOutOfStock):
+		return ErrOutOfStock
+	case errors.Is(err, repository.ErrUserLocked):
+		return ErrUserLocked
+	case errors.Is(err, repository.ErrPromoCodeExhausted):
+		return ErrPromoCodeExhausted
+	case errors.Is(err, repository.ErrPromoCodeUserLimit):
+		return ErrPromoCodeUserLimit
+	default:
+		return ErrInternal
+	}
 }
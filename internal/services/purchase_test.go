@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/internal/models"
 	"github.com/maksemen2/avito-shop/internal/repository"
 	"github.com/maksemen2/avito-shop/internal/services"
 	"github.com/stretchr/testify/assert"
@@ -20,7 +21,7 @@ func getMockPurchaseService(t *testing.T) (services.PurchaseService, *gorm.DB) {
 		t.Fatalf("failed to open in-memory database: %v", err)
 	}
 
-	db.AutoMigrate(&database.User{}, &database.Purchase{}, &database.Transaction{}, &database.Good{})
+	db.AutoMigrate(&database.User{}, &database.Purchase{}, &database.Transaction{}, &database.Good{}, &database.CoinRequest{}, &database.GrantProgram{}, &database.Grant{}, &database.CoinLot{}, &database.CoinExpiration{}, &database.ItemTransfer{}, &database.Listing{}, &database.PromoCode{}, &database.PromoRedemption{}, &database.PriceSchedule{}, &database.GoodVariant{}, &database.WishlistItem{}, &database.Notification{}, &database.OutboxEvent{}, &database.Webhook{}, &database.WebhookDelivery{})
 
 	goods := map[string]int{
 		"t-shirt":    80,
@@ -58,7 +59,7 @@ func TestPurchaseItem_Success(t *testing.T) {
 
 	assert.NoError(t, db.Create(&user).Error, "failed to create user")
 
-	err := srv.BuyGood(context.Background(), user.ID, "t-shirt")
+	err := srv.BuyGood(context.Background(), user.ID, "t-shirt", models.BuyOptions{})
 
 	assert.NoError(t, err)
 
@@ -88,10 +89,10 @@ func TestPurchaseItem_NoCoins(t *testing.T) {
 	assert.NoError(t, db.Create(&user).Error, "failed to create user")
 
 	for i := 0; i < 12; i++ {
-		assert.NoError(t, srv.BuyGood(ctx, user.ID, "t-shirt"), "failed to buy tshirt")
+		assert.NoError(t, srv.BuyGood(ctx, user.ID, "t-shirt", models.BuyOptions{}), "failed to buy tshirt")
 	}
 
-	err := srv.BuyGood(context.Background(), user.ID, "t-shirt")
+	err := srv.BuyGood(context.Background(), user.ID, "t-shirt", models.BuyOptions{})
 
 	assert.Error(t, err)
 	assert.Equal(t, services.ErrInsufficientFunds, err)
@@ -108,8 +109,50 @@ func TestPurchaseItem_NoGood(t *testing.T) {
 
 	assert.NoError(t, db.Create(&user).Error, "failed to create user")
 
-	err := srv.BuyGood(context.Background(), user.ID, "non-existing-good")
+	err := srv.BuyGood(context.Background(), user.ID, "non-existing-good", models.BuyOptions{})
 
 	assert.Error(t, err)
 	assert.Equal(t, services.ErrItemNotFound, err)
 }
+
+func TestGiftItem_Success(t *testing.T) {
+	srv, db := getMockPurchaseService(t)
+	ctx := context.Background()
+
+	buyer := database.User{Username: "buyer", PasswordHash: "test"}
+	recipient := database.User{Username: "recipient", PasswordHash: "test"}
+
+	assert.NoError(t, db.Create(&buyer).Error, "failed to create buyer")
+	assert.NoError(t, db.Create(&recipient).Error, "failed to create recipient")
+
+	err := srv.GiftGood(ctx, buyer.ID, buyer.Username, models.GiftRequest{ToUser: recipient.Username, Item: "cup"})
+	assert.NoError(t, err)
+
+	assert.NoError(t, db.First(&buyer, buyer.ID).Error, "failed to fetch buyer")
+	assert.NoError(t, db.First(&recipient, recipient.ID).Error, "failed to fetch recipient")
+	assert.Equal(t, 980, buyer.Coins, "buyer's coins should be deducted")
+	assert.Equal(t, 1000, recipient.Coins, "recipient's coins should stay the same")
+
+	var purchase database.Purchase
+
+	assert.NoError(t, db.Where("user_id = ?", recipient.ID).First(&purchase).Error, "gift should be owned by recipient")
+	assert.NotNil(t, purchase.GiftedByID, "gift should reference buyer")
+	assert.Equal(t, buyer.ID, *purchase.GiftedByID, "unexpected gift buyer")
+}
+
+func TestGiftItem_Validation(t *testing.T) {
+	srv, db := getMockPurchaseService(t)
+	ctx := context.Background()
+
+	buyer := database.User{Username: "buyer", PasswordHash: "test"}
+	assert.NoError(t, db.Create(&buyer).Error, "failed to create buyer")
+
+	err := srv.GiftGood(ctx, buyer.ID, buyer.Username, models.GiftRequest{ToUser: buyer.Username, Item: "cup"})
+	assert.Equal(t, services.ErrCantSelfGift, err)
+
+	err = srv.GiftGood(ctx, buyer.ID, buyer.Username, models.GiftRequest{ToUser: "nobody", Item: "cup"})
+	assert.Equal(t, services.ErrRecieverNotFound, err)
+
+	err = srv.GiftGood(ctx, buyer.ID, buyer.Username, models.GiftRequest{ToUser: "nobody"})
+	assert.Equal(t, services.ErrItemTypeRequired, err)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 
+	"github.com/maksemen2/avito-shop/config"
 	"github.com/maksemen2/avito-shop/internal/models"
 	"github.com/maksemen2/avito-shop/internal/repository"
 	"go.uber.org/zap"
@@ -15,11 +16,16 @@ type TransferService interface {
 
 type transferServiceImpl struct {
 	repository repository.HolderRepository
+	policy     *TransferPolicy
 	logger     *zap.Logger
 }
 
-func NewTransferService(repository repository.HolderRepository, logger *zap.Logger) TransferService {
-	return &transferServiceImpl{repository: repository, logger: logger}
+func NewTransferService(repository repository.HolderRepository, transferConfig config.TransferConfig, logger *zap.Logger) TransferService {
+	return &transferServiceImpl{
+		repository: repository,
+		policy:     NewTransferPolicy(transferConfig, repository, logger),
+		logger:     logger,
+	}
 }
 
 func (s *transferServiceImpl) SendCoins(ctx context.Context, senderID uint, senderUsername string, req models.SendCoinRequest) error {
@@ -44,12 +50,21 @@ func (s *transferServiceImpl) SendCoins(ctx context.Context, senderID uint, send
 		return ErrInternal
 	}
 
-	err = s.repository.TransferCoins(ctx, senderID, receiverID, req.Amount)
+	if err := s.policy.Check(ctx, senderID, req.Amount); err != nil {
+		return err
+	}
+
+	err = s.repository.TransferCoins(ctx, senderID, receiverID, req.Amount, s.policy.DailyLimit())
 
 	if err != nil {
-		if errors.Is(err, repository.ErrInsufficientFunds) {
+		switch {
+		case errors.Is(err, repository.ErrInsufficientFunds):
 			return ErrInsufficientFunds
-		} else {
+		case errors.Is(err, repository.ErrDailyLimit):
+			return ErrDailyLimitExceeded
+		case errors.Is(err, repository.ErrUserLocked):
+			return ErrUserLocked
+		default:
 			return ErrInternal
 		}
 	}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/maksemen2/avito-shop/config"
+	"github.com/maksemen2/avito-shop/internal/repository"
+	"go.uber.org/zap"
+)
+
+// TransferPolicy проверяет настраиваемые ограничения на исходящие переводы:
+// максимальную сумму одного перевода и минимальный возраст аккаунта отправителя.
+// Суточный лимит проверяет сам репозиторий в транзакции перевода, политика только хранит его значение.
+type TransferPolicy struct {
+	config     config.TransferConfig
+	repository repository.HolderRepository
+	logger     *zap.Logger
+}
+
+func NewTransferPolicy(config config.TransferConfig, repository repository.HolderRepository, logger *zap.Logger) *TransferPolicy {
+	return &TransferPolicy{config: config, repository: repository, logger: logger}
+}
+
+// Check проверяет, может ли пользователь senderID отправить amount монет.
+// Возвращает ErrTransferAmountTooLarge, ErrAccountTooNew или ErrInternal.
+func (p *TransferPolicy) Check(ctx context.Context, senderID uint, amount int) error {
+	if p.config.MaxAmount > 0 && amount > p.config.MaxAmount {
+		return ErrTransferAmountTooLarge
+	}
+
+	if p.config.MinAccountAgeHours > 0 {
+		sender, err := p.repository.User().GetByID(ctx, senderID)
+		if err != nil {
+			p.logger.Error("failed to get sender for transfer policy", zap.Uint("userID", senderID), zap.Error(err))
+			return ErrInternal
+		}
+
+		if time.Since(sender.CreatedAt) < time.Duration(p.config.MinAccountAgeHours)*time.Hour {
+			return ErrAccountTooNew
+		}
+	}
+
+	return nil
+}
+
+// DailyLimit возвращает суточный лимит исходящих переводов или 0, если лимита нет.
+func (p *TransferPolicy) DailyLimit() int {
+	return p.config.DailyLimit
+}
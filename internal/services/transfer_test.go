@@ -3,7 +3,9 @@ package services_test
 import (
 	"context"
 	"testing"
+	"time"
 
+	"github.com/maksemen2/avito-shop/config"
 	"github.com/maksemen2/avito-shop/internal/database"
 	"github.com/maksemen2/avito-shop/internal/models"
 	"github.com/maksemen2/avito-shop/internal/repository"
@@ -16,17 +18,21 @@ import (
 )
 
 func getMockTransferService(t *testing.T) (services.TransferService, *gorm.DB) {
+	return getMockTransferServiceWithConfig(t, config.TransferConfig{})
+}
+
+func getMockTransferServiceWithConfig(t *testing.T, transferConfig config.TransferConfig) (services.TransferService, *gorm.DB) {
 	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormLogger.Default.LogMode(gormLogger.Silent)})
 	if err != nil {
 		t.Fatalf("failed to open in-memory database: %v", err)
 	}
 
-	db.AutoMigrate(&database.User{}, &database.Purchase{}, &database.Transaction{}, &database.Good{})
+	db.AutoMigrate(&database.User{}, &database.Purchase{}, &database.Transaction{}, &database.Good{}, &database.CoinRequest{}, &database.GrantProgram{}, &database.Grant{}, &database.CoinLot{}, &database.CoinExpiration{}, &database.ItemTransfer{}, &database.Listing{}, &database.PromoCode{}, &database.PromoRedemption{}, &database.PriceSchedule{}, &database.GoodVariant{}, &database.WishlistItem{}, &database.Notification{}, &database.OutboxEvent{}, &database.Webhook{}, &database.WebhookDelivery{})
 
 	logger := zap.NewNop()
 	holderRepo := repository.NewHolderRepository(db, logger)
 
-	return services.NewTransferService(holderRepo, logger), db
+	return services.NewTransferService(holderRepo, transferConfig, logger), db
 }
 
 func TestTransferCoins_Success(t *testing.T) {
@@ -152,3 +158,74 @@ func TestTransferCoins_NegativeAmount(t *testing.T) {
 
 	assert.ErrorIs(t, err, services.ErrAmountBelowZero, "unexpected error")
 }
+
+func TestTransferCoins_MaxAmount(t *testing.T) {
+	srv, db := getMockTransferServiceWithConfig(t, config.TransferConfig{MaxAmount: 50})
+
+	sender := database.User{Username: "test1", PasswordHash: "test1"}
+	receiver := database.User{Username: "test2", PasswordHash: "test2"}
+
+	assert.NoError(t, db.Create(&sender).Error, "failed to create user1")
+	assert.NoError(t, db.Create(&receiver).Error, "failed to create user2")
+
+	err := srv.SendCoins(context.Background(), sender.ID, sender.Username, models.SendCoinRequest{ToUser: receiver.Username, Amount: 51})
+	assert.ErrorIs(t, err, services.ErrTransferAmountTooLarge, "unexpected error")
+
+	err = srv.SendCoins(context.Background(), sender.ID, sender.Username, models.SendCoinRequest{ToUser: receiver.Username, Amount: 50})
+	assert.NoError(t, err, "transfer within limit should succeed")
+}
+
+func TestTransferCoins_DailyLimit(t *testing.T) {
+	srv, db := getMockTransferServiceWithConfig(t, config.TransferConfig{DailyLimit: 150})
+
+	sender := database.User{Username: "test1", PasswordHash: "test1"}
+	receiver := database.User{Username: "test2", PasswordHash: "test2"}
+
+	assert.NoError(t, db.Create(&sender).Error, "failed to create user1")
+	assert.NoError(t, db.Create(&receiver).Error, "failed to create user2")
+
+	// Переводы старше суток в лимит не входят
+	assert.NoError(t, db.Create(&database.Transaction{
+		FromUserID: sender.ID,
+		ToUserID:   receiver.ID,
+		Amount:     150,
+		CreatedAt:  time.Now().Add(-25 * time.Hour),
+	}).Error, "failed to create old transaction")
+
+	// Оплаты объявлений и сторнирующие переводы в лимит тоже не входят
+	for _, kind := range []string{database.TransactionKindListing, database.TransactionKindReversal} {
+		assert.NoError(t, db.Create(&database.Transaction{
+			FromUserID: sender.ID,
+			ToUserID:   receiver.ID,
+			Amount:     150,
+			Kind:       kind,
+		}).Error, "failed to create %s transaction", kind)
+	}
+
+	req := models.SendCoinRequest{ToUser: receiver.Username, Amount: 100}
+
+	assert.NoError(t, srv.SendCoins(context.Background(), sender.ID, sender.Username, req), "first transfer should succeed")
+
+	err := srv.SendCoins(context.Background(), sender.ID, sender.Username, req)
+	assert.ErrorIs(t, err, services.ErrDailyLimitExceeded, "unexpected error")
+
+	var coins int
+	assert.NoError(t, db.Model(&database.User{}).Where("id = ?", sender.ID).Pluck("coins", &coins).Error)
+	assert.Equal(t, 900, coins, "rejected transfer should be rolled back")
+}
+
+func TestTransferCoins_AccountTooNew(t *testing.T) {
+	srv, db := getMockTransferServiceWithConfig(t, config.TransferConfig{MinAccountAgeHours: 24})
+
+	sender := database.User{Username: "test1", PasswordHash: "test1"}
+	veteran := database.User{Username: "test2", PasswordHash: "test2", CreatedAt: time.Now().Add(-48 * time.Hour)}
+
+	assert.NoError(t, db.Create(&sender).Error, "failed to create user1")
+	assert.NoError(t, db.Create(&veteran).Error, "failed to create user2")
+
+	err := srv.SendCoins(context.Background(), sender.ID, sender.Username, models.SendCoinRequest{ToUser: veteran.Username, Amount: 10})
+	assert.ErrorIs(t, err, services.ErrAccountTooNew, "unexpected error")
+
+	err = srv.SendCoins(context.Background(), veteran.ID, veteran.Username, models.SendCoinRequest{ToUser: sender.Username, Amount: 10})
+	assert.NoError(t, err, "old enough account should be able to send coins")
+}
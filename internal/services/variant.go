@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/internal/models"
+	"github.com/maksemen2/avito-shop/internal/repository"
+	"go.uber.org/zap"
+)
+
+type VariantService interface {
+	CreateVariant(ctx context.Context, item string, req models.CreateVariantRequest) (models.Variant, error)
+	ListVariants(ctx context.Context, item string) ([]models.Variant, error)
+	Restock(ctx context.Context, variantID uint, quantity int) (models.Variant, error)
+}
+
+type variantServiceImpl struct {
+	repository repository.HolderRepository
+	logger     *zap.Logger
+}
+
+func NewVariantService(repository repository.HolderRepository, logger *zap.Logger) VariantService {
+	return &variantServiceImpl{repository: repository, logger: logger}
+}
+
+func (s *variantServiceImpl) CreateVariant(ctx context.Context, item string, req models.CreateVariantRequest) (models.Variant, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return models.Variant{}, ErrVariantNameRequired
+	}
+
+	if req.Stock < 0 {
+		return models.Variant{}, ErrInvalidStock
+	}
+
+	good, err := s.getGood(ctx, item)
+	if err != nil {
+		return models.Variant{}, err
+	}
+
+	variant := &database.GoodVariant{
+		GoodID:     good.ID,
+		Name:       name,
+		Stock:      req.Stock,
+		PriceDelta: req.PriceDelta,
+	}
+
+	if err := s.repository.Variant().Create(ctx, variant); err != nil {
+		if errors.Is(err, repository.ErrVariantExists) {
+			return models.Variant{}, ErrVariantExists
+		}
+
+		return models.Variant{}, ErrInternal
+	}
+
+	return toVariantModel(good.Type, *variant), nil
+}
+
+func (s *variantServiceImpl) ListVariants(ctx context.Context, item string) ([]models.Variant, error) {
+	good, err := s.getGood(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+
+	variants, err := s.repository.Variant().GetByGoodID(ctx, good.ID)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	result := make([]models.Variant, 0, len(variants))
+	for _, variant := range variants {
+		result = append(result, toVariantModel(good.Type, variant))
+	}
+
+	return result, nil
+}
+
+// Restock пополняет остаток варианта на quantity единиц.
+// Если вариант был распродан, пользователи с этим товаром в списке желаний получают уведомление.
+func (s *variantServiceImpl) Restock(ctx context.Context, variantID uint, quantity int) (models.Variant, error) {
+	if quantity <= 0 {
+		return models.Variant{}, ErrQuantityBelowZero
+	}
+
+	variant, err := s.repository.Variant().Restock(ctx, variantID, quantity)
+	if err != nil {
+		if errors.Is(err, repository.ErrVariantNotFound) {
+			return models.Variant{}, ErrVariantNotFound
+		}
+
+		return models.Variant{}, ErrInternal
+	}
+
+	good, err := s.repository.Good().GetByID(ctx, variant.GoodID)
+	if err != nil {
+		return models.Variant{}, ErrInternal
+	}
+
+	// Вариант был распродан до пополнения - сообщаем тем, кто его ждал.
+	// Остаток уже пополнен, поэтому ошибка уведомления не должна превращаться в ошибку запроса
+	if variant.Stock == quantity {
+		message := fmt.Sprintf("%s (%s) is back in stock", good.Type, variant.Name)
+		if _, err := s.repository.Notification().CreateForWishlisters(ctx, good.ID, database.NotificationRestock, message); err != nil {
+			s.logger.Warn("failed to notify about restock", zap.Uint("variantID", variant.ID), zap.Error(err))
+		}
+	}
+
+	return toVariantModel(good.Type, *variant), nil
+}
+
+func (s *variantServiceImpl) getGood(ctx context.Context, item string) (*database.Good, error) {
+	good, err := s.repository.Good().GetByName(ctx, item)
+	if err != nil {
+		if errors.Is(err, repository.ErrGoodNotFound) {
+			return nil, ErrItemNotFound
+		}
+
+		return nil, ErrInternal
+	}
+
+	return good, nil
+}
+
+func toVariantModel(item string, variant database.GoodVariant) models.Variant {
+	return models.Variant{
+		ID:         variant.ID,
+		Item:       item,
+		Name:       variant.Name,
+		Stock:      variant.Stock,
+		PriceDelta: variant.PriceDelta,
+	}
+}
+
+// resolveVariant находит вариант variantName товара good или возвращает nil для товара без вариантов.
+// Для товаров с вариантами вариант обязателен, для остальных variantName должен быть пустым.
+func resolveVariant(ctx context.Context, repo repository.HolderRepository, good *database.Good, variantName string) (*database.GoodVariant, error) {
+	if variantName == "" {
+		variants, err := repo.Variant().GetByGoodID(ctx, good.ID)
+		if err != nil {
+			return nil, ErrInternal
+		}
+
+		if len(variants) > 0 {
+			return nil, ErrVariantRequired
+		}
+
+		return nil, nil
+	}
+
+	variant, err := repo.Variant().GetByName(ctx, good.ID, variantName)
+	if err != nil {
+		if errors.Is(err, repository.ErrVariantNotFound) {
+			return nil, ErrVariantNotFound
+		}
+
+		return nil, ErrInternal
+	}
+
+	return variant, nil
+}
+
+// variantID возвращает идентификатор варианта или nil, если варианта нет.
+func variantID(variant *database.GoodVariant) *uint {
+	if variant == nil {
+		return nil
+	}
+
+	return &variant.ID
+}
@@ -0,0 +1,65 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/internal/models"
+	"github.com/maksemen2/avito-shop/internal/repository"
+	"github.com/maksemen2/avito-shop/internal/services"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestBuyGood_Variants(t *testing.T) {
+	purchaseSrv, db := getMockPurchaseService(t)
+	ctx := context.Background()
+
+	logger := zap.NewNop()
+	holderRepo := repository.NewHolderRepository(db, logger)
+	variantSrv := services.NewVariantService(holderRepo, logger)
+
+	_, err := variantSrv.CreateVariant(ctx, "t-shirt", models.CreateVariantRequest{Name: "S", Stock: 1})
+	assert.NoError(t, err, "failed to create variant")
+
+	large, err := variantSrv.CreateVariant(ctx, "t-shirt", models.CreateVariantRequest{Name: "L", PriceDelta: 10})
+	assert.NoError(t, err, "failed to create variant")
+
+	_, err = variantSrv.CreateVariant(ctx, "t-shirt", models.CreateVariantRequest{Name: "S"})
+	assert.Equal(t, services.ErrVariantExists, err)
+
+	user := database.User{Username: "buyer", PasswordHash: "test"}
+	assert.NoError(t, db.Create(&user).Error, "failed to create user")
+
+	assert.Equal(t, services.ErrVariantRequired, purchaseSrv.BuyGood(ctx, user.ID, "t-shirt", models.BuyOptions{}))
+	assert.Equal(t, services.ErrVariantNotFound, purchaseSrv.BuyGood(ctx, user.ID, "cup", models.BuyOptions{Variant: "S"}))
+	assert.Equal(t, services.ErrOutOfStock, purchaseSrv.BuyGood(ctx, user.ID, "t-shirt", models.BuyOptions{Variant: "L"}))
+
+	assert.NoError(t, purchaseSrv.BuyGood(ctx, user.ID, "t-shirt", models.BuyOptions{Variant: "S"}))
+	assert.Equal(t, services.ErrOutOfStock, purchaseSrv.BuyGood(ctx, user.ID, "t-shirt", models.BuyOptions{Variant: "S"}))
+
+	restocked, err := variantSrv.Restock(ctx, large.ID, 2)
+	assert.NoError(t, err, "failed to restock variant")
+	assert.Equal(t, 2, restocked.Stock)
+
+	assert.NoError(t, purchaseSrv.BuyGood(ctx, user.ID, "t-shirt", models.BuyOptions{Variant: "L"}))
+	assert.NoError(t, purchaseSrv.BuyGood(ctx, user.ID, "cup", models.BuyOptions{}))
+
+	assert.NoError(t, db.First(&user, user.ID).Error, "failed to fetch user")
+	assert.Equal(t, 1000-80-90-20, user.Coins, "expected variant price delta to be charged")
+
+	inventory, err := holderRepo.Purchase().GetInventoryByUserID(ctx, user.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Item{
+		{Type: "cup", Quantity: 1},
+		{Type: "t-shirt", Variant: "L", Quantity: 1},
+		{Type: "t-shirt", Variant: "S", Quantity: 1},
+	}, inventory, "expected variant-level inventory")
+
+	variants, err := variantSrv.ListVariants(ctx, "t-shirt")
+	assert.NoError(t, err)
+	assert.Len(t, variants, 2)
+	assert.Equal(t, 0, variants[0].Stock, "sold out variant should have no stock")
+	assert.Equal(t, 1, variants[1].Stock, "bought variant stock should be decremented")
+}
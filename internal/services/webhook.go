@@ -0,0 +1,300 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/maksemen2/avito-shop/config"
+	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/internal/models"
+	"github.com/maksemen2/avito-shop/internal/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	// Заголовки запроса вебхуку
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+
+	// Сколько доставок отправляется за один запуск фоновой задачи
+	webhookBatchSize = 100
+	// Максимальная длина сохраняемой ошибки доставки, совпадает с размером колонки last_error
+	maxDeliveryErrorLength = 512
+	// Максимальный множитель задержки между попытками, чтобы сдвиг не переполнился
+	maxBackoffShift = 16
+	// Срок резервирования доставки, если он не задан в конфигурации
+	defaultWebhookLease = time.Minute
+)
+
+type WebhookService interface {
+	CreateWebhook(ctx context.Context, req models.CreateWebhookRequest) (models.Webhook, error)
+	ListWebhooks(ctx context.Context) ([]models.Webhook, error)
+	SetWebhookActive(ctx context.Context, webhookID uint, active bool) error
+	ListDeliveries(ctx context.Context, status string) ([]models.WebhookDelivery, error)
+	RetryDelivery(ctx context.Context, deliveryID uint) error
+	DeliverPending(ctx context.Context, now time.Time) (int, error)
+}
+
+type webhookServiceImpl struct {
+	repository repository.HolderRepository
+	cfg        config.WebhooksConfig
+	client     *http.Client
+	logger     *zap.Logger
+}
+
+func NewWebhookService(repository repository.HolderRepository, cfg config.WebhooksConfig, logger *zap.Logger) WebhookService {
+	return &webhookServiceImpl{
+		repository: repository,
+		cfg:        cfg,
+		client:     &http.Client{Timeout: time.Duration(cfg.TimeoutSeconds) * time.Second},
+		logger:     logger,
+	}
+}
+
+// CreateWebhook регистрирует вебхук. Если секрет не указан, генерируется случайный.
+// Секрет возвращается только в ответе на создание.
+func (s *webhookServiceImpl) CreateWebhook(ctx context.Context, req models.CreateWebhookRequest) (models.Webhook, error) {
+	target, err := url.Parse(req.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return models.Webhook{}, ErrInvalidWebhookURL
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			s.logger.Error("failed to generate webhook secret", zap.Error(err))
+			return models.Webhook{}, ErrInternal
+		}
+
+		secret = hex.EncodeToString(buf)
+	}
+
+	webhook := &database.Webhook{URL: req.URL, Secret: secret, Active: true}
+	if err := s.repository.Webhook().Create(ctx, webhook); err != nil {
+		return models.Webhook{}, ErrInternal
+	}
+
+	resp := webhookToModel(*webhook)
+	resp.Secret = secret
+
+	return resp, nil
+}
+
+func (s *webhookServiceImpl) ListWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	webhooks, err := s.repository.Webhook().GetAll(ctx)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	resp := make([]models.Webhook, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		resp = append(resp, webhookToModel(webhook))
+	}
+
+	return resp, nil
+}
+
+func (s *webhookServiceImpl) SetWebhookActive(ctx context.Context, webhookID uint, active bool) error {
+	if err := s.repository.Webhook().SetActive(ctx, webhookID, active); err != nil {
+		if errors.Is(err, repository.ErrWebhookNotFound) {
+			return ErrWebhookNotFound
+		}
+
+		return ErrInternal
+	}
+
+	return nil
+}
+
+func (s *webhookServiceImpl) ListDeliveries(ctx context.Context, status string) ([]models.WebhookDelivery, error) {
+	switch status {
+	case "", database.DeliveryPending, database.DeliveryDelivered, database.DeliveryDead:
+	default:
+		return nil, ErrInvalidDeliveryStatus
+	}
+
+	deliveries, err := s.repository.Webhook().GetDeliveries(ctx, status)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	resp := make([]models.WebhookDelivery, 0, len(deliveries))
+
+	for _, delivery := range deliveries {
+		item := models.WebhookDelivery{
+			ID:            delivery.ID,
+			WebhookID:     delivery.WebhookID,
+			EventID:       delivery.EventID,
+			Status:        delivery.Status,
+			Attempts:      delivery.Attempts,
+			NextAttemptAt: delivery.NextAttemptAt,
+			LastError:     delivery.LastError,
+			DeliveredAt:   delivery.DeliveredAt,
+		}
+
+		if delivery.Event != nil {
+			item.EventType = delivery.Event.Type
+		}
+
+		resp = append(resp, item)
+	}
+
+	return resp, nil
+}
+
+// RetryDelivery возвращает доставку из dead-letter в очередь.
+func (s *webhookServiceImpl) RetryDelivery(ctx context.Context, deliveryID uint) error {
+	if err := s.repository.Webhook().Retry(ctx, deliveryID, time.Now()); err != nil {
+		if errors.Is(err, repository.ErrWebhookDeliveryNotFound) {
+			return ErrWebhookDeliveryNotFound
+		}
+
+		return ErrInternal
+	}
+
+	return nil
+}
+
+// DeliverPending отправляет доставки, время попытки которых наступило к моменту now, и возвращает количество успешных.
+// Неудачная доставка повторяется с удваивающейся задержкой, а после cfg.MaxAttempts попыток переходит в dead-letter.
+func (s *webhookServiceImpl) DeliverPending(ctx context.Context, now time.Time) (int, error) {
+	deliveries, err := s.repository.Webhook().GetDueDeliveries(ctx, now, webhookBatchSize)
+	if err != nil {
+		return 0, ErrInternal
+	}
+
+	delivered := 0
+
+	for i := range deliveries {
+		delivery := &deliveries[i]
+
+		// Пока идёт запрос, доставка зарезервирована: другой экземпляр сервиса возьмёт её только после окончания аренды
+		claimed, err := s.repository.Webhook().Claim(ctx, delivery, now, now.Add(s.lease()))
+		if err != nil {
+			return delivered, ErrInternal
+		}
+
+		if !claimed {
+			continue
+		}
+
+		sendErr := s.send(ctx, delivery)
+		if sendErr == nil {
+			if err := s.repository.Webhook().MarkDelivered(ctx, delivery.ID, time.Now()); err != nil {
+				return delivered, ErrInternal
+			}
+
+			delivered++
+
+			continue
+		}
+
+		attempts := delivery.Attempts + 1
+		status := database.DeliveryPending
+
+		if attempts >= s.cfg.MaxAttempts {
+			status = database.DeliveryDead
+
+			s.logger.Warn("webhook delivery moved to dead-letter",
+				zap.Uint("deliveryID", delivery.ID), zap.Uint("webhookID", delivery.WebhookID), zap.Error(sendErr))
+		}
+
+		message := sendErr.Error()
+		if len(message) > maxDeliveryErrorLength {
+			message = message[:maxDeliveryErrorLength]
+		}
+
+		if err := s.repository.Webhook().MarkFailed(ctx, delivery.ID, attempts, status, now.Add(s.backoff(attempts)), message); err != nil {
+			return delivered, ErrInternal
+		}
+	}
+
+	return delivered, nil
+}
+
+// lease возвращает срок резервирования доставки: не меньше cfg.LeaseSeconds и не меньше двух таймаутов запроса,
+// чтобы аренда не истекла, пока запрос ещё может выполняться.
+func (s *webhookServiceImpl) lease() time.Duration {
+	minLease := time.Duration(s.cfg.LeaseSeconds) * time.Second
+	if minLease <= 0 {
+		minLease = defaultWebhookLease
+	}
+
+	return max(minLease, 2*s.client.Timeout)
+}
+
+// send отправляет событие доставки вебхуку. Успешной считается доставка с кодом ответа 2xx.
+func (s *webhookServiceImpl) send(ctx context.Context, delivery *database.WebhookDelivery) error {
+	body, err := json.Marshal(models.WebhookPayload{
+		ID:        delivery.Event.ID,
+		Type:      delivery.Event.Type,
+		CreatedAt: delivery.Event.CreatedAt,
+		Data:      json.RawMessage(delivery.Event.Payload),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, delivery.Event.Type)
+	req.Header.Set(WebhookDeliveryHeader, fmt.Sprint(delivery.ID))
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(delivery.Webhook.Secret, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Дочитываем тело, чтобы соединение вернулось в пул
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// backoff возвращает задержку перед попыткой, следующей за attempts неудачными.
+func (s *webhookServiceImpl) backoff(attempts int) time.Duration {
+	shift := min(attempts-1, maxBackoffShift)
+
+	return time.Duration(s.cfg.BackoffSeconds) * time.Second << shift
+}
+
+// SignWebhookPayload возвращает подпись тела запроса вебхуку в формате заголовка X-Webhook-Signature.
+// Получатель проверяет её, вычисляя HMAC-SHA256 тела со своим секретом.
+func SignWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func webhookToModel(webhook database.Webhook) models.Webhook {
+	return models.Webhook{
+		ID:        webhook.ID,
+		URL:       webhook.URL,
+		Active:    webhook.Active,
+		CreatedAt: webhook.CreatedAt,
+	}
+}
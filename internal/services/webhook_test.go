@@ -0,0 +1,187 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maksemen2/avito-shop/config"
+	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/internal/models"
+	"github.com/maksemen2/avito-shop/internal/repository"
+	"github.com/maksemen2/avito-shop/internal/services"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// webhookReceiver - локальный получатель вебхуков, отвечающий кодами из statuses по очереди, а затем 200
+type webhookReceiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+
+	w.WriteHeader(status)
+}
+
+func getMockWebhookService(t *testing.T, cfg config.WebhooksConfig) (services.WebhookService, services.TransferService, *gorm.DB) {
+	transferSrv, db := getMockTransferService(t)
+
+	logger := zap.NewNop()
+
+	return services.NewWebhookService(repository.NewHolderRepository(db, logger), cfg, logger), transferSrv, db
+}
+
+func createTransferUsers(t *testing.T, db *gorm.DB) (database.User, database.User) {
+	sender := database.User{Username: "sender", PasswordHash: "test", Coins: 1000}
+	assert.NoError(t, db.Create(&sender).Error, "failed to create sender")
+
+	receiver := database.User{Username: "receiver", PasswordHash: "test", Coins: 1000}
+	assert.NoError(t, db.Create(&receiver).Error, "failed to create receiver")
+
+	return sender, receiver
+}
+
+func TestWebhooks_DeliveryWithRetry(t *testing.T) {
+	webhookSrv, transferSrv, db := getMockWebhookService(t, config.WebhooksConfig{MaxAttempts: 3, BackoffSeconds: 10, TimeoutSeconds: 2})
+	ctx := context.Background()
+
+	receiver := &webhookReceiver{statuses: []int{http.StatusInternalServerError}}
+	server := httptest.NewServer(receiver)
+
+	defer server.Close()
+
+	webhook, err := webhookSrv.CreateWebhook(ctx, models.CreateWebhookRequest{URL: server.URL + "/hook"})
+	assert.NoError(t, err, "failed to create webhook")
+	assert.NotEmpty(t, webhook.Secret, "expected generated secret")
+
+	_, err = webhookSrv.CreateWebhook(ctx, models.CreateWebhookRequest{URL: "ftp://example.com"})
+	assert.Equal(t, services.ErrInvalidWebhookURL, err)
+
+	sender, _ := createTransferUsers(t, db)
+
+	assert.NoError(t, transferSrv.SendCoins(ctx, sender.ID, sender.Username, models.SendCoinRequest{ToUser: "receiver", Amount: 100}))
+
+	// Неудачный перевод откатывается вместе с событием
+	assert.Equal(t, services.ErrInsufficientFunds, transferSrv.SendCoins(ctx, sender.ID, sender.Username, models.SendCoinRequest{ToUser: "receiver", Amount: 100000}))
+
+	var events int64
+
+	assert.NoError(t, db.Model(&database.OutboxEvent{}).Count(&events).Error)
+	assert.Equal(t, int64(1), events, "expected only the committed transfer to produce an event")
+
+	now := time.Now()
+
+	delivered, err := webhookSrv.DeliverPending(ctx, now)
+	assert.NoError(t, err)
+	assert.Zero(t, delivered, "expected first attempt to fail")
+
+	// До истечения задержки повторной попытки не будет
+	delivered, err = webhookSrv.DeliverPending(ctx, now.Add(5*time.Second))
+	assert.NoError(t, err)
+	assert.Zero(t, delivered)
+	assert.Len(t, receiver.requests, 1, "expected no retry before backoff")
+
+	delivered, err = webhookSrv.DeliverPending(ctx, now.Add(11*time.Second))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, delivered, "expected retry to succeed")
+	assert.Len(t, receiver.requests, 2)
+
+	req, body := receiver.requests[1], receiver.bodies[1]
+	assert.Equal(t, database.EventTransferCreated, req.Header.Get(services.WebhookEventHeader))
+	assert.Equal(t, services.SignWebhookPayload(webhook.Secret, body), req.Header.Get(services.WebhookSignatureHeader), "expected valid signature")
+
+	var payload models.WebhookPayload
+
+	assert.NoError(t, json.Unmarshal(body, &payload))
+	assert.Equal(t, database.EventTransferCreated, payload.Type)
+	assert.JSONEq(t, `{"transactionId": 1, "fromUser": "sender", "toUser": "receiver", "amount": 100}`, string(payload.Data))
+
+	deliveries, err := webhookSrv.ListDeliveries(ctx, database.DeliveryDelivered)
+	assert.NoError(t, err)
+	assert.Len(t, deliveries, 1)
+	assert.Equal(t, 2, deliveries[0].Attempts)
+
+	// Выключенный вебхук не получает новых событий
+	assert.NoError(t, webhookSrv.SetWebhookActive(ctx, webhook.ID, false))
+	assert.NoError(t, transferSrv.SendCoins(ctx, sender.ID, sender.Username, models.SendCoinRequest{ToUser: "receiver", Amount: 1}))
+
+	delivered, err = webhookSrv.DeliverPending(ctx, now.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Zero(t, delivered)
+}
+
+func TestWebhooks_DeadLetter(t *testing.T) {
+	webhookSrv, _, db := getMockWebhookService(t, config.WebhooksConfig{MaxAttempts: 2, BackoffSeconds: 10, TimeoutSeconds: 2})
+	ctx := context.Background()
+
+	receiver := &webhookReceiver{statuses: []int{http.StatusBadGateway, http.StatusBadGateway}}
+	server := httptest.NewServer(receiver)
+
+	defer server.Close()
+
+	_, err := webhookSrv.CreateWebhook(ctx, models.CreateWebhookRequest{URL: server.URL, Secret: "secret"})
+	assert.NoError(t, err, "failed to create webhook")
+
+	buyer, _ := createTransferUsers(t, db)
+
+	logger := zap.NewNop()
+	purchaseSrv := services.NewPurchaseService(repository.NewHolderRepository(db, logger), logger)
+
+	assert.NoError(t, db.Create(&database.Good{Type: "cup", Price: 20}).Error, "failed to create good")
+	assert.NoError(t, purchaseSrv.GiftGood(ctx, buyer.ID, buyer.Username, models.GiftRequest{ToUser: "receiver", Item: "cup"}))
+
+	now := time.Now()
+
+	for _, at := range []time.Time{now, now.Add(time.Hour)} {
+		delivered, err := webhookSrv.DeliverPending(ctx, at)
+		assert.NoError(t, err)
+		assert.Zero(t, delivered)
+	}
+
+	var payload models.WebhookPayload
+
+	assert.NoError(t, json.Unmarshal(receiver.bodies[0], &payload))
+	assert.JSONEq(t, `{"purchaseId": 1, "user": "receiver", "item": "cup", "price": 20, "giftedBy": "sender"}`, string(payload.Data))
+
+	dead, err := webhookSrv.ListDeliveries(ctx, database.DeliveryDead)
+	assert.NoError(t, err)
+	assert.Len(t, dead, 1, "expected delivery to be dead after max attempts")
+	assert.Equal(t, "unexpected response status 502", dead[0].LastError)
+
+	// Dead-letter больше не отправляется, пока администратор не вернёт его в очередь
+	delivered, err := webhookSrv.DeliverPending(ctx, now.Add(24*time.Hour))
+	assert.NoError(t, err)
+	assert.Zero(t, delivered)
+
+	assert.NoError(t, webhookSrv.RetryDelivery(ctx, dead[0].ID))
+	assert.Equal(t, services.ErrWebhookDeliveryNotFound, webhookSrv.RetryDelivery(ctx, dead[0].ID))
+
+	delivered, err = webhookSrv.DeliverPending(ctx, time.Now().Add(time.Second))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, delivered, "expected retried delivery to succeed")
+
+	_, err = webhookSrv.ListDeliveries(ctx, "unknown")
+	assert.Equal(t, services.ErrInvalidDeliveryStatus, err)
+}
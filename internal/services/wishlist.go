@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/internal/models"
+	"github.com/maksemen2/avito-shop/internal/repository"
+	"go.uber.org/zap"
+)
+
+type WishlistService interface {
+	Add(ctx context.Context, userID uint, req models.AddToWishlistRequest) error
+	Remove(ctx context.Context, userID uint, item string) error
+	List(ctx context.Context, userID uint, now time.Time) ([]models.WishlistItem, error)
+}
+
+type wishlistServiceImpl struct {
+	repository repository.HolderRepository
+	logger     *zap.Logger
+}
+
+func NewWishlistService(repository repository.HolderRepository, logger *zap.Logger) WishlistService {
+	return &wishlistServiceImpl{repository: repository, logger: logger}
+}
+
+func (s *wishlistServiceImpl) Add(ctx context.Context, userID uint, req models.AddToWishlistRequest) error {
+	good, err := s.getGood(ctx, req.Item)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repository.Wishlist().Add(ctx, userID, good.ID); err != nil {
+		if errors.Is(err, repository.ErrAlreadyInWishlist) {
+			return ErrAlreadyInWishlist
+		}
+
+		return ErrInternal
+	}
+
+	return nil
+}
+
+func (s *wishlistServiceImpl) Remove(ctx context.Context, userID uint, item string) error {
+	good, err := s.getGood(ctx, item)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repository.Wishlist().Remove(ctx, userID, good.ID); err != nil {
+		if errors.Is(err, repository.ErrNotInWishlist) {
+			return ErrNotInWishlist
+		}
+
+		return ErrInternal
+	}
+
+	return nil
+}
+
+// List возвращает список желаний пользователя с ценами, действующими в момент now,
+// наличием товара и тем, хватает ли пользователю монет на покупку.
+func (s *wishlistServiceImpl) List(ctx context.Context, userID uint, now time.Time) ([]models.WishlistItem, error) {
+	items, err := s.repository.Wishlist().GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	balance, err := s.repository.User().GetBalance(ctx, userID)
+	if err != nil {
+		s.logger.Error("user not exists but token is valid", zap.Error(err), zap.Uint("userID", userID))
+		return nil, ErrInternal
+	}
+
+	result := make([]models.WishlistItem, 0, len(items))
+
+	for _, item := range items {
+		price, err := currentPrice(ctx, s.repository, item.Good, now)
+		if err != nil {
+			return nil, err
+		}
+
+		variants, err := s.repository.Variant().GetByGoodID(ctx, item.GoodID)
+		if err != nil {
+			return nil, ErrInternal
+		}
+
+		result = append(result, models.WishlistItem{
+			Item:      item.Good.Type,
+			Price:     price,
+			InStock:   inStock(variants),
+			CanAfford: balance >= price,
+		})
+	}
+
+	return result, nil
+}
+
+func (s *wishlistServiceImpl) getGood(ctx context.Context, item string) (*database.Good, error) {
+	if item == "" {
+		return nil, ErrItemTypeRequired
+	}
+
+	good, err := s.repository.Good().GetByName(ctx, item)
+	if err != nil {
+		if errors.Is(err, repository.ErrGoodNotFound) {
+			return nil, ErrItemNotFound
+		}
+
+		return nil, ErrInternal
+	}
+
+	return good, nil
+}
+
+// inStock сообщает, можно ли купить товар с вариантами variants. Товары без вариантов есть всегда.
+func inStock(variants []database.GoodVariant) bool {
+	if len(variants) == 0 {
+		return true
+	}
+
+	for _, variant := range variants {
+		if variant.Stock > 0 {
+			return true
+		}
+	}
+
+	return false
+}
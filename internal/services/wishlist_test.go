@@ -0,0 +1,78 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/internal/models"
+	"github.com/maksemen2/avito-shop/internal/repository"
+	"github.com/maksemen2/avito-shop/internal/services"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestWishlist_ListAndNotifications(t *testing.T) {
+	pricingSrv, _, db := getMockPricingServices(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	logger := zap.NewNop()
+	holderRepo := repository.NewHolderRepository(db, logger)
+	wishlistSrv := services.NewWishlistService(holderRepo, logger)
+	variantSrv := services.NewVariantService(holderRepo, logger)
+
+	user := database.User{Username: "dreamer", PasswordHash: "test", Coins: 100}
+	assert.NoError(t, db.Create(&user).Error, "failed to create user")
+
+	variant, err := variantSrv.CreateVariant(ctx, "t-shirt", models.CreateVariantRequest{Name: "M"})
+	assert.NoError(t, err, "failed to create variant")
+
+	assert.NoError(t, wishlistSrv.Add(ctx, user.ID, models.AddToWishlistRequest{Item: "t-shirt"}))
+	assert.NoError(t, wishlistSrv.Add(ctx, user.ID, models.AddToWishlistRequest{Item: "hoody"}))
+	assert.Equal(t, services.ErrAlreadyInWishlist, wishlistSrv.Add(ctx, user.ID, models.AddToWishlistRequest{Item: "hoody"}))
+	assert.Equal(t, services.ErrItemNotFound, wishlistSrv.Add(ctx, user.ID, models.AddToWishlistRequest{Item: "car"}))
+
+	items, err := wishlistSrv.List(ctx, user.ID, now)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []models.WishlistItem{
+		{Item: "t-shirt", Price: 80, InStock: false, CanAfford: true},
+		{Item: "hoody", Price: 300, InStock: true, CanAfford: false},
+	}, items)
+
+	_, err = variantSrv.Restock(ctx, variant.ID, 3)
+	assert.NoError(t, err, "failed to restock variant")
+
+	// Повторное пополнение не уведомляет: вариант и так был в наличии
+	_, err = variantSrv.Restock(ctx, variant.ID, 1)
+	assert.NoError(t, err, "failed to restock variant")
+
+	_, err = pricingSrv.CreateSchedule(ctx, models.CreatePriceScheduleRequest{
+		Item:     "hoody",
+		Price:    90,
+		StartsAt: now.Add(-time.Minute),
+		EndsAt:   now.Add(time.Hour),
+	})
+	assert.NoError(t, err, "failed to create schedule")
+
+	notified, err := pricingSrv.NotifySales(ctx, now)
+	assert.NoError(t, err)
+	assert.Zero(t, notified, "started sale should be notified only once")
+
+	var notifications []database.Notification
+	assert.NoError(t, db.Where("user_id = ?", user.ID).Order("id").Find(&notifications).Error)
+	assert.Len(t, notifications, 2)
+	assert.Equal(t, database.NotificationRestock, notifications[0].Type)
+	assert.Equal(t, database.NotificationSale, notifications[1].Type)
+
+	items, err = wishlistSrv.List(ctx, user.ID, now)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []models.WishlistItem{
+		{Item: "t-shirt", Price: 80, InStock: true, CanAfford: true},
+		{Item: "hoody", Price: 90, InStock: true, CanAfford: true},
+	}, items)
+
+	assert.NoError(t, wishlistSrv.Remove(ctx, user.ID, "hoody"))
+	assert.Equal(t, services.ErrNotInWishlist, wishlistSrv.Remove(ctx, user.ID, "hoody"))
+}
@@ -0,0 +1,394 @@
+// Package shopctl реализует команды административной утилиты shopctl.
+// Команды работают напрямую с базой через репозитории, минуя HTTP API и проверку прав администратора.
+package shopctl
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrUsage          = errors.New("invalid usage")
+	ErrUnknownCommand = errors.New("unknown command")
+)
+
+const usage = `usage: shopctl <command> <subcommand> [flags] [args]
+
+commands:
+  user create <username> <password>       create a user with the starting balance
+  user show <username>                    show user details and balance
+  user lock <username>                    forbid the user to get new tokens
+  user unlock <username>                  allow the user to get new tokens again
+  coins grant [-message text] <username> <amount>
+                                          credit amount coins to the user
+  coins adjust [-message text] <username> <delta>
+                                          change the balance by a signed delta
+  goods list                              list goods with base prices
+  goods set-price <name> <price>          change the base price of a good
+  tx show <id>                            show a coin transfer
+  tx reverse <id>                         reverse a coin transfer
+  migrate up                              apply all pending migrations
+  migrate down [-steps n]                 revert the last n migrations (1 by default)
+  migrate status                          show current and latest schema versions
+  migrate force <version>                 mark the schema as being at version without running SQL
+`
+
+// CLI выполняет команды shopctl и пишет их вывод в out.
+type CLI struct {
+	repository repository.HolderRepository
+	migrator   *database.Migrator
+	out        io.Writer
+}
+
+func New(repository repository.HolderRepository, migrator *database.Migrator, out io.Writer) *CLI {
+	return &CLI{
+		repository: repository,
+		migrator:   migrator,
+		out:        out,
+	}
+}
+
+// Usage выводит справку по командам.
+func (c *CLI) Usage() {
+	fmt.Fprint(c.out, usage)
+}
+
+// Run выполняет команду, заданную аргументами командной строки без имени программы.
+func (c *CLI) Run(ctx context.Context, args []string) error {
+	if len(args) < 2 {
+		return ErrUsage
+	}
+
+	command, subcommand, args := args[0], args[1], args[2:]
+
+	switch command + " " + subcommand {
+	case "user create":
+		return c.userCreate(ctx, args)
+	case "user show":
+		return c.userShow(ctx, args)
+	case "user lock":
+		return c.userSetLocked(ctx, args, true)
+	case "user unlock":
+		return c.userSetLocked(ctx, args, false)
+	case "coins grant":
+		return c.coinsAdjust(ctx, args, true)
+	case "coins adjust":
+		return c.coinsAdjust(ctx, args, false)
+	case "goods list":
+		return c.goodsList(ctx, args)
+	case "goods set-price":
+		return c.goodsSetPrice(ctx, args)
+	case "tx show":
+		return c.txShow(ctx, args)
+	case "tx reverse":
+		return c.txReverse(ctx, args)
+	case "migrate up":
+		return c.migrateUp(ctx, args)
+	case "migrate down":
+		return c.migrateDown(ctx, args)
+	case "migrate status":
+		return c.migrateStatus(ctx, args)
+	case "migrate force":
+		return c.migrateForce(ctx, args)
+	default:
+		return fmt.Errorf("%w: %s %s", ErrUnknownCommand, command, subcommand)
+	}
+}
+
+func (c *CLI) userCreate(ctx context.Context, args []string) error {
+	if len(args) != 2 || args[0] == "" || args[1] == "" {
+		return ErrUsage
+	}
+
+	if _, err := c.repository.User().GetByUsername(ctx, args[0]); err == nil {
+		return fmt.Errorf("user %s already exists", args[0])
+	} else if !errors.Is(err, repository.ErrUserNotFound) {
+		return err
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(args[1]), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	user, err := c.repository.User().Create(ctx, args[0], string(passwordHash))
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.out, "created user %s (id %d) with %d coins\n", user.Username, user.ID, user.Coins)
+
+	return nil
+}
+
+func (c *CLI) userShow(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return ErrUsage
+	}
+
+	user, err := c.repository.User().GetByUsername(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "id:\t%d\n", user.ID)
+	fmt.Fprintf(w, "username:\t%s\n", user.Username)
+	fmt.Fprintf(w, "coins:\t%d\n", user.Coins)
+	fmt.Fprintf(w, "admin:\t%t\n", user.IsAdmin)
+	fmt.Fprintf(w, "locked:\t%t\n", user.Locked)
+	fmt.Fprintf(w, "created:\t%s\n", user.CreatedAt.Format(time.RFC3339))
+
+	return w.Flush()
+}
+
+func (c *CLI) userSetLocked(ctx context.Context, args []string, locked bool) error {
+	if len(args) != 1 {
+		return ErrUsage
+	}
+
+	userID, err := c.repository.User().GetIDByUsername(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	if err := c.repository.User().SetLocked(ctx, userID, locked); err != nil {
+		return err
+	}
+
+	if locked {
+		fmt.Fprintf(c.out, "locked user %s\n", args[0])
+	} else {
+		fmt.Fprintf(c.out, "unlocked user %s\n", args[0])
+	}
+
+	return nil
+}
+
+// coinsAdjust начисляет (grant) или изменяет на знаковую величину (adjust) баланс пользователя.
+// Для grant без -message пользователь получает стандартное уведомление о начислении.
+func (c *CLI) coinsAdjust(ctx context.Context, args []string, grant bool) error {
+	flags := flag.NewFlagSet("coins", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	message := flags.String("message", "", "notification sent to the user")
+
+	if err := flags.Parse(args); err != nil || flags.NArg() != 2 {
+		return ErrUsage
+	}
+
+	username := flags.Arg(0)
+
+	delta, err := strconv.Atoi(flags.Arg(1))
+	if err != nil || delta == 0 || (grant && delta < 0) {
+		return fmt.Errorf("%w: amount must be a non-zero integer, positive for grant", ErrUsage)
+	}
+
+	if grant && *message == "" {
+		*message = fmt.Sprintf("You received %d coins from the administration", delta)
+	}
+
+	userID, err := c.repository.User().GetIDByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	if err := c.repository.AdjustCoins(ctx, userID, delta, *message); err != nil {
+		return err
+	}
+
+	balance, err := c.repository.User().GetBalance(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.out, "balance of %s changed by %+d, now %d coins\n", username, delta, balance)
+
+	return nil
+}
+
+func (c *CLI) goodsList(ctx context.Context, args []string) error {
+	if len(args) != 0 {
+		return ErrUsage
+	}
+
+	goods, err := c.repository.Good().GetAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tPRICE")
+
+	for _, good := range goods {
+		fmt.Fprintf(w, "%d\t%s\t%d\n", good.ID, good.Type, good.Price)
+	}
+
+	return w.Flush()
+}
+
+func (c *CLI) goodsSetPrice(ctx context.Context, args []string) error {
+	if len(args) != 2 {
+		return ErrUsage
+	}
+
+	price, err := strconv.Atoi(args[1])
+	if err != nil || price <= 0 {
+		return fmt.Errorf("%w: price must be a positive integer", ErrUsage)
+	}
+
+	if err := c.repository.Good().SetPrice(ctx, args[0], price); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.out, "price of %s set to %d\n", args[0], price)
+
+	return nil
+}
+
+func (c *CLI) txShow(ctx context.Context, args []string) error {
+	transactionID, err := parseID(args)
+	if err != nil {
+		return err
+	}
+
+	transaction, err := c.repository.Transaction().GetByID(ctx, transactionID)
+	if err != nil {
+		return err
+	}
+
+	from, err := c.repository.User().GetByID(ctx, transaction.FromUserID)
+	if err != nil {
+		return err
+	}
+
+	to, err := c.repository.User().GetByID(ctx, transaction.ToUserID)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "id:\t%d\n", transaction.ID)
+	fmt.Fprintf(w, "from:\t%s\n", from.Username)
+	fmt.Fprintf(w, "to:\t%s\n", to.Username)
+	fmt.Fprintf(w, "amount:\t%d\n", transaction.Amount)
+	fmt.Fprintf(w, "created:\t%s\n", transaction.CreatedAt.Format(time.RFC3339))
+
+	if transaction.ReversalOfID != nil {
+		fmt.Fprintf(w, "reversal of:\t%d\n", *transaction.ReversalOfID)
+	}
+
+	return w.Flush()
+}
+
+func (c *CLI) txReverse(ctx context.Context, args []string) error {
+	transactionID, err := parseID(args)
+	if err != nil {
+		return err
+	}
+
+	reversal, err := c.repository.ReverseTransfer(ctx, transactionID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.out, "transfer %d reversed by transfer %d\n", transactionID, reversal.ID)
+
+	return nil
+}
+
+func (c *CLI) migrateUp(ctx context.Context, args []string) error {
+	if len(args) != 0 {
+		return ErrUsage
+	}
+
+	applied, err := c.migrator.Up(ctx)
+	for _, migration := range applied {
+		fmt.Fprintf(c.out, "applied %d_%s\n", migration.Version, migration.Name)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if len(applied) == 0 {
+		fmt.Fprintln(c.out, "schema is up to date")
+	}
+
+	return nil
+}
+
+func (c *CLI) migrateDown(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("migrate down", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	steps := flags.Int("steps", 1, "number of migrations to revert")
+
+	if err := flags.Parse(args); err != nil || flags.NArg() != 0 || *steps <= 0 {
+		return ErrUsage
+	}
+
+	reverted, err := c.migrator.Down(ctx, *steps)
+	for _, migration := range reverted {
+		fmt.Fprintf(c.out, "reverted %d_%s\n", migration.Version, migration.Name)
+	}
+
+	return err
+}
+
+func (c *CLI) migrateStatus(ctx context.Context, args []string) error {
+	if len(args) != 0 {
+		return ErrUsage
+	}
+
+	version, err := c.migrator.Version(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "current:\t%d\n", version)
+	fmt.Fprintf(w, "latest:\t%d\n", c.migrator.Latest())
+
+	return w.Flush()
+}
+
+func (c *CLI) migrateForce(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return ErrUsage
+	}
+
+	version, err := strconv.ParseUint(args[0], 10, 32)
+	if err != nil {
+		return fmt.Errorf("%w: version must be a non-negative integer", ErrUsage)
+	}
+
+	if err := c.migrator.Force(ctx, uint(version)); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.out, "schema version set to %d\n", version)
+
+	return nil
+}
+
+func parseID(args []string) (uint, error) {
+	if len(args) != 1 {
+		return 0, ErrUsage
+	}
+
+	id, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil || id == 0 {
+		return 0, fmt.Errorf("%w: id must be a positive integer", ErrUsage)
+	}
+
+	return uint(id), nil
+}
@@ -0,0 +1,128 @@
+package shopctl_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/maksemen2/avito-shop/config"
+	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/internal/models"
+	"github.com/maksemen2/avito-shop/internal/repository"
+	"github.com/maksemen2/avito-shop/internal/services"
+	"github.com/maksemen2/avito-shop/internal/shopctl"
+	"github.com/maksemen2/avito-shop/pkg/auth"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+func setupTestCLI(t *testing.T) (*shopctl.CLI, *bytes.Buffer, repository.HolderRepository) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormLogger.Default.LogMode(gormLogger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to open in-memory database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&database.User{}, &database.Purchase{}, &database.Transaction{}, &database.Good{}, &database.CoinRequest{}, &database.GrantProgram{}, &database.Grant{}, &database.CoinLot{}, &database.CoinExpiration{}, &database.ItemTransfer{}, &database.Listing{}, &database.PromoCode{}, &database.PromoRedemption{}, &database.PriceSchedule{}, &database.GoodVariant{}, &database.WishlistItem{}, &database.Notification{}, &database.OutboxEvent{}, &database.Webhook{}, &database.WebhookDelivery{}); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	if err := db.Create(&database.Good{Type: "t-shirt", Price: 80}).Error; err != nil {
+		t.Fatalf("failed to create good: %v", err)
+	}
+
+	migrator, err := database.NewMigrator(db, fstest.MapFS{
+		"0001_audit.up.sql":   {Data: []byte("CREATE TABLE audit (id INTEGER PRIMARY KEY);")},
+		"0001_audit.down.sql": {Data: []byte("DROP TABLE audit;")},
+	})
+	if err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+
+	holderRepo := repository.NewHolderRepository(db, zap.NewNop())
+	out := &bytes.Buffer{}
+
+	return shopctl.New(holderRepo, migrator, out), out, holderRepo
+}
+
+func TestCLI_Commands(t *testing.T) {
+	cli, out, holderRepo := setupTestCLI(t)
+	ctx := context.Background()
+
+	run := func(args ...string) string {
+		out.Reset()
+
+		if err := cli.Run(ctx, args); err != nil {
+			t.Fatalf("shopctl %v: %v", args, err)
+		}
+
+		return out.String()
+	}
+
+	assert.Contains(t, run("user", "create", "alice", "secret"), "created user alice (id 1) with 1000 coins")
+	run("user", "create", "bob", "secret")
+
+	assert.Contains(t, run("coins", "grant", "alice", "200"), "now 1200 coins")
+	assert.Contains(t, run("coins", "adjust", "-message", "correction", "alice", "-100"), "now 1100 coins")
+
+	assert.NoError(t, holderRepo.TransferCoins(ctx, 1, 2, 100, 0))
+	assert.Contains(t, run("tx", "show", "1"), "from:     alice")
+	assert.Contains(t, run("tx", "reverse", "1"), "transfer 1 reversed by transfer 2")
+	assert.Contains(t, run("tx", "show", "2"), "reversal of:  1")
+	assert.Contains(t, run("user", "show", "alice"), "coins:     1100")
+
+	assert.Contains(t, run("goods", "set-price", "t-shirt", "120"), "price of t-shirt set to 120")
+	assert.Regexp(t, `1\s+t-shirt\s+120`, run("goods", "list"))
+
+	run("user", "lock", "alice")
+
+	authService := services.NewAuthService(holderRepo, auth.NewJWTManager(config.AuthConfig{JwtKey: "very_secret_key", TokenLifetimeHours: 1}), zap.NewNop())
+
+	_, err := authService.Authenticate(ctx, models.AuthRequest{Username: "alice", Password: "secret"})
+	assert.ErrorIs(t, err, services.ErrUserLocked)
+
+	_, err = authService.Authenticate(ctx, models.AuthRequest{Username: "alice", Password: "wrong"})
+	assert.ErrorIs(t, err, services.ErrAuthFailed, "lock must not be revealed without the password")
+
+	run("user", "unlock", "alice")
+
+	_, err = authService.Authenticate(ctx, models.AuthRequest{Username: "alice", Password: "secret"})
+	assert.NoError(t, err)
+}
+
+func TestCLI_Migrate(t *testing.T) {
+	cli, out, _ := setupTestCLI(t)
+	ctx := context.Background()
+
+	run := func(args ...string) string {
+		out.Reset()
+
+		if err := cli.Run(ctx, args); err != nil {
+			t.Fatalf("shopctl %v: %v", args, err)
+		}
+
+		return out.String()
+	}
+
+	assert.Regexp(t, `current:\s+0\nlatest:\s+1`, run("migrate", "status"))
+	assert.Contains(t, run("migrate", "up"), "applied 1_audit")
+	assert.Contains(t, run("migrate", "up"), "schema is up to date")
+	assert.Contains(t, run("migrate", "down"), "reverted 1_audit")
+	assert.Contains(t, run("migrate", "force", "1"), "schema version set to 1")
+	assert.Regexp(t, `current:\s+1`, run("migrate", "status"))
+}
+
+func TestCLI_Errors(t *testing.T) {
+	cli, _, _ := setupTestCLI(t)
+	ctx := context.Background()
+
+	assert.ErrorIs(t, cli.Run(ctx, []string{"user"}), shopctl.ErrUsage)
+	assert.ErrorIs(t, cli.Run(ctx, []string{"user", "delete", "alice"}), shopctl.ErrUnknownCommand)
+	assert.ErrorIs(t, cli.Run(ctx, []string{"coins", "grant", "alice", "-5"}), shopctl.ErrUsage)
+	assert.ErrorIs(t, cli.Run(ctx, []string{"user", "show", "alice"}), repository.ErrUserNotFound)
+	assert.ErrorIs(t, cli.Run(ctx, []string{"goods", "set-price", "mug", "10"}), repository.ErrGoodNotFound)
+	assert.ErrorIs(t, cli.Run(ctx, []string{"tx", "reverse", "1"}), repository.ErrTransactionNotFound)
+}
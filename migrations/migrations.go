@@ -0,0 +1,22 @@
+// Package migrations содержит версионированные SQL-миграции схемы базы данных.
+// Для каждого драйвера есть свой каталог с одинаковыми версиями миграций: <версия>_<название>.up.sql
+// и <версия>_<название>.down.sql. Файлы встраиваются в бинарники, применяет их database.Migrator.
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+)
+
+//go:embed postgres/*.sql sqlite/*.sql
+var files embed.FS
+
+// ForDriver возвращает миграции для драйвера базы данных из config.DatabaseConfig.
+func ForDriver(driver string) (fs.FS, error) {
+	if _, err := fs.Stat(files, driver); err != nil {
+		return nil, fmt.Errorf("no migrations for driver %q", driver)
+	}
+
+	return fs.Sub(files, driver)
+}
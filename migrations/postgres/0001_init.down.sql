@@ -0,0 +1,20 @@
+DROP TABLE webhook_deliveries,
+           webhooks,
+           outbox_events,
+           notifications,
+           wishlist_items,
+           good_variants,
+           price_schedules,
+           promo_redemptions,
+           promo_codes,
+           listings,
+           item_transfers,
+           coin_expirations,
+           coin_lots,
+           grants,
+           grant_programs,
+           coin_requests,
+           purchases,
+           transactions,
+           goods,
+           users;
@@ -0,0 +1,464 @@
+CREATE TABLE users (
+    id BIGSERIAL PRIMARY KEY,
+    username VARCHAR(255) NOT NULL UNIQUE,
+    password_hash CHAR(60) NOT NULL,
+    coins BIGINT NOT NULL DEFAULT 1000 CHECK (coins >= 0),
+    is_admin BOOLEAN NOT NULL DEFAULT FALSE,
+    locked BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE goods (
+    id BIGSERIAL PRIMARY KEY,
+    type VARCHAR(255) NOT NULL UNIQUE,
+    price BIGINT NOT NULL CHECK (price > 0)
+);
+
+CREATE TABLE transactions (
+    id BIGSERIAL PRIMARY KEY,
+    from_user_id BIGINT NOT NULL,
+    to_user_id BIGINT NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    kind VARCHAR(16) NOT NULL DEFAULT 'transfer',
+    reversal_of_id BIGINT UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    
+    CONSTRAINT fk_from_user
+        FOREIGN KEY (from_user_id) 
+        REFERENCES users(id)
+        ON UPDATE CASCADE
+        ON DELETE CASCADE,
+        
+    CONSTRAINT fk_to_user
+        FOREIGN KEY (to_user_id) 
+        REFERENCES users(id)
+        ON UPDATE CASCADE
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_reversal_of
+        FOREIGN KEY (reversal_of_id)
+        REFERENCES transactions(id)
+        ON UPDATE CASCADE
+        ON DELETE SET NULL
+);
+
+CREATE TABLE purchases (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    good_id BIGINT NOT NULL,
+    gifted_by_id BIGINT,
+    gifted_to_id BIGINT,
+    listing_id BIGINT,
+    variant_id BIGINT,
+    promo_code_id BIGINT,
+    discount INT NOT NULL DEFAULT 0 CHECK (discount >= 0),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    
+    CONSTRAINT fk_user
+        FOREIGN KEY (user_id) 
+        REFERENCES users(id)
+        ON UPDATE CASCADE
+        ON DELETE CASCADE,
+        
+    CONSTRAINT fk_good
+        FOREIGN KEY (good_id) 
+        REFERENCES goods(id)
+        ON UPDATE CASCADE
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_gifted_by
+        FOREIGN KEY (gifted_by_id)
+        REFERENCES users(id)
+        ON UPDATE CASCADE
+        ON DELETE SET NULL,
+
+    CONSTRAINT fk_gifted_to
+        FOREIGN KEY (gifted_to_id)
+        REFERENCES users(id)
+        ON UPDATE CASCADE
+        ON DELETE SET NULL
+);
+
+CREATE TABLE coin_requests (
+    id BIGSERIAL PRIMARY KEY,
+    requester_id BIGINT NOT NULL,
+    payer_id BIGINT NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    status VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'declined')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMP,
+
+    CONSTRAINT fk_requester
+        FOREIGN KEY (requester_id)
+        REFERENCES users(id)
+        ON UPDATE CASCADE
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_payer
+        FOREIGN KEY (payer_id)
+        REFERENCES users(id)
+        ON UPDATE CASCADE
+        ON DELETE CASCADE
+);
+
+CREATE TABLE grant_programs (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    period VARCHAR(16) NOT NULL CHECK (period IN ('daily', 'weekly', 'monthly')),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE grants (
+    id BIGSERIAL PRIMARY KEY,
+    program_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    period VARCHAR(32) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_grant_program
+        FOREIGN KEY (program_id)
+        REFERENCES grant_programs(id)
+        ON UPDATE CASCADE
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_grant_user
+        FOREIGN KEY (user_id)
+        REFERENCES users(id)
+        ON UPDATE CASCADE
+        ON DELETE CASCADE,
+
+    CONSTRAINT uq_grants_program_user_period UNIQUE (program_id, user_id, period)
+);
+
+CREATE TABLE coin_lots (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    source VARCHAR(16) NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    remaining BIGINT NOT NULL CHECK (remaining >= 0),
+    expires_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_coin_lot_user
+        FOREIGN KEY (user_id)
+        REFERENCES users(id)
+        ON UPDATE CASCADE
+        ON DELETE CASCADE
+);
+
+CREATE TABLE coin_expirations (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_coin_expiration_user
+        FOREIGN KEY (user_id)
+        REFERENCES users(id)
+        ON UPDATE CASCADE
+        ON DELETE CASCADE
+);
+
+CREATE TABLE item_transfers (
+    id BIGSERIAL PRIMARY KEY,
+    from_user_id BIGINT,
+    to_user_id BIGINT,
+    good_id BIGINT NOT NULL,
+    variant_id BIGINT,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_item_transfer_from_user
+        FOREIGN KEY (from_user_id)
+        REFERENCES users(id)
+        ON UPDATE CASCADE
+        ON DELETE SET NULL,
+
+    CONSTRAINT fk_item_transfer_to_user
+        FOREIGN KEY (to_user_id)
+        REFERENCES users(id)
+        ON UPDATE CASCADE
+        ON DELETE SET NULL,
+
+    CONSTRAINT fk_item_transfer_good
+        FOREIGN KEY (good_id)
+        REFERENCES goods(id)
+        ON UPDATE CASCADE
+        ON DELETE CASCADE
+);
+
+CREATE TABLE listings (
+    id BIGSERIAL PRIMARY KEY,
+    seller_id BIGINT NOT NULL,
+    good_id BIGINT NOT NULL,
+    variant_id BIGINT,
+    price INT NOT NULL CHECK (price > 0),
+    status VARCHAR(16) NOT NULL DEFAULT 'active',
+    buyer_id BIGINT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    closed_at TIMESTAMP,
+
+    CONSTRAINT fk_listing_seller
+        FOREIGN KEY (seller_id)
+        REFERENCES users(id)
+        ON UPDATE CASCADE
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_listing_good
+        FOREIGN KEY (good_id)
+        REFERENCES goods(id)
+        ON UPDATE CASCADE
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_listing_buyer
+        FOREIGN KEY (buyer_id)
+        REFERENCES users(id)
+        ON UPDATE CASCADE
+        ON DELETE SET NULL
+);
+
+ALTER TABLE purchases
+    ADD CONSTRAINT fk_purchase_listing
+        FOREIGN KEY (listing_id)
+        REFERENCES listings(id)
+        ON UPDATE CASCADE
+        ON DELETE SET NULL;
+
+CREATE TABLE promo_codes (
+    id BIGSERIAL PRIMARY KEY,
+    code VARCHAR(64) UNIQUE NOT NULL,
+    discount_type VARCHAR(16) NOT NULL,
+    discount_value INT NOT NULL CHECK (discount_value > 0),
+    good_id BIGINT,
+    max_uses INT NOT NULL DEFAULT 0,
+    max_uses_per_user INT NOT NULL DEFAULT 0,
+    used_count INT NOT NULL DEFAULT 0,
+    valid_from TIMESTAMP,
+    valid_until TIMESTAMP,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_promo_code_good
+        FOREIGN KEY (good_id)
+        REFERENCES goods(id)
+        ON UPDATE CASCADE
+        ON DELETE CASCADE
+);
+
+ALTER TABLE purchases
+    ADD CONSTRAINT fk_purchase_promo_code
+        FOREIGN KEY (promo_code_id)
+        REFERENCES promo_codes(id)
+        ON UPDATE CASCADE
+        ON DELETE SET NULL;
+
+CREATE TABLE promo_redemptions (
+    id BIGSERIAL PRIMARY KEY,
+    promo_code_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    purchase_id BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_promo_redemption_promo_code
+        FOREIGN KEY (promo_code_id)
+        REFERENCES promo_codes(id)
+        ON UPDATE CASCADE
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_promo_redemption_user
+        FOREIGN KEY (user_id)
+        REFERENCES users(id)
+        ON UPDATE CASCADE
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_promo_redemption_purchase
+        FOREIGN KEY (purchase_id)
+        REFERENCES purchases(id)
+        ON UPDATE CASCADE
+        ON DELETE CASCADE
+);
+
+CREATE TABLE price_schedules (
+    id BIGSERIAL PRIMARY KEY,
+    good_id BIGINT NOT NULL,
+    price INT NOT NULL DEFAULT 0 CHECK (price >= 0),
+    discount_percent INT NOT NULL DEFAULT 0 CHECK (discount_percent >= 0 AND discount_percent < 100),
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    notified BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_price_schedule_good
+        FOREIGN KEY (good_id)
+        REFERENCES goods(id)
+        ON UPDATE CASCADE
+        ON DELETE CASCADE,
+
+    CONSTRAINT chk_price_schedule_window CHECK (ends_at > starts_at)
+);
+
+CREATE TABLE good_variants (
+    id BIGSERIAL PRIMARY KEY,
+    good_id BIGINT NOT NULL,
+    name VARCHAR(64) NOT NULL,
+    stock INT NOT NULL DEFAULT 0 CHECK (stock >= 0),
+    price_delta INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_good_variant_good
+        FOREIGN KEY (good_id)
+        REFERENCES goods(id)
+        ON UPDATE CASCADE
+        ON DELETE CASCADE,
+
+    CONSTRAINT uq_good_variant_name UNIQUE (good_id, name)
+);
+
+ALTER TABLE purchases
+    ADD CONSTRAINT fk_purchase_variant
+        FOREIGN KEY (variant_id)
+        REFERENCES good_variants(id)
+        ON UPDATE CASCADE
+        ON DELETE SET NULL;
+
+ALTER TABLE listings
+    ADD CONSTRAINT fk_listing_variant
+        FOREIGN KEY (variant_id)
+        REFERENCES good_variants(id)
+        ON UPDATE CASCADE
+        ON DELETE SET NULL;
+
+ALTER TABLE item_transfers
+    ADD CONSTRAINT fk_item_transfer_variant
+        FOREIGN KEY (variant_id)
+        REFERENCES good_variants(id)
+        ON UPDATE CASCADE
+        ON DELETE SET NULL;
+
+CREATE TABLE wishlist_items (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    good_id BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_wishlist_item_user
+        FOREIGN KEY (user_id)
+        REFERENCES users(id)
+        ON UPDATE CASCADE
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_wishlist_item_good
+        FOREIGN KEY (good_id)
+        REFERENCES goods(id)
+        ON UPDATE CASCADE
+        ON DELETE CASCADE,
+
+    CONSTRAINT uq_wishlist_item_user_good UNIQUE (user_id, good_id)
+);
+
+CREATE TABLE notifications (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    type VARCHAR(32) NOT NULL,
+    message VARCHAR(512) NOT NULL,
+    read_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_notification_user
+        FOREIGN KEY (user_id)
+        REFERENCES users(id)
+        ON UPDATE CASCADE
+        ON DELETE CASCADE
+);
+
+CREATE TABLE outbox_events (
+    id BIGSERIAL PRIMARY KEY,
+    type VARCHAR(64) NOT NULL,
+    payload TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE webhooks (
+    id BIGSERIAL PRIMARY KEY,
+    url VARCHAR(2048) NOT NULL,
+    secret VARCHAR(255) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE webhook_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    webhook_id BIGINT NOT NULL,
+    event_id BIGINT NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'dead')),
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    lease_until TIMESTAMP,
+    last_error VARCHAR(512) NOT NULL DEFAULT '',
+    delivered_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_webhook_delivery_webhook
+        FOREIGN KEY (webhook_id)
+        REFERENCES webhooks(id)
+        ON UPDATE CASCADE
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_webhook_delivery_event
+        FOREIGN KEY (event_id)
+        REFERENCES outbox_events(id)
+        ON UPDATE CASCADE
+        ON DELETE CASCADE
+);
+
+INSERT INTO goods (type, price)
+VALUES ('t-shirt', 80),
+       ('cup', 20),
+       ('book', 50),
+       ('pen', 10),
+       ('powerbank', 200),
+       ('hoody', 300),
+       ('umbrella', 200),
+       ('socks', 10),
+       ('wallet', 50),
+       ('pink-hoody', 500)
+ON CONFLICT (type) DO NOTHING;
+
+CREATE INDEX idx_transactions_from_user ON transactions(from_user_id);
+CREATE INDEX idx_transactions_to_user ON transactions(to_user_id);
+CREATE INDEX idx_transactions_from_user_kind_created ON transactions(from_user_id, kind, created_at);
+CREATE INDEX idx_purchases_user ON purchases(user_id);
+CREATE INDEX idx_purchases_good ON purchases(good_id);
+CREATE INDEX idx_purchases_gifted_by ON purchases(gifted_by_id);
+CREATE INDEX idx_purchases_gifted_to ON purchases(gifted_to_id);
+CREATE INDEX idx_coin_requests_requester ON coin_requests(requester_id);
+CREATE INDEX idx_coin_requests_payer ON coin_requests(payer_id);
+CREATE INDEX idx_coin_requests_status ON coin_requests(status);
+CREATE INDEX idx_grants_user ON grants(user_id);
+CREATE INDEX idx_coin_lots_user_created ON coin_lots(user_id, created_at) WHERE remaining > 0;
+CREATE INDEX idx_coin_lots_expires_at ON coin_lots(expires_at) WHERE remaining > 0;
+CREATE INDEX idx_coin_expirations_user ON coin_expirations(user_id);
+CREATE INDEX idx_item_transfers_from_user ON item_transfers(from_user_id);
+CREATE INDEX idx_item_transfers_to_user ON item_transfers(to_user_id);
+CREATE INDEX idx_item_transfers_variant ON item_transfers(variant_id);
+CREATE INDEX idx_purchases_listing ON purchases(listing_id);
+CREATE INDEX idx_listings_seller ON listings(seller_id);
+CREATE INDEX idx_listings_good_status ON listings(good_id, status);
+CREATE INDEX idx_listings_variant ON listings(variant_id);
+CREATE INDEX idx_purchases_promo_code ON purchases(promo_code_id);
+CREATE INDEX idx_promo_codes_good ON promo_codes(good_id);
+CREATE INDEX idx_promo_redemptions_promo_user ON promo_redemptions(promo_code_id, user_id);
+CREATE INDEX idx_promo_redemptions_purchase ON promo_redemptions(purchase_id);
+CREATE INDEX idx_price_schedules_good_window ON price_schedules(good_id, starts_at, ends_at);
+CREATE INDEX idx_purchases_variant ON purchases(variant_id);
+CREATE INDEX idx_wishlist_items_good ON wishlist_items(good_id);
+CREATE INDEX idx_notifications_user_created ON notifications(user_id, created_at);
+CREATE INDEX idx_webhook_deliveries_status_next ON webhook_deliveries(status, next_attempt_at);
+CREATE INDEX idx_webhook_deliveries_webhook ON webhook_deliveries(webhook_id);
+CREATE INDEX idx_webhook_deliveries_event ON webhook_deliveries(event_id);
+CREATE INDEX idx_users_username ON users(username);
+CREATE INDEX idx_goods_type ON goods(type);
\ No newline at end of file
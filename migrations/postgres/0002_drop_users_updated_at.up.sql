@@ -0,0 +1,2 @@
+-- Колонка не отражена в модели User и никогда не обновлялась приложением
+ALTER TABLE users DROP COLUMN updated_at;
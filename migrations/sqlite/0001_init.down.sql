@@ -0,0 +1,20 @@
+DROP TABLE webhook_deliveries;
+DROP TABLE webhooks;
+DROP TABLE outbox_events;
+DROP TABLE notifications;
+DROP TABLE wishlist_items;
+DROP TABLE promo_redemptions;
+DROP TABLE purchases;
+DROP TABLE good_variants;
+DROP TABLE price_schedules;
+DROP TABLE promo_codes;
+DROP TABLE listings;
+DROP TABLE item_transfers;
+DROP TABLE coin_expirations;
+DROP TABLE coin_lots;
+DROP TABLE grants;
+DROP TABLE grant_programs;
+DROP TABLE coin_requests;
+DROP TABLE transactions;
+DROP TABLE goods;
+DROP TABLE users;
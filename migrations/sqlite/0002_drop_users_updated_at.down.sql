@@ -0,0 +1,2 @@
+-- Схема SQLite создана уже без колонки updated_at, миграция сохраняет нумерацию версий общей с Postgres
+SELECT 1;
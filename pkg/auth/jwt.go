@@ -1,12 +1,16 @@
 package auth
 
 import (
+	"errors"
 	"time"
 
 	jwt "github.com/golang-jwt/jwt/v5"
 	"github.com/maksemen2/avito-shop/config"
 )
 
+// ErrInvalidToken - токен подписан верно, но не содержит данных пользователя.
+var ErrInvalidToken = errors.New("invalid token")
+
 type JWTManager struct {
 	signingKey    []byte
 	tokenDuration time.Duration
@@ -46,3 +50,25 @@ func (m *JWTManager) ParseToken(tokenString string) (*jwt.Token, error) {
 		return m.signingKey, nil
 	})
 }
+
+// ValidateToken проверяет токен и возвращает идентификатор и имя пользователя из него.
+func (m *JWTManager) ValidateToken(tokenString string) (uint, string, error) {
+	t, err := m.ParseToken(tokenString)
+	if err != nil {
+		return 0, "", err
+	}
+
+	claims, ok := t.Claims.(jwt.MapClaims)
+	if !ok || !t.Valid {
+		return 0, "", ErrInvalidToken
+	}
+
+	userID, userIDExists := claims[UserIDKey].(float64)
+	username, usernameExists := claims[UsernameKey].(string)
+
+	if !userIDExists || !usernameExists {
+		return 0, "", ErrInvalidToken
+	}
+
+	return uint(userID), username, nil
+}
@@ -0,0 +1,255 @@
+// Package client - типизированный Go-клиент HTTP API магазина.
+// Клиент сам получает токен по логину и паролю, обновляет его после ответа 401
+// и повторяет запросы с экспоненциальной задержкой, если сервер ответил 5xx.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/maksemen2/avito-shop/internal/models"
+)
+
+// Модели запросов и ответов совпадают с моделями сервера.
+type (
+	InfoResponse    = models.InfoResponse
+	Item            = models.Item
+	CoinHistory     = models.CoinHistory
+	SendCoinRequest = models.SendCoinRequest
+	BuyOptions      = models.BuyOptions
+)
+
+const (
+	defaultMaxRetries = 3
+	defaultBackoff    = 200 * time.Millisecond
+)
+
+type Client struct {
+	baseURL     string
+	credentials models.AuthRequest
+	httpClient  *http.Client
+	maxRetries  int
+	backoff     time.Duration
+
+	mu    sync.Mutex
+	token string
+}
+
+type Option func(*Client)
+
+// WithHTTPClient задаёт HTTP-клиент, например с таймаутом или собственным транспортом.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithRetry задаёт число повторов запроса после ответа 5xx и начальную задержку, которая удваивается с каждым повтором.
+// Ноль повторов отключает их.
+func WithRetry(maxRetries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.backoff = backoff
+	}
+}
+
+// New создаёт клиент к API по адресу baseURL (например, http://localhost:8080).
+// Пользователь регистрируется при первом входе, как и при вызове /api/auth.
+func New(baseURL, username, password string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		credentials: models.AuthRequest{Username: username, Password: password},
+		httpClient:  http.DefaultClient,
+		maxRetries:  defaultMaxRetries,
+		backoff:     defaultBackoff,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Authenticate получает новый токен. Вызывать его не обязательно - остальные методы получают токен сами.
+func (c *Client) Authenticate(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.authenticate(ctx)
+}
+
+func (c *Client) Info(ctx context.Context) (InfoResponse, error) {
+	var resp InfoResponse
+
+	err := c.call(ctx, http.MethodGet, "/api/info", nil, &resp)
+
+	return resp, err
+}
+
+func (c *Client) SendCoin(ctx context.Context, req SendCoinRequest) error {
+	return c.call(ctx, http.MethodPost, "/api/sendCoin", req, nil)
+}
+
+func (c *Client) Buy(ctx context.Context, item string, opts BuyOptions) error {
+	query := url.Values{}
+
+	if opts.Variant != "" {
+		query.Set("variant", opts.Variant)
+	}
+
+	if opts.Promo != "" {
+		query.Set("promo", opts.Promo)
+	}
+
+	path := "/api/buy/" + url.PathEscape(item)
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	return c.call(ctx, http.MethodGet, path, nil, nil)
+}
+
+// authenticate получает токен. Вызывается под c.mu.
+func (c *Client) authenticate(ctx context.Context) error {
+	var resp models.AuthResponse
+
+	if err := c.retry(ctx, http.MethodPost, "/api/auth", c.credentials, "", &resp); err != nil {
+		return err
+	}
+
+	c.token = resp.Token
+
+	return nil
+}
+
+// currentToken возвращает действующий токен, получая его при первом обращении.
+func (c *Client) currentToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token == "" {
+		if err := c.authenticate(ctx); err != nil {
+			return "", err
+		}
+	}
+
+	return c.token, nil
+}
+
+// invalidateToken сбрасывает токен, если его ещё не обновил другой запрос.
+func (c *Client) invalidateToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token == token {
+		c.token = ""
+	}
+}
+
+// call выполняет запрос от имени пользователя. Если токен истёк, запрос повторяется один раз с новым токеном.
+func (c *Client) call(ctx context.Context, method, path string, body, out any) error {
+	token, err := c.currentToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = c.retry(ctx, method, path, body, token, out)
+	if !errors.Is(err, ErrUnauthorized) {
+		return err
+	}
+
+	c.invalidateToken(token)
+
+	if token, err = c.currentToken(ctx); err != nil {
+		return err
+	}
+
+	return c.retry(ctx, method, path, body, token, out)
+}
+
+// retry выполняет запрос и повторяет его после ответа 5xx. Ошибки сети не повторяются:
+// неизвестно, успел ли сервер выполнить перевод или покупку.
+func (c *Client) retry(ctx context.Context, method, path string, body any, token string, out any) error {
+	var payload []byte
+
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		statusCode, data, err := c.send(ctx, method, path, payload, token)
+		if err != nil {
+			return err
+		}
+
+		if statusCode >= http.StatusInternalServerError && attempt < c.maxRetries {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(c.backoff << attempt):
+			}
+
+			continue
+		}
+
+		if statusCode >= http.StatusBadRequest {
+			return newAPIError(statusCode, data)
+		}
+
+		if out == nil || len(data) == 0 {
+			return nil
+		}
+
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+
+		return nil
+	}
+}
+
+func (c *Client) send(ctx context.Context, method, path string, payload []byte, token string) (int, []byte, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	return resp.StatusCode, data, nil
+}
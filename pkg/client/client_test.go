@@ -0,0 +1,128 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maksemen2/avito-shop/config"
+	"github.com/maksemen2/avito-shop/internal/database"
+	"github.com/maksemen2/avito-shop/internal/events"
+	"github.com/maksemen2/avito-shop/internal/handlers"
+	"github.com/maksemen2/avito-shop/internal/repository"
+	"github.com/maksemen2/avito-shop/internal/routes"
+	"github.com/maksemen2/avito-shop/internal/services"
+	"github.com/maksemen2/avito-shop/pkg/auth"
+	"github.com/maksemen2/avito-shop/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+func setupServer(t *testing.T) *httptest.Server {
+	gin.SetMode(gin.TestMode)
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormLogger.Default.LogMode(gormLogger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	if err = db.AutoMigrate(&database.User{}, &database.Purchase{}, &database.Transaction{}, &database.Good{}, &database.CoinRequest{}, &database.GrantProgram{}, &database.Grant{}, &database.CoinLot{}, &database.CoinExpiration{}, &database.ItemTransfer{}, &database.Listing{}, &database.PromoCode{}, &database.PromoRedemption{}, &database.PriceSchedule{}, &database.GoodVariant{}, &database.WishlistItem{}, &database.Notification{}, &database.OutboxEvent{}, &database.Webhook{}, &database.WebhookDelivery{}); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	if err = db.Create(&database.Good{Type: "cup", Price: 20}).Error; err != nil {
+		t.Fatalf("failed to create good: %v", err)
+	}
+
+	logger := zap.NewNop()
+	jwtManager := auth.NewJWTManager(config.AuthConfig{JwtKey: "verySecretKey", TokenLifetimeHours: 72})
+	broker := events.NewBroker(logger)
+	holderRepo := repository.NewHolderRepository(db, logger, repository.WithUserChangeHook(services.NewEventsHook(broker, logger)))
+	handler := handlers.NewRequestsHandler(holderRepo, nil, jwtManager, broker, &config.Config{}, logger)
+
+	server := httptest.NewServer(routes.SetupRoutes(handler, logger, config.CorsConfig{}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestClient_Flow(t *testing.T) {
+	server := setupServer(t)
+	ctx := context.Background()
+
+	sender := client.New(server.URL, "sender", "password")
+	receiver := client.New(server.URL, "receiver", "password")
+
+	assert.NoError(t, receiver.Authenticate(ctx), "failed to register receiver")
+	assert.NoError(t, sender.SendCoin(ctx, client.SendCoinRequest{ToUser: "receiver", Amount: 100}), "failed to send coins")
+	assert.NoError(t, sender.Buy(ctx, "cup", client.BuyOptions{}), "failed to buy cup")
+
+	info, err := sender.Info(ctx)
+	assert.NoError(t, err, "failed to get info")
+	assert.Equal(t, 880, info.Coins)
+	assert.Equal(t, []client.Item{{Type: "cup", Quantity: 1}}, info.Inventory)
+
+	err = sender.SendCoin(ctx, client.SendCoinRequest{ToUser: "receiver", Amount: 100000})
+	assert.ErrorIs(t, err, client.ErrBadRequest)
+
+	var apiErr *client.APIError
+
+	assert.True(t, errors.As(err, &apiErr), "expected APIError")
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	assert.Equal(t, "insufficient funds", apiErr.Details)
+
+	intruder := client.New(server.URL, "sender", "wrong")
+	_, err = intruder.Info(ctx)
+	assert.ErrorIs(t, err, client.ErrUnauthorized, "wrong password should be rejected")
+}
+
+func TestClient_RetryAndTokenRefresh(t *testing.T) {
+	var authCalls, infoCalls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/api/auth":
+			n := authCalls.Add(1)
+			fmt.Fprintf(w, `{"token": "token-%d"}`, n)
+		case "/api/info":
+			switch infoCalls.Add(1) {
+			case 1, 2:
+				w.WriteHeader(http.StatusInternalServerError)
+				_, _ = w.Write([]byte(`{"errors": "internal server error"}`))
+			case 3:
+				// Первый токен истёк
+				w.WriteHeader(http.StatusUnauthorized)
+				_, _ = w.Write([]byte(`{"errors": "unauthorized"}`))
+			default:
+				assert.Equal(t, "Bearer token-2", r.Header.Get("Authorization"), "expected refreshed token")
+				_, _ = w.Write([]byte(`{"coins": 42}`))
+			}
+		}
+	}))
+	defer server.Close()
+
+	c := client.New(server.URL, "user", "password", client.WithRetry(2, time.Millisecond))
+
+	info, err := c.Info(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 42, info.Coins)
+	assert.Equal(t, int32(2), authCalls.Load(), "expected token to be refreshed once")
+	assert.Equal(t, int32(4), infoCalls.Load(), "expected two retries and one call with new token")
+
+	c = client.New(server.URL, "user", "password", client.WithRetry(0, time.Millisecond))
+	infoCalls.Store(0)
+
+	_, err = c.Info(context.Background())
+	assert.ErrorIs(t, err, client.ErrInternal, "expected error without retries")
+}
@@ -0,0 +1,63 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/maksemen2/avito-shop/internal/models"
+)
+
+// Ошибки по кодам ответа API. APIError разворачивается в одну из них, поэтому их можно проверять через errors.Is.
+var (
+	ErrBadRequest   = errors.New(models.ErrBadRequest)
+	ErrUnauthorized = errors.New(models.ErrUnauthorized)
+	ErrForbidden    = errors.New(models.ErrForbidden)
+	ErrNotFound     = errors.New(models.ErrNotFound)
+	ErrInternal     = errors.New(models.ErrInternal)
+)
+
+// APIError - ошибка, которую вернул сервер в теле models.ErrorResponse.
+// Code - общая категория ошибки ("bad request"), Details - причина ("insufficient funds"), если сервер её указал.
+type APIError struct {
+	StatusCode int
+	Code       string
+	Details    string
+}
+
+func (e *APIError) Error() string {
+	if e.Details == "" {
+		return e.Code
+	}
+
+	return e.Code + ": " + e.Details
+}
+
+func (e *APIError) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusUnauthorized:
+		return ErrUnauthorized
+	case e.StatusCode == http.StatusForbidden:
+		return ErrForbidden
+	case e.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case e.StatusCode >= http.StatusInternalServerError:
+		return ErrInternal
+	default:
+		return ErrBadRequest
+	}
+}
+
+// newAPIError разбирает тело ответа с ошибкой. Сервер пишет ошибку как "<code>: <details>" (см. models.NewDetailedErrorResponse).
+func newAPIError(statusCode int, body []byte) *APIError {
+	var resp models.ErrorResponse
+
+	if err := json.Unmarshal(body, &resp); err != nil || resp.Errors == "" {
+		return &APIError{StatusCode: statusCode, Code: strings.ToLower(http.StatusText(statusCode))}
+	}
+
+	code, details, _ := strings.Cut(resp.Errors, ": ")
+
+	return &APIError{StatusCode: statusCode, Code: code, Details: details}
+}
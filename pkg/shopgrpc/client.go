@@ -0,0 +1,58 @@
+package shopgrpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// ShopClient - типизированный клиент сервиса магазина.
+// Методы, кроме Auth, требуют токен в метаданных (см. WithToken).
+type ShopClient interface {
+	Auth(ctx context.Context, req *AuthRequest, opts ...grpc.CallOption) (*AuthResponse, error)
+	Info(ctx context.Context, req *InfoRequest, opts ...grpc.CallOption) (*InfoResponse, error)
+	SendCoins(ctx context.Context, req *SendCoinsRequest, opts ...grpc.CallOption) (*SendCoinsResponse, error)
+	BuyGood(ctx context.Context, req *BuyGoodRequest, opts ...grpc.CallOption) (*BuyGoodResponse, error)
+}
+
+type shopClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewShopClient(cc grpc.ClientConnInterface) ShopClient {
+	return &shopClient{cc: cc}
+}
+
+// WithToken добавляет JWT-токен в исходящие метаданные запроса.
+func WithToken(ctx context.Context, token string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+}
+
+func (c *shopClient) Auth(ctx context.Context, req *AuthRequest, opts ...grpc.CallOption) (*AuthResponse, error) {
+	return invoke[AuthResponse](ctx, c.cc, AuthMethod, req, opts)
+}
+
+func (c *shopClient) Info(ctx context.Context, req *InfoRequest, opts ...grpc.CallOption) (*InfoResponse, error) {
+	return invoke[InfoResponse](ctx, c.cc, InfoMethod, req, opts)
+}
+
+func (c *shopClient) SendCoins(ctx context.Context, req *SendCoinsRequest, opts ...grpc.CallOption) (*SendCoinsResponse, error) {
+	return invoke[SendCoinsResponse](ctx, c.cc, SendCoinsMethod, req, opts)
+}
+
+func (c *shopClient) BuyGood(ctx context.Context, req *BuyGoodRequest, opts ...grpc.CallOption) (*BuyGoodResponse, error) {
+	return invoke[BuyGoodResponse](ctx, c.cc, BuyGoodMethod, req, opts)
+}
+
+// invoke вызывает метод с кодеком магазина, поэтому при подключении кодек указывать не нужно.
+func invoke[Resp any](ctx context.Context, cc grpc.ClientConnInterface, method string, req any, opts []grpc.CallOption) (*Resp, error) {
+	resp := new(Resp)
+	opts = append([]grpc.CallOption{grpc.ForceCodec(jsonCodec{})}, opts...)
+
+	if err := cc.Invoke(ctx, method, req, resp, opts...); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,37 @@
+package shopgrpc
+
+import (
+	"encoding/json"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+)
+
+// CodecName - content-subtype сообщений сервиса магазина (application/grpc+json).
+const CodecName = "json"
+
+// jsonCodec кодирует сообщения в JSON, чтобы сервису не требовалась кодогенерация protobuf.
+// Формат сообщений совпадает с телами запросов и ответов REST API.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return CodecName
+}
+
+// Codec возвращает кодек сообщений сервиса магазина.
+func Codec() encoding.Codec {
+	return jsonCodec{}
+}
+
+// ServerOption включает кодек магазина на gRPC-сервере.
+func ServerOption() grpc.ServerOption {
+	return grpc.ForceServerCodec(jsonCodec{})
+}
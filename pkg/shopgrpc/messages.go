@@ -0,0 +1,26 @@
+package shopgrpc
+
+import "github.com/maksemen2/avito-shop/internal/models"
+
+// Сообщения Auth, Info и SendCoins совпадают с моделями REST API.
+type (
+	AuthRequest      = models.AuthRequest
+	AuthResponse     = models.AuthResponse
+	InfoResponse     = models.InfoResponse
+	Item             = models.Item
+	CoinHistory      = models.CoinHistory
+	SendCoinsRequest = models.SendCoinRequest
+)
+
+type InfoRequest struct{}
+
+type SendCoinsResponse struct{}
+
+// BuyGoodRequest - покупка товара, аналог GET /api/buy/{item}?variant=&promo=
+type BuyGoodRequest struct {
+	Item    string `json:"item"`
+	Variant string `json:"variant,omitempty"`
+	Promo   string `json:"promo,omitempty"`
+}
+
+type BuyGoodResponse struct{}
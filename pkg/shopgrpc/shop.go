@@ -0,0 +1,83 @@
+// Package shopgrpc описывает gRPC-сервис магазина и типизированный клиент к нему.
+// Сервис описан вручную, без protoc, а сообщения передаются в JSON (см. Codec).
+package shopgrpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+const ServiceName = "shop.Shop"
+
+// Полные имена методов сервиса.
+const (
+	AuthMethod      = "/" + ServiceName + "/Auth"
+	InfoMethod      = "/" + ServiceName + "/Info"
+	SendCoinsMethod = "/" + ServiceName + "/SendCoins"
+	BuyGoodMethod   = "/" + ServiceName + "/BuyGood"
+)
+
+// ShopServer - серверная часть сервиса магазина.
+type ShopServer interface {
+	Auth(ctx context.Context, req *AuthRequest) (*AuthResponse, error)
+	Info(ctx context.Context, req *InfoRequest) (*InfoResponse, error)
+	SendCoins(ctx context.Context, req *SendCoinsRequest) (*SendCoinsResponse, error)
+	BuyGood(ctx context.Context, req *BuyGoodRequest) (*BuyGoodResponse, error)
+}
+
+// RegisterShopServer регистрирует реализацию сервиса на gRPC-сервере.
+// Сервер должен быть создан с ServerOption, иначе сообщения не декодируются.
+func RegisterShopServer(s grpc.ServiceRegistrar, srv ShopServer) {
+	s.RegisterService(serviceDesc(), srv)
+}
+
+func serviceDesc() *grpc.ServiceDesc {
+	return &grpc.ServiceDesc{
+		ServiceName: ServiceName,
+		HandlerType: (*ShopServer)(nil),
+		Methods: []grpc.MethodDesc{
+			{
+				MethodName: "Auth",
+				Handler:    unaryHandler(AuthMethod, ShopServer.Auth),
+			},
+			{
+				MethodName: "Info",
+				Handler:    unaryHandler(InfoMethod, ShopServer.Info),
+			},
+			{
+				MethodName: "SendCoins",
+				Handler:    unaryHandler(SendCoinsMethod, ShopServer.SendCoins),
+			},
+			{
+				MethodName: "BuyGood",
+				Handler:    unaryHandler(BuyGoodMethod, ShopServer.BuyGood),
+			},
+		},
+		Streams: []grpc.StreamDesc{},
+	}
+}
+
+// unaryHandler строит обработчик метода: декодирует запрос и вызывает метод сервера через цепочку перехватчиков.
+func unaryHandler[Req, Resp any](
+	fullMethod string,
+	call func(ShopServer, context.Context, *Req) (*Resp, error),
+) func(any, context.Context, func(any) error, grpc.UnaryServerInterceptor) (any, error) {
+	return func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+		req := new(Req)
+		if err := dec(req); err != nil {
+			return nil, err
+		}
+
+		if interceptor == nil {
+			return call(srv.(ShopServer), ctx, req)
+		}
+
+		info := &grpc.UnaryServerInfo{Server: srv, FullMethod: fullMethod}
+		handler := func(ctx context.Context, req any) (any, error) {
+			return call(srv.(ShopServer), ctx, req.(*Req))
+		}
+
+		return interceptor(ctx, req, info, handler)
+	}
+}